@@ -0,0 +1,13 @@
+package caching
+
+import (
+	"context"
+	"time"
+)
+
+type CacheService interface {
+	Get(ctx context.Context, key string) (bool, string, error)
+	Set(ctx context.Context, key string, value string, ttl time.Duration) error
+	Delete(ctx context.Context, key string) error
+	Ping(ctx context.Context) error
+}
@@ -0,0 +1,84 @@
+package caching
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+type memoryCacheEntry struct {
+	key       string
+	value     string
+	expiresAt time.Time
+}
+
+type MemoryCacheService struct {
+	capacity int
+	mutex    sync.Mutex
+	entries  map[string]*list.Element
+	recency  *list.List
+}
+
+func NewMemoryCacheService(capacity int) *MemoryCacheService {
+	return &MemoryCacheService{
+		capacity: capacity,
+		entries:  make(map[string]*list.Element),
+		recency:  list.New(),
+	}
+}
+
+func (cs *MemoryCacheService) Get(ctx context.Context, key string) (bool, string, error) {
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+	element, found := cs.entries[key]
+	if !found {
+		return false, "", nil
+	}
+	entry := element.Value.(*memoryCacheEntry)
+	if !entry.expiresAt.IsZero() && time.Now().After(entry.expiresAt) {
+		cs.removeElement(element)
+		return false, "", nil
+	}
+	cs.recency.MoveToFront(element)
+	return true, entry.value, nil
+}
+
+func (cs *MemoryCacheService) Set(ctx context.Context, key string, value string, ttl time.Duration) error {
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = time.Now().Add(ttl)
+	}
+	if element, found := cs.entries[key]; found {
+		entry := element.Value.(*memoryCacheEntry)
+		entry.value = value
+		entry.expiresAt = expiresAt
+		cs.recency.MoveToFront(element)
+		return nil
+	}
+	cs.entries[key] = cs.recency.PushFront(&memoryCacheEntry{key: key, value: value, expiresAt: expiresAt})
+	for cs.capacity > 0 && cs.recency.Len() > cs.capacity {
+		cs.removeElement(cs.recency.Back())
+	}
+	return nil
+}
+
+func (cs *MemoryCacheService) Delete(ctx context.Context, key string) error {
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+	if element, found := cs.entries[key]; found {
+		cs.removeElement(element)
+	}
+	return nil
+}
+
+func (cs *MemoryCacheService) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (cs *MemoryCacheService) removeElement(element *list.Element) {
+	cs.recency.Remove(element)
+	delete(cs.entries, element.Value.(*memoryCacheEntry).key)
+}
@@ -0,0 +1,39 @@
+package caching
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type RedisCacheService struct {
+	redisClient *redis.Client
+}
+
+func NewRedisCacheService(redisClient *redis.Client) *RedisCacheService {
+	return &RedisCacheService{redisClient}
+}
+
+func (cs *RedisCacheService) Get(ctx context.Context, key string) (bool, string, error) {
+	result, err := cs.redisClient.Get(ctx, key).Result()
+	if err == redis.Nil {
+		return false, "", nil
+	}
+	if err != nil {
+		return false, "", err
+	}
+	return true, result, nil
+}
+
+func (cs *RedisCacheService) Set(ctx context.Context, key string, value string, ttl time.Duration) error {
+	return cs.redisClient.Set(ctx, key, value, ttl).Err()
+}
+
+func (cs *RedisCacheService) Delete(ctx context.Context, key string) error {
+	return cs.redisClient.Del(ctx, key).Err()
+}
+
+func (cs *RedisCacheService) Ping(ctx context.Context) error {
+	return cs.redisClient.Ping(ctx).Err()
+}
@@ -0,0 +1,43 @@
+package caching
+
+import (
+	"config/tracing"
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type TracedCacheService struct {
+	inner CacheService
+}
+
+func NewTracedCacheService(inner CacheService) *TracedCacheService {
+	return &TracedCacheService{inner}
+}
+
+func (cs *TracedCacheService) Get(ctx context.Context, key string) (bool, string, error) {
+	ctx, span := tracing.Start(ctx, "cache.get")
+	found, value, err := cs.inner.Get(ctx, key)
+	span.SetAttributes(attribute.Bool("cache.hit", found))
+	tracing.EndSpan(span, err)
+	return found, value, err
+}
+
+func (cs *TracedCacheService) Set(ctx context.Context, key string, value string, ttl time.Duration) error {
+	ctx, span := tracing.Start(ctx, "cache.set")
+	err := cs.inner.Set(ctx, key, value, ttl)
+	tracing.EndSpan(span, err)
+	return err
+}
+
+func (cs *TracedCacheService) Delete(ctx context.Context, key string) error {
+	ctx, span := tracing.Start(ctx, "cache.delete")
+	err := cs.inner.Delete(ctx, key)
+	tracing.EndSpan(span, err)
+	return err
+}
+
+func (cs *TracedCacheService) Ping(ctx context.Context) error {
+	return cs.inner.Ping(ctx)
+}
@@ -0,0 +1,139 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type Client struct {
+	baseURL      string
+	httpClient   *http.Client
+	tokenSource  func(ctx context.Context) (string, error)
+	maxRetries   int
+	retryBackoff time.Duration
+}
+
+type Option func(client *Client)
+
+func WithToken(token string) Option {
+	return WithTokenSource(func(context.Context) (string, error) {
+		return token, nil
+	})
+}
+
+func WithTokenSource(tokenSource func(ctx context.Context) (string, error)) Option {
+	return func(client *Client) {
+		client.tokenSource = tokenSource
+	}
+}
+
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(client *Client) {
+		client.httpClient = httpClient
+	}
+}
+
+func WithRetries(maxRetries int, backoff time.Duration) Option {
+	return func(client *Client) {
+		client.maxRetries = maxRetries
+		client.retryBackoff = backoff
+	}
+}
+
+func New(baseURL string, options ...Option) *Client {
+	client := &Client{
+		baseURL:      strings.TrimSuffix(baseURL, "/"),
+		httpClient:   &http.Client{Timeout: 30 * time.Second},
+		maxRetries:   3,
+		retryBackoff: 200 * time.Millisecond,
+	}
+	for _, option := range options {
+		option(client)
+	}
+	return client
+}
+
+func (client *Client) do(ctx context.Context, method string, path string, body interface{}, result interface{}) error {
+	var payload []byte
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		payload = encoded
+	}
+	idempotent := method != http.MethodPost && method != http.MethodPatch
+	for attempt := 0; ; attempt++ {
+		resp, err := client.send(ctx, method, path, payload)
+		retryable := err != nil && ctx.Err() == nil
+		if err == nil {
+			retryable = isRetryableStatus(resp.StatusCode)
+		}
+		if !retryable || !idempotent || attempt >= client.maxRetries {
+			if err != nil {
+				return err
+			}
+			return decodeResponse(method, path, resp, result)
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(client.retryBackoff << attempt):
+		}
+	}
+}
+
+func (client *Client) send(ctx context.Context, method string, path string, payload []byte) (*http.Response, error) {
+	var reader io.Reader
+	if payload != nil {
+		reader = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, client.baseURL+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if client.tokenSource != nil {
+		token, err := client.tokenSource(ctx)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return client.httpClient.Do(req)
+}
+
+func isRetryableStatus(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode == http.StatusBadGateway || statusCode == http.StatusServiceUnavailable || statusCode == http.StatusGatewayTimeout
+}
+
+func decodeResponse(method string, path string, resp *http.Response, result interface{}) error {
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		apiErr := &APIError{Method: method, Path: path, StatusCode: resp.StatusCode}
+		var problem Problem
+		if err := json.NewDecoder(resp.Body).Decode(&problem); err == nil && problem.Status != 0 {
+			apiErr.Problem = &problem
+		}
+		return apiErr
+	}
+	if result == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
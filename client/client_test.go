@@ -0,0 +1,160 @@
+package client_test
+
+import (
+	"config/client"
+	"config/models"
+	"config/testharness"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newClient(t *testing.T, permissions ...string) (*client.Client, *testharness.Harness) {
+	harness := testharness.New(t)
+	server := harness.NewServer()
+	caller := harness.AsUser("sdk", permissions...)
+	return client.New(server.URL, client.WithToken(caller.Token())), harness
+}
+
+func TestProducts(t *testing.T) {
+	ctx := context.Background()
+	sdk, _ := newClient(t, "product-view", "product-search", "product-create", "product-edit", "product-rename")
+
+	if err := sdk.CreateProduct(ctx, &models.Product{ProductCode: "P 100", Description: "Plain weave"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := sdk.UpdateProduct(ctx, &models.Product{ProductCode: "P 100", Description: "Twill weave"}); err != nil {
+		t.Fatal(err)
+	}
+	product, err := sdk.GetProduct(ctx, "P 100")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if product.Description != "Twill weave" || product.UpdatedBy != "sdk" {
+		t.Fatalf("unexpected product %+v", product)
+	}
+	products, err := sdk.ListProducts(ctx)
+	if err != nil || len(products) != 1 {
+		t.Fatalf("expected one product, got %+v (%v)", products, err)
+	}
+
+	_, err = sdk.GetProduct(ctx, "P-404")
+	var apiErr *client.APIError
+	if !errors.Is(err, client.ErrNotFound) || !errors.As(err, &apiErr) || apiErr.Problem == nil || apiErr.Problem.Detail != "product 'P-404' not found" {
+		t.Fatalf("expected a not found problem, got %v", err)
+	}
+	if err := sdk.CreateProduct(ctx, &models.Product{ProductCode: "P 100"}); !errors.Is(err, client.ErrConflict) {
+		t.Fatalf("expected a conflict, got %v", err)
+	}
+
+	if _, err := sdk.RenameProduct(ctx, "P 100", "P-101"); err != nil {
+		t.Fatal(err)
+	}
+	if product, err := sdk.GetProduct(ctx, "P 100"); err != nil || product.ProductCode != "P-101" {
+		t.Fatalf("expected the old code to follow the rename, got %+v (%v)", product, err)
+	}
+	if renamed, err := sdk.GetProductByID(ctx, product.ID); err != nil || renamed.ProductCode != "P-101" {
+		t.Fatalf("expected the id to survive the rename, got %+v (%v)", renamed, err)
+	}
+}
+
+func TestSearchTestsIteratesKeysetPages(t *testing.T) {
+	ctx := context.Background()
+	sdk, _ := newClient(t, "test-create", "test-search", "test-view")
+	for i := 0; i < 7; i++ {
+		unitType := "linear"
+		if i%2 == 1 {
+			unitType = "weight"
+		}
+		if err := sdk.CreateTest(ctx, &models.Test{TestName: fmt.Sprintf("Test %02d", i), UnitType: unitType, References: []string{}, Standards: []string{}, AvailableModifiers: []string{}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var names []string
+	iterator := sdk.SearchTests(client.TestSearch{PageSize: 3})
+	for iterator.Next(ctx) {
+		names = append(names, iterator.Test().TestName)
+	}
+	if iterator.Err() != nil {
+		t.Fatal(iterator.Err())
+	}
+	if len(names) != 7 || names[0] != "Test 00" || names[6] != "Test 06" {
+		t.Fatalf("unexpected search results %v", names)
+	}
+
+	count := 0
+	iterator = sdk.SearchTests(client.TestSearch{NamePattern: "test", UnitTypes: []string{"weight"}, PageSize: 2})
+	for iterator.Next(ctx) {
+		if iterator.Test().UnitType != "weight" {
+			t.Fatalf("unexpected test %+v", iterator.Test())
+		}
+		count++
+	}
+	if iterator.Err() != nil || count != 3 {
+		t.Fatalf("expected 3 weight tests, got %v (%v)", count, iterator.Err())
+	}
+
+	test, err := sdk.GetTest(ctx, "Test 03")
+	if err != nil || test.UnitType != "weight" {
+		t.Fatalf("unexpected test %+v (%v)", test, err)
+	}
+}
+
+func TestListUnits(t *testing.T) {
+	sdk, harness := newClient(t, "unit-search")
+	harness.Bootstrap()
+	units, err := sdk.ListUnits(context.Background())
+	if err != nil || len(units) == 0 {
+		t.Fatalf("expected bootstrapped units, got %v (%v)", len(units), err)
+	}
+}
+
+func TestAuthenticationErrors(t *testing.T) {
+	harness := testharness.New(t)
+	server := harness.NewServer()
+
+	_, err := client.New(server.URL).ListUnits(context.Background())
+	if !errors.Is(err, client.ErrUnauthorized) {
+		t.Fatalf("expected unauthorized without a token, got %v", err)
+	}
+	caller := harness.AsUser("reader")
+	_, err = client.New(server.URL, client.WithToken(caller.Token())).ListUnits(context.Background())
+	if !errors.Is(err, client.ErrForbidden) {
+		t.Fatalf("expected forbidden without the permission, got %v", err)
+	}
+}
+
+func TestRetriesTransientFailures(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`[{"fullName":"inch"}]`))
+	}))
+	defer server.Close()
+
+	units, err := client.New(server.URL, client.WithRetries(3, time.Millisecond)).ListUnits(context.Background())
+	if err != nil || len(units) != 1 || calls != 3 {
+		t.Fatalf("expected success on the third attempt, got %v units after %v calls (%v)", len(units), calls, err)
+	}
+
+	atomic.StoreInt32(&calls, -10)
+	_, err = client.New(server.URL, client.WithRetries(1, time.Millisecond)).ListUnits(context.Background())
+	if !errors.Is(err, client.ErrUnavailable) {
+		t.Fatalf("expected unavailable once retries are exhausted, got %v", err)
+	}
+
+	atomic.StoreInt32(&calls, 0)
+	err = client.New(server.URL, client.WithRetries(3, time.Millisecond)).CreateProduct(context.Background(), &models.Product{ProductCode: "P-100"})
+	if !errors.Is(err, client.ErrUnavailable) || calls != 1 {
+		t.Fatalf("expected a single attempt for a non-idempotent request, got %v calls (%v)", calls, err)
+	}
+}
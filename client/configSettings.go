@@ -0,0 +1,32 @@
+package client
+
+import (
+	"config/models"
+	"context"
+	"net/http"
+	"net/url"
+)
+
+func (client *Client) GetConfigSetting(ctx context.Context, name string) (*models.ConfigSetting, error) {
+	var setting models.ConfigSetting
+	if err := client.do(ctx, http.MethodGet, "/config-settings/"+url.PathEscape(name), nil, &setting); err != nil {
+		return nil, err
+	}
+	return &setting, nil
+}
+
+func (client *Client) ListConfigSettings(ctx context.Context) ([]models.ConfigSetting, error) {
+	var settings []models.ConfigSetting
+	if err := client.do(ctx, http.MethodGet, "/config-settings/", nil, &settings); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+func (client *Client) PutConfigSetting(ctx context.Context, setting *models.ConfigSetting) error {
+	return client.do(ctx, http.MethodPut, "/config-settings/"+url.PathEscape(setting.Name), setting, nil)
+}
+
+func (client *Client) DeleteConfigSetting(ctx context.Context, name string) error {
+	return client.do(ctx, http.MethodDelete, "/config-settings/"+url.PathEscape(name), nil, nil)
+}
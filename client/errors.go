@@ -0,0 +1,61 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+var (
+	ErrBadRequest       = errors.New("bad request")
+	ErrUnauthorized     = errors.New("unauthorized")
+	ErrForbidden        = errors.New("forbidden")
+	ErrNotFound         = errors.New("not found")
+	ErrConflict         = errors.New("conflict")
+	ErrValidationFailed = errors.New("validation failed")
+	ErrUnavailable      = errors.New("service unavailable")
+)
+
+type Problem struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail,omitempty"`
+	Instance  string `json:"instance,omitempty"`
+	RequestID string `json:"requestId,omitempty"`
+}
+
+type APIError struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Problem    *Problem
+}
+
+func (err *APIError) Error() string {
+	message := fmt.Sprintf("%v %v: %v %v", err.Method, err.Path, err.StatusCode, http.StatusText(err.StatusCode))
+	if err.Problem != nil && err.Problem.Detail != "" {
+		message += ": " + err.Problem.Detail
+	}
+	return message
+}
+
+func (err *APIError) Is(target error) bool {
+	switch err.StatusCode {
+	case http.StatusBadRequest:
+		return target == ErrBadRequest
+	case http.StatusUnauthorized:
+		return target == ErrUnauthorized
+	case http.StatusForbidden:
+		return target == ErrForbidden
+	case http.StatusNotFound:
+		return target == ErrNotFound
+	case http.StatusConflict:
+		return target == ErrConflict
+	case http.StatusUnprocessableEntity:
+		return target == ErrValidationFailed
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return target == ErrUnavailable
+	}
+	return false
+}
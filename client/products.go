@@ -0,0 +1,60 @@
+package client
+
+import (
+	"config/models"
+	"context"
+	"net/http"
+	"net/url"
+)
+
+func (client *Client) GetProduct(ctx context.Context, productCode string) (*models.Product, error) {
+	var product models.Product
+	if err := client.do(ctx, http.MethodGet, "/products/"+url.PathEscape(productCode), nil, &product); err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+func (client *Client) GetProductByID(ctx context.Context, id string) (*models.Product, error) {
+	var product models.Product
+	if err := client.do(ctx, http.MethodGet, "/by-id/products/"+url.PathEscape(id), nil, &product); err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+func (client *Client) ListProducts(ctx context.Context) ([]models.Product, error) {
+	var products []models.Product
+	if err := client.do(ctx, http.MethodGet, "/products/", nil, &products); err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+func (client *Client) CreateProduct(ctx context.Context, product *models.Product) error {
+	return client.do(ctx, http.MethodPost, "/products/", product, nil)
+}
+
+func (client *Client) UpdateProduct(ctx context.Context, product *models.Product) error {
+	return client.do(ctx, http.MethodPut, "/products/"+url.PathEscape(product.ProductCode), product, nil)
+}
+
+func (client *Client) RenameProduct(ctx context.Context, productCode string, newProductCode string) (*models.Product, error) {
+	var product models.Product
+	if err := client.do(ctx, http.MethodPost, "/products/"+url.PathEscape(productCode)+"/rename", models.ProductRename{NewProductCode: newProductCode}, &product); err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+func (client *Client) DeleteProduct(ctx context.Context, productCode string) error {
+	return client.do(ctx, http.MethodDelete, "/products/"+url.PathEscape(productCode), nil, nil)
+}
+
+func (client *Client) BatchProducts(ctx context.Context, request *models.ProductBatchRequest) (*models.BatchResult, error) {
+	var result models.BatchResult
+	if err := client.do(ctx, http.MethodPost, "/products/batch", request, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -0,0 +1,133 @@
+package client
+
+import (
+	"config/models"
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+const defaultSearchPageSize = 100
+
+type TestSearch struct {
+	NamePattern string
+	UnitTypes   []string
+	PageSize    int
+}
+
+type TestIterator struct {
+	client  *Client
+	search  TestSearch
+	page    []models.Test
+	index   int
+	lastKey string
+	done    bool
+	err     error
+}
+
+func (client *Client) GetTest(ctx context.Context, testName string) (*models.Test, error) {
+	var test models.Test
+	if err := client.do(ctx, http.MethodGet, "/tests/"+url.PathEscape(testName), nil, &test); err != nil {
+		return nil, err
+	}
+	return &test, nil
+}
+
+func (client *Client) GetTestByID(ctx context.Context, id string) (*models.Test, error) {
+	var test models.Test
+	if err := client.do(ctx, http.MethodGet, "/by-id/tests/"+url.PathEscape(id), nil, &test); err != nil {
+		return nil, err
+	}
+	return &test, nil
+}
+
+func (client *Client) SearchTests(search TestSearch) *TestIterator {
+	if search.PageSize <= 0 {
+		search.PageSize = defaultSearchPageSize
+	}
+	return &TestIterator{client: client, search: search, index: -1}
+}
+
+func (client *Client) CreateTest(ctx context.Context, test *models.Test) error {
+	return client.do(ctx, http.MethodPost, "/tests/", test, nil)
+}
+
+func (client *Client) UpdateTest(ctx context.Context, test *models.Test) error {
+	return client.do(ctx, http.MethodPut, "/tests/"+url.PathEscape(test.TestName), test, nil)
+}
+
+func (iterator *TestIterator) Next(ctx context.Context) bool {
+	if iterator.err != nil {
+		return false
+	}
+	iterator.index++
+	if iterator.index < len(iterator.page) {
+		return true
+	}
+	if iterator.done {
+		return false
+	}
+	page, err := iterator.client.searchTestsPage(ctx, iterator.search, iterator.lastKey)
+	if err != nil {
+		iterator.err = err
+		return false
+	}
+	iterator.page = page
+	iterator.index = 0
+	if len(page) < iterator.search.PageSize {
+		iterator.done = true
+	}
+	if len(page) == 0 {
+		return false
+	}
+	iterator.lastKey = page[len(page)-1].TestName
+	return true
+}
+
+func (iterator *TestIterator) Test() models.Test {
+	return iterator.page[iterator.index]
+}
+
+func (iterator *TestIterator) Err() error {
+	return iterator.err
+}
+
+func (client *Client) searchTestsPage(ctx context.Context, search TestSearch, lastKey string) ([]models.Test, error) {
+	query := url.Values{}
+	query.Set("pageSize", strconv.Itoa(search.PageSize))
+	if lastKey != "" {
+		query.Set("lastKey", lastKey)
+	}
+	if search.NamePattern != "" {
+		query.Set("namePattern", search.NamePattern)
+	}
+	for _, unitType := range search.UnitTypes {
+		query.Add("unitType", unitType)
+	}
+	var tests []models.Test
+	if err := client.do(ctx, http.MethodGet, "/tests/?"+query.Encode(), nil, &tests); err != nil {
+		return nil, err
+	}
+	return tests, nil
+}
+
+func (client *Client) RenameTest(ctx context.Context, testName string, newTestName string) (*models.Test, error) {
+	var test models.Test
+	if err := client.do(ctx, http.MethodPost, "/tests/"+url.PathEscape(testName)+"/rename", models.TestRename{NewTestName: newTestName}, &test); err != nil {
+		return nil, err
+	}
+	return &test, nil
+}
+
+func (client *Client) DeleteTest(ctx context.Context, testName string) error {
+	return client.do(ctx, http.MethodDelete, "/tests/"+url.PathEscape(testName), nil, nil)
+}
+
+func (client *Client) BatchTests(ctx context.Context, request *models.TestBatchRequest) (*models.BatchResult, error) {
+	var result models.BatchResult
+	if err := client.do(ctx, http.MethodPost, "/tests/batch", request, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
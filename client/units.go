@@ -0,0 +1,44 @@
+package client
+
+import (
+	"config/models"
+	"context"
+	"net/http"
+	"net/url"
+)
+
+func (client *Client) GetUnit(ctx context.Context, fullName string) (*models.Unit, error) {
+	var unit models.Unit
+	if err := client.do(ctx, http.MethodGet, "/units/"+url.PathEscape(fullName), nil, &unit); err != nil {
+		return nil, err
+	}
+	return &unit, nil
+}
+
+func (client *Client) GetUnitByID(ctx context.Context, id string) (*models.Unit, error) {
+	var unit models.Unit
+	if err := client.do(ctx, http.MethodGet, "/by-id/units/"+url.PathEscape(id), nil, &unit); err != nil {
+		return nil, err
+	}
+	return &unit, nil
+}
+
+func (client *Client) ListUnits(ctx context.Context) ([]models.Unit, error) {
+	var units []models.Unit
+	if err := client.do(ctx, http.MethodGet, "/units/", nil, &units); err != nil {
+		return nil, err
+	}
+	return units, nil
+}
+
+func (client *Client) CreateUnit(ctx context.Context, unit *models.Unit) error {
+	return client.do(ctx, http.MethodPost, "/units/", unit, nil)
+}
+
+func (client *Client) UpdateUnit(ctx context.Context, unit *models.Unit) error {
+	return client.do(ctx, http.MethodPut, "/units/"+url.PathEscape(unit.FullName), unit, nil)
+}
+
+func (client *Client) DeleteUnit(ctx context.Context, fullName string) error {
+	return client.do(ctx, http.MethodDelete, "/units/"+url.PathEscape(fullName), nil, nil)
+}
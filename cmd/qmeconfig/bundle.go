@@ -0,0 +1,148 @@
+package main
+
+import (
+	"config/client"
+	"config/models"
+	"config/repositories"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+type bundle struct {
+	ConfigSettings []models.ConfigSetting `json:"configSettings"`
+	Units          []models.Unit          `json:"units"`
+	Products       []models.Product       `json:"products"`
+	Tests          []models.Test          `json:"tests"`
+}
+
+type importSummary struct {
+	Entity  string `json:"entity"`
+	Created int    `json:"created"`
+	Updated int    `json:"updated"`
+}
+
+var sampleBundle = bundle{
+	Products: []models.Product{
+		{ProductCode: "P-100", Description: "Sample cold rolled sheet"},
+		{ProductCode: "P-200", Description: "Sample hot rolled coil"},
+	},
+	Tests: []models.Test{
+		{TestName: "Tensile Strength", UnitType: "pressure", References: []string{}, Standards: []string{"ASTM E8"}, AvailableModifiers: []string{}},
+		{TestName: "Thickness", UnitType: "linear", References: []string{}, Standards: []string{}, AvailableModifiers: []string{"left", "center", "right"}},
+	},
+}
+
+func exportBundle(ctx context.Context, b *backend) (*bundle, error) {
+	var exported bundle
+	var err error
+	if exported.ConfigSettings, err = b.configSettings.list(ctx); err != nil {
+		return nil, err
+	}
+	if exported.Units, err = b.units.list(ctx); err != nil {
+		return nil, err
+	}
+	if exported.Products, err = b.products.list(ctx); err != nil {
+		return nil, err
+	}
+	if exported.Tests, err = b.tests.list(ctx); err != nil {
+		return nil, err
+	}
+	return &exported, nil
+}
+
+// importBundle imports everything in one transaction when the backend has
+// them. Otherwise it returns the summaries of what was written alongside the
+// error, including the counts of the entity it stopped at.
+func importBundle(ctx context.Context, b *backend, imported *bundle) ([]importSummary, error) {
+	var summaries []importSummary
+	work := func(ctx context.Context) error {
+		summaries = nil
+		for _, step := range []func() (importSummary, error){
+			func() (importSummary, error) { return importItems(ctx, b, b.configSettings, imported.ConfigSettings) },
+			func() (importSummary, error) { return importItems(ctx, b, b.units, imported.Units) },
+			func() (importSummary, error) { return importItems(ctx, b, b.products, imported.Products) },
+			func() (importSummary, error) { return importItems(ctx, b, b.tests, imported.Tests) },
+		} {
+			summary, err := step()
+			summaries = append(summaries, summary)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if b.transact == nil {
+		err := work(ctx)
+		return summaries, err
+	}
+	if err := b.transact(ctx, work); err != nil {
+		return nil, err
+	}
+	return summaries, nil
+}
+
+func importItems[T any](ctx context.Context, b *backend, res resource[T], items []T) (importSummary, error) {
+	summary := importSummary{Entity: res.name}
+	for i := range items {
+		err := b.savepoint(ctx, func(ctx context.Context) error { return res.create(ctx, &items[i]) })
+		if isConflict(err) {
+			if err = b.savepoint(ctx, func(ctx context.Context) error { return res.update(ctx, &items[i]) }); err == nil {
+				summary.Updated++
+				continue
+			}
+		}
+		if err != nil {
+			return summary, fmt.Errorf("unable to import %v '%v': %w", res.name, res.key(&items[i]), err)
+		}
+		summary.Created++
+	}
+	return summary, nil
+}
+
+func isConflict(err error) bool {
+	return errors.Is(err, client.ErrConflict) || errors.Is(err, repositories.ErrAlreadyExists)
+}
+
+func readBundle(path string) (*bundle, error) {
+	var imported bundle
+	if err := readJSON(path, &imported); err != nil {
+		return nil, err
+	}
+	return &imported, nil
+}
+
+func readJSON(path string, target interface{}) error {
+	var reader io.Reader = os.Stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		reader = file
+	}
+	if err := jsonDecoder(reader).Decode(target); err != nil {
+		return fmt.Errorf("unable to parse %v: %w", path, err)
+	}
+	return nil
+}
+
+func jsonDecoder(reader io.Reader) *json.Decoder {
+	decoder := json.NewDecoder(reader)
+	decoder.DisallowUnknownFields()
+	return decoder
+}
+
+func sortedTableNames(versions map[string]int) []string {
+	names := make([]string, 0, len(versions))
+	for name := range versions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"config/models"
+	"config/repositories"
+	"config/settings"
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const testsPageSize = 500
+
+func connectDatabase(ctx context.Context, configFile string) (*pgxpool.Pool, error) {
+	var args []string
+	if configFile != "" {
+		args = []string{"--config", configFile}
+	}
+	appSettings, err := settings.LoadDatabase(args)
+	if err != nil {
+		return nil, err
+	}
+	repositories.SetQueryTimeout(appSettings.Database.StatementTimeout)
+	return pgxpool.New(ctx, appSettings.Database.ConnectionString())
+}
+
+func newDBBackend(conn *pgxpool.Pool, repos *repositories.Repositories, by string) *backend {
+	b := &backend{transact: repos.Transactor.WithinTransaction, close: conn.Close}
+
+	b.products.list = func(ctx context.Context) ([]models.Product, error) {
+		products, err := repos.Products.GetMany(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return *products, nil
+	}
+	b.products.get = repos.Products.GetOne
+	b.products.create = func(ctx context.Context, product *models.Product) error {
+		return repos.Products.Create(ctx, product, by)
+	}
+	b.products.update = func(ctx context.Context, product *models.Product) error {
+		return repos.Products.Update(ctx, product, by)
+	}
+	b.products.delete = func(ctx context.Context, productCode string) error {
+		return repos.Products.Delete(ctx, productCode, by)
+	}
+
+	b.tests.list = func(ctx context.Context) ([]models.Test, error) {
+		tests := []models.Test{}
+		var lastKey *string
+		for {
+			page, err := repos.Tests.GetMany(ctx, testsPageSize, lastKey, &models.TestCriteria{})
+			if err != nil {
+				return nil, err
+			}
+			tests = append(tests, *page...)
+			if len(*page) < testsPageSize {
+				return tests, nil
+			}
+			lastKey = &(*page)[len(*page)-1].TestName
+		}
+	}
+	b.tests.get = repos.Tests.GetOne
+	b.tests.create = func(ctx context.Context, test *models.Test) error {
+		return repos.Tests.Create(ctx, test, by)
+	}
+	b.tests.update = func(ctx context.Context, test *models.Test) error {
+		return repos.Tests.Update(ctx, test, by)
+	}
+	b.tests.delete = func(ctx context.Context, testName string) error {
+		return repos.Tests.Delete(ctx, testName, by)
+	}
+
+	b.units.list = func(ctx context.Context) ([]models.Unit, error) {
+		units, err := repos.Units.GetMany(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return *units, nil
+	}
+	b.units.get = repos.Units.GetOne
+	b.units.create = func(ctx context.Context, unit *models.Unit) error {
+		return repos.Units.Create(ctx, unit, by)
+	}
+	b.units.update = func(ctx context.Context, unit *models.Unit) error {
+		return repos.Units.Update(ctx, unit, by)
+	}
+	b.units.delete = func(ctx context.Context, fullName string) error {
+		return repos.Units.Delete(ctx, fullName, by)
+	}
+
+	b.configSettings.list = func(ctx context.Context) ([]models.ConfigSetting, error) {
+		settings, err := repos.ConfigSettings.GetMany(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return *settings, nil
+	}
+	b.configSettings.get = func(ctx context.Context, name string) (*models.ConfigSetting, error) {
+		values, err := repos.ConfigSettings.GetOne(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		if values == nil {
+			return nil, fmt.Errorf("config setting '%v' not found", name)
+		}
+		return &models.ConfigSetting{Name: name, SettingValues: *values}, nil
+	}
+	b.configSettings.create = func(ctx context.Context, setting *models.ConfigSetting) error {
+		return repos.ConfigSettings.Upsert(ctx, setting.Name, setting.SettingValues, by)
+	}
+	b.configSettings.update = b.configSettings.create
+	b.configSettings.delete = func(ctx context.Context, name string) error {
+		return repos.ConfigSettings.Delete(ctx, name, by)
+	}
+
+	b.describe()
+	return b
+}
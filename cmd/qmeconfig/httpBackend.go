@@ -0,0 +1,46 @@
+package main
+
+import (
+	"config/client"
+	"config/models"
+	"context"
+)
+
+func newHTTPBackend(baseURL string, token string) *backend {
+	api := client.New(baseURL, client.WithToken(token))
+	b := &backend{close: func() {}}
+
+	b.products.list = api.ListProducts
+	b.products.get = api.GetProduct
+	b.products.create = api.CreateProduct
+	b.products.update = api.UpdateProduct
+	b.products.delete = api.DeleteProduct
+
+	b.tests.list = func(ctx context.Context) ([]models.Test, error) {
+		tests := []models.Test{}
+		iterator := api.SearchTests(client.TestSearch{PageSize: 500})
+		for iterator.Next(ctx) {
+			tests = append(tests, iterator.Test())
+		}
+		return tests, iterator.Err()
+	}
+	b.tests.get = api.GetTest
+	b.tests.create = api.CreateTest
+	b.tests.update = api.UpdateTest
+	b.tests.delete = api.DeleteTest
+
+	b.units.list = api.ListUnits
+	b.units.get = api.GetUnit
+	b.units.create = api.CreateUnit
+	b.units.update = api.UpdateUnit
+	b.units.delete = api.DeleteUnit
+
+	b.configSettings.list = api.ListConfigSettings
+	b.configSettings.get = api.GetConfigSetting
+	b.configSettings.create = api.PutConfigSetting
+	b.configSettings.update = api.PutConfigSetting
+	b.configSettings.delete = api.DeleteConfigSetting
+
+	b.describe()
+	return b
+}
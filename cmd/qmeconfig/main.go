@@ -0,0 +1,271 @@
+package main
+
+import (
+	"config/repositories"
+	"config/utilities"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+)
+
+const usage = `usage: qmeconfig [flags] <command> [arguments]
+
+commands:
+  products|tests|units|config-settings list
+  products|tests|units|config-settings get <key>
+  products|tests|units|config-settings create|update (--data <json> | --file <path>)
+  products|tests|units|config-settings delete <key>
+  migrate                      run database migrations (db mode)
+  status                       show table versions (db mode)
+  bootstrap                    write default config settings and units (db mode)
+  seed [--file <path>]         import a bundle, or built-in sample data
+  export [--file <path>]       write all master data as a JSON bundle
+  import --file <path>         create or update everything in a JSON bundle; all or
+                               nothing in db mode, while over http a failed import
+                               keeps and prints what it wrote before the failure
+
+flags:
+`
+
+var errUsage = errors.New("invalid usage")
+
+type options struct {
+	mode       string
+	url        string
+	token      string
+	output     string
+	configFile string
+	user       string
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if err := run(ctx, os.Args[1:], os.Stdout); err != nil {
+		// A bare errUsage follows the usage text, which already says it all.
+		if !errors.Is(err, errUsage) || errors.Unwrap(err) != nil {
+			fmt.Fprintln(os.Stderr, "error:", err)
+		}
+		if errors.Is(err, errUsage) {
+			os.Exit(2)
+		}
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("qmeconfig", flag.ContinueOnError)
+	var opts options
+	flags.StringVar(&opts.mode, "mode", "http", "http to use the API, db to use the database directly")
+	flags.StringVar(&opts.url, "url", envOrDefault("QME_URL", "http://localhost:3020"), "API base URL in http mode (env QME_URL)")
+	flags.StringVar(&opts.token, "token", os.Getenv("QME_TOKEN"), "bearer token in http mode (env QME_TOKEN)")
+	flags.StringVar(&opts.output, "output", "table", "output format: table, json or csv")
+	flags.StringVar(&opts.configFile, "config", os.Getenv("CONFIG_FILE"), "settings YAML with database settings in db mode")
+	flags.StringVar(&opts.user, "user", envOrDefault("USER", "qmeconfig"), "name recorded as the author of changes in db mode")
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), usage)
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return errUsage
+	}
+	if flags.NArg() == 0 || (opts.mode != "http" && opts.mode != "db") || !isOutputFormat(opts.output) {
+		flags.Usage()
+		return errUsage
+	}
+	command, commandArgs := flags.Arg(0), flags.Args()[1:]
+	p := &printer{out: out, format: opts.output}
+
+	switch command {
+	case "migrate", "status", "bootstrap":
+		if opts.mode != "db" {
+			return fmt.Errorf("%v requires --mode db", command)
+		}
+		return runDatabaseCommand(ctx, command, opts, p)
+	}
+
+	b, err := openBackend(ctx, opts)
+	if err != nil {
+		return err
+	}
+	defer b.close()
+	switch command {
+	case "products":
+		return runResource(ctx, b.products, commandArgs, p)
+	case "tests":
+		return runResource(ctx, b.tests, commandArgs, p)
+	case "units":
+		return runResource(ctx, b.units, commandArgs, p)
+	case "config-settings":
+		return runResource(ctx, b.configSettings, commandArgs, p)
+	case "export":
+		return runExport(ctx, b, commandArgs, out)
+	case "import", "seed":
+		return runImport(ctx, b, command, commandArgs, p)
+	}
+	flags.Usage()
+	return errUsage
+}
+
+func openBackend(ctx context.Context, opts options) (*backend, error) {
+	if opts.mode == "http" {
+		return newHTTPBackend(opts.url, opts.token), nil
+	}
+	conn, err := connectDatabase(ctx, opts.configFile)
+	if err != nil {
+		return nil, err
+	}
+	return newDBBackend(conn, repositories.NewPostgresRepositories(conn, nil), opts.user), nil
+}
+
+func runDatabaseCommand(ctx context.Context, command string, opts options, p *printer) error {
+	conn, err := connectDatabase(ctx, opts.configFile)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	repos := repositories.NewPostgresRepositories(conn, nil)
+	switch command {
+	case "migrate":
+		if err := repositories.MigratePostgres(ctx, conn); err != nil {
+			return err
+		}
+	case "bootstrap":
+		return utilities.BootstrapConfig(ctx, repos.Transactor, repos.ConfigSettings, repos.Units)
+	}
+	versions, err := repos.TableVersions.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+	var rows [][]string
+	for _, tableName := range sortedTableNames(versions) {
+		rows = append(rows, []string{tableName, fmt.Sprint(versions[tableName])})
+	}
+	return p.print(versions, []string{"TABLE", "VERSION"}, rows)
+}
+
+func runResource[T any](ctx context.Context, res resource[T], args []string, p *printer) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%w: expected list, get, create, update or delete", errUsage)
+	}
+	flags := flag.NewFlagSet(args[0], flag.ContinueOnError)
+	data := flags.String("data", "", "JSON body")
+	file := flags.String("file", "", "file with the JSON body, - for stdin")
+	if err := flags.Parse(args[1:]); err != nil {
+		return errUsage
+	}
+	switch args[0] {
+	case "list":
+		items, err := res.list(ctx)
+		if err != nil {
+			return err
+		}
+		return printItems(p, res, items)
+	case "get":
+		if flags.NArg() != 1 {
+			return fmt.Errorf("%w: get needs the %v key", errUsage, res.name)
+		}
+		item, err := res.get(ctx, flags.Arg(0))
+		if err != nil {
+			return err
+		}
+		return printItems(p, res, []T{*item})
+	case "create", "update":
+		var item T
+		switch {
+		case *data != "":
+			if err := readJSONString(*data, &item); err != nil {
+				return err
+			}
+		case *file != "":
+			if err := readJSON(*file, &item); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("%w: %v needs --data or --file", errUsage, args[0])
+		}
+		if args[0] == "create" {
+			return res.create(ctx, &item)
+		}
+		return res.update(ctx, &item)
+	case "delete":
+		if flags.NArg() != 1 {
+			return fmt.Errorf("%w: delete needs the %v key", errUsage, res.name)
+		}
+		return res.delete(ctx, flags.Arg(0))
+	}
+	return fmt.Errorf("%w: unknown action '%v'", errUsage, args[0])
+}
+
+func runExport(ctx context.Context, b *backend, args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	file := flags.String("file", "-", "file to write the bundle to, - for stdout")
+	if err := flags.Parse(args); err != nil {
+		return errUsage
+	}
+	exported, err := exportBundle(ctx, b)
+	if err != nil {
+		return err
+	}
+	if *file != "-" {
+		file, err := os.Create(*file)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		out = file
+	}
+	return (&printer{out: out, format: "json"}).print(exported, nil, nil)
+}
+
+func runImport(ctx context.Context, b *backend, command string, args []string, p *printer) error {
+	flags := flag.NewFlagSet(command, flag.ContinueOnError)
+	file := flags.String("file", "", "bundle to import, - for stdin")
+	if err := flags.Parse(args); err != nil {
+		return errUsage
+	}
+	imported := &sampleBundle
+	if *file != "" {
+		var err error
+		if imported, err = readBundle(*file); err != nil {
+			return err
+		}
+	} else if command == "import" {
+		return fmt.Errorf("%w: import needs --file", errUsage)
+	}
+	summaries, err := importBundle(ctx, b, imported)
+	if len(summaries) > 0 {
+		rows := make([][]string, len(summaries))
+		for i, summary := range summaries {
+			rows[i] = []string{summary.Entity, fmt.Sprint(summary.Created), fmt.Sprint(summary.Updated)}
+		}
+		if printErr := p.print(summaries, []string{"ENTITY", "CREATED", "UPDATED"}, rows); printErr != nil && err == nil {
+			return printErr
+		}
+	}
+	return err
+}
+
+func readJSONString(data string, target interface{}) error {
+	decoder := jsonDecoder(strings.NewReader(data))
+	if err := decoder.Decode(target); err != nil {
+		return fmt.Errorf("unable to parse --data: %w", err)
+	}
+	return nil
+}
+
+func isOutputFormat(format string) bool {
+	return format == "table" || format == "json" || format == "csv"
+}
+
+func envOrDefault(name string, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"config/testharness"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCommandsOverHTTP(t *testing.T) {
+	ctx := context.Background()
+	harness := testharness.New(t)
+	harness.Bootstrap()
+	server := harness.NewServer()
+	token := harness.AsUser("admin",
+		"product-view", "product-search", "product-create", "product-edit", "product-delete",
+		"test-view", "test-search", "test-create", "test-edit",
+		"unit-search", "unit-create", "unit-edit",
+		"config-view", "config-edit",
+	).Token()
+	qmeconfig := func(args ...string) (string, error) {
+		var out bytes.Buffer
+		err := run(ctx, append([]string{"--url", server.URL, "--token", token}, args...), &out)
+		return out.String(), err
+	}
+
+	if _, err := qmeconfig("seed"); err != nil {
+		t.Fatal(err)
+	}
+	out, err := qmeconfig("--output", "csv", "products", "list")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(out, "PRODUCT CODE,DESCRIPTION") || !strings.Contains(out, "P-100,Sample cold rolled sheet,admin,admin") {
+		t.Fatalf("unexpected csv output:\n%v", out)
+	}
+	if _, err := qmeconfig("products", "update", "--data", `{"productCode":"P-100","description":"Renamed"}`); err != nil {
+		t.Fatal(err)
+	}
+	if out, err = qmeconfig("products", "get", "P-100"); err != nil || !strings.Contains(out, "Renamed") {
+		t.Fatalf("expected updated product, got %v %v", out, err)
+	}
+
+	bundlePath := filepath.Join(t.TempDir(), "bundle.json")
+	if _, err := qmeconfig("export", "--file", bundlePath); err != nil {
+		t.Fatal(err)
+	}
+	exported, err := readBundle(bundlePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(exported.Products) != 2 || len(exported.Tests) != 2 || len(exported.Units) == 0 || len(exported.ConfigSettings) == 0 {
+		t.Fatalf("unexpected bundle %+v", exported)
+	}
+
+	if out, err = qmeconfig("--output", "json", "import", "--file", bundlePath); err != nil {
+		t.Fatal(err)
+	}
+	var summaries []importSummary
+	if err := json.Unmarshal([]byte(out), &summaries); err != nil {
+		t.Fatal(err)
+	}
+	for _, summary := range summaries {
+		if summary.Entity != "config setting" && summary.Created != 0 {
+			t.Fatalf("expected re-import to only update, got %+v", summaries)
+		}
+	}
+
+	partialPath := filepath.Join(t.TempDir(), "partial.json")
+	if err := os.WriteFile(partialPath, []byte(`{"products":[{"productCode":"P-300","description":"Plate"}],"tests":[{"testName":"Bend","unitType":"pressure"}]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	out, err = qmeconfig("--output", "json", "import", "--file", partialPath)
+	if err == nil {
+		t.Fatal("expected the import to fail on the test without lists")
+	}
+	summaries = nil
+	if err := json.Unmarshal([]byte(out), &summaries); err != nil || len(summaries) != 4 || summaries[2].Created != 1 || summaries[3].Entity != "test" {
+		t.Fatalf("expected the counts written before the failure, got %v (%v)", out, err)
+	}
+
+	if _, err := qmeconfig("products", "delete", "P-100"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := qmeconfig("products", "get", "P-100"); err == nil {
+		t.Fatal("expected deleted product to be missing")
+	}
+}
+
+func TestUsageErrors(t *testing.T) {
+	for _, args := range [][]string{
+		{},
+		{"--output", "yaml", "products", "list"},
+		{"products"},
+		{"products", "create"},
+		{"import"},
+	} {
+		if err := run(context.Background(), args, &bytes.Buffer{}); !errors.Is(err, errUsage) {
+			t.Errorf("expected usage error for %v, got %v", args, err)
+		}
+	}
+}
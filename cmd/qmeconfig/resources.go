@@ -0,0 +1,69 @@
+package main
+
+import (
+	"config/models"
+	"context"
+	"strings"
+)
+
+type resource[T any] struct {
+	name    string
+	columns []string
+	row     func(item *T) []string
+	key     func(item *T) string
+	list    func(ctx context.Context) ([]T, error)
+	get     func(ctx context.Context, key string) (*T, error)
+	create  func(ctx context.Context, item *T) error
+	update  func(ctx context.Context, item *T) error
+	delete  func(ctx context.Context, key string) error
+}
+
+// backend.transact is nil when the backend cannot group writes, as over the
+// API, where an import that fails keeps whatever it wrote before the failure.
+type backend struct {
+	products       resource[models.Product]
+	tests          resource[models.Test]
+	units          resource[models.Unit]
+	configSettings resource[models.ConfigSetting]
+	transact       func(ctx context.Context, work func(ctx context.Context) error) error
+	close          func()
+}
+
+// savepoint runs work in a nested transaction when the backend has them, so a
+// failed write does not abort the transaction around it.
+func (b *backend) savepoint(ctx context.Context, work func(ctx context.Context) error) error {
+	if b.transact == nil {
+		return work(ctx)
+	}
+	return b.transact(ctx, work)
+}
+
+func (b *backend) describe() {
+	b.products.name = "product"
+	b.products.columns = []string{"PRODUCT CODE", "DESCRIPTION", "CREATED BY", "UPDATED BY"}
+	b.products.row = func(product *models.Product) []string {
+		return []string{product.ProductCode, product.Description, product.CreatedBy, product.UpdatedBy}
+	}
+	b.products.key = func(product *models.Product) string { return product.ProductCode }
+
+	b.tests.name = "test"
+	b.tests.columns = []string{"TEST NAME", "UNIT TYPE", "REFERENCES", "STANDARDS", "MODIFIERS", "CREATED BY", "UPDATED BY"}
+	b.tests.row = func(test *models.Test) []string {
+		return []string{test.TestName, test.UnitType, strings.Join(test.References, "; "), strings.Join(test.Standards, "; "), strings.Join(test.AvailableModifiers, "; "), test.CreatedBy, test.UpdatedBy}
+	}
+	b.tests.key = func(test *models.Test) string { return test.TestName }
+
+	b.units.name = "unit"
+	b.units.columns = []string{"FULL NAME", "PLURAL", "ABBREVIATION", "SYSTEM", "UNIT TYPE", "CREATED BY", "UPDATED BY"}
+	b.units.row = func(unit *models.Unit) []string {
+		return []string{unit.FullName, unit.FullNamePlural, unit.Abbreviation, unit.MeasurementSystem, unit.UnitType, unit.CreatedBy, unit.UpdatedBy}
+	}
+	b.units.key = func(unit *models.Unit) string { return unit.FullName }
+
+	b.configSettings.name = "config setting"
+	b.configSettings.columns = []string{"NAME", "VALUES"}
+	b.configSettings.row = func(setting *models.ConfigSetting) []string {
+		return []string{setting.Name, strings.Join(setting.SettingValues, "; ")}
+	}
+	b.configSettings.key = func(setting *models.ConfigSetting) string { return setting.Name }
+}
@@ -1,8 +1,12 @@
 package main
 
 import (
+	"config/caching"
+	"config/metrics"
 	"config/repositories"
 	"config/routers"
+	"config/settings"
+	"config/tracing"
 	"config/utilities"
 	"context"
 	"fmt"
@@ -10,66 +14,139 @@ import (
 	"os"
 
 	"github.com/gin-gonic/gin"
-	"github.com/go-redis/redis"
-	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/redis/go-redis/v9"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"github.com/rs/zerolog/pkgerrors"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
 func main() {
 	zerolog.ErrorStackMarshaler = pkgerrors.MarshalStack
-	zerolog.SetGlobalLevel(zerolog.DebugLevel)
+	zerolog.DefaultContextLogger = &log.Logger
 
-	r := gin.Default()
-	r.SetTrustedProxies(nil)
-	r.GET("/test", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{
-			"message": "test",
-		})
-	})
+	appSettings, err := settings.Load(os.Args[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	logLevel, _ := zerolog.ParseLevel(appSettings.LogLevel)
+	zerolog.SetGlobalLevel(logLevel)
 
-	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=disable TimeZone=America/New_York", os.Getenv("PGHOST"),
-		os.Getenv("PGUSER"), os.Getenv("PGPASSWORD"), os.Getenv("PGDATABASE"), os.Getenv("PGPORT"))
+	lifecycle := utilities.NewLifecycle(appSettings.ShutdownTimeout)
 
-	conn, err := pgx.Connect(context.Background(), dsn)
+	shutdownTracing, err := tracing.Init(context.Background(), appSettings.Tracing.Exporter, appSettings.Tracing.File)
 	if err != nil {
-		log.Error().Err(err).Msg("unable to connect to database")
-		panic(err)
-	}
-	defer conn.Close(context.Background())
-	tableVersionsRepository := repositories.NewTableVersionsRepository(conn)
-	if err = tableVersionsRepository.Migrate(); err != nil {
 		panic(err)
 	}
-	configSettingsRepository := repositories.NewConfigSettingsRepository(conn)
-	if err = configSettingsRepository.Migrate(); err != nil {
-		panic(err)
+	lifecycle.AddCloser("tracing", shutdownTracing)
+
+	r := gin.New()
+	r.SetTrustedProxies(nil)
+	r.Use(gin.Recovery())
+	r.Use(routers.RequestID())
+	r.Use(routers.AccessLog())
+	r.Use(otelgin.Middleware(tracing.ServiceName))
+	r.Use(metrics.HTTPMiddleware())
+	r.GET("/metrics", gin.WrapH(promhttp.Handler()))
+
+	var cacheService caching.CacheService
+	switch appSettings.Cache.Provider {
+	case "memory":
+		cacheService = caching.NewMemoryCacheService(appSettings.Cache.MemoryCapacity)
+	case "redis":
+		redis := redis.NewClient(&redis.Options{
+			Addr:     appSettings.Redis.Address,
+			Username: appSettings.Redis.Username,
+			Password: appSettings.Redis.Password,
+			DB:       appSettings.Redis.DB,
+		})
+		cacheService = caching.NewRedisCacheService(redis)
+		lifecycle.AddCloser("redis", func(context.Context) error {
+			return redis.Close()
+		})
 	}
-	unitsRepository := repositories.NewUnitsRepository(conn)
-	if err = unitsRepository.Migrate(); err != nil {
-		panic(err)
+	cacheService = caching.NewTracedCacheService(cacheService)
+
+	startupCtx := context.Background()
+	var repos *repositories.Repositories
+	var changeNotifier repositories.ChangeNotifier
+	healthChecker := utilities.NewHealthChecker(appSettings.Readiness.Timeout)
+	switch appSettings.Storage {
+	case "memory":
+		log.Warn().Msg("using in-memory storage, data will not survive a restart")
+		store := repositories.NewMemoryStore()
+		repos = repositories.NewMemoryRepositories(store)
+		changeNotifier = store
+	case "postgres":
+		poolConfig, err := pgxpool.ParseConfig(appSettings.Database.ConnectionString())
+		if err != nil {
+			panic(err)
+		}
+		poolConfig.ConnConfig.Tracer = tracing.NewPgxTracer()
+		repositories.SetQueryTimeout(appSettings.Database.StatementTimeout)
+		conn, err := pgxpool.NewWithConfig(startupCtx, poolConfig)
+		if err != nil {
+			log.Error().Err(err).Msg("unable to connect to database")
+			panic(err)
+		}
+		lifecycle.AddCloser("postgres", func(context.Context) error {
+			conn.Close()
+			return nil
+		})
+		if err = repositories.MigratePostgres(startupCtx, conn); err != nil {
+			panic(err)
+		}
+		metrics.SetMigrationsSucceeded(true)
+		repos = repositories.NewPostgresRepositories(conn, cacheService)
+
+		changeListener := repositories.NewChangeListener(conn)
+		changeListener.Subscribe(func(change repositories.ChangeNotification) {
+			repositories.InvalidateMasterData(context.Background(), cacheService, change)
+		})
+		lifecycle.AddWorker("change listener", changeListener.Run)
+		changeNotifier = changeListener
+		healthChecker.AddCheck(utilities.HealthCheck{Name: "postgres", Critical: appSettings.IsCritical("postgres"), Check: conn.Ping})
 	}
-	productsRepository := repositories.NewProductsRepository(conn)
-	if err = productsRepository.Migrate(); err != nil {
+
+	tableVersions, err := repos.TableVersions.GetAll(startupCtx)
+	if err != nil {
 		panic(err)
 	}
-	testsRepository := repositories.NewTestsRepository(conn)
-	if err = testsRepository.Migrate(); err != nil {
-		panic(err)
+	for tableName, version := range tableVersions {
+		metrics.SetTableVersion(tableName, version)
 	}
-	if err = utilities.BootstrapConfig(configSettingsRepository, unitsRepository); err != nil {
+	if err = utilities.BootstrapConfig(startupCtx, repos.Transactor, repos.ConfigSettings, repos.Units); err != nil {
 		panic(err)
 	}
+	metrics.SetBootstrapped(true)
+
+	webhookDispatcher := utilities.NewWebhookDispatcher(repos.Webhooks, appSettings.Retention.Webhooks)
+	lifecycle.AddWorker("webhook dispatcher", webhookDispatcher.Run)
+	changeLogPruner := utilities.NewChangeLogPruner(repos.ChangeLog, appSettings.Retention.ChangeLog)
+	lifecycle.AddWorker("change log pruner", changeLogPruner.Run)
 
-	redis := redis.NewClient(&redis.Options{Addr: os.Getenv("REDIS_ADDRESS"), Password: "", DB: 0}) //default DB
-	cacheService := utilities.NewCacheService(redis)
-	authClient := utilities.NewAuthClient(os.Getenv("AUTH_SERVICE_ENDPOINT"))
+	authClient := utilities.NewAuthClient(appSettings.Auth.Endpoint)
 	permissionsHelper := utilities.NewPermissionHelper(authClient, cacheService)
 
-	routers.RegisterProducts(r.Group("/products"), productsRepository, permissionsHelper)
-	routers.RegisterTests(r.Group("/tests"), testsRepository, permissionsHelper)
-	routers.RegisterUnits(r.Group("/units"), unitsRepository, permissionsHelper)
+	healthChecker.AddCheck(utilities.HealthCheck{Name: "cache", Critical: appSettings.IsCritical("cache"), Check: cacheService.Ping})
+	if appSettings.Readiness.CheckAuth {
+		healthChecker.AddCheck(utilities.HealthCheck{Name: "auth", Critical: appSettings.IsCritical("auth"), Check: authClient.Ping})
+	}
+
+	routers.RegisterAll(r, routers.Dependencies{
+		Repositories:      repos,
+		ChangeNotifier:    changeNotifier,
+		HealthChecker:     healthChecker,
+		PermissionsHelper: permissionsHelper,
+		ShuttingDown:      lifecycle.ShuttingDown(),
+	})
 
-	r.Run(fmt.Sprintf(":%s", os.Getenv("PORT")))
+	server := &http.Server{Addr: fmt.Sprintf(":%v", appSettings.Port), Handler: r}
+	if err := lifecycle.Run(server); err != nil {
+		log.Error().Err(err).Msg("exiting after server error")
+		os.Exit(1)
+	}
 }
@@ -0,0 +1,118 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "qme_config"
+
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests handled, by method, route and status.",
+	}, []string{"method", "route", "status"})
+	httpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency, by method, route and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+	repositoryQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "repository_query_duration_seconds",
+		Help:      "Repository method duration, by method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method"})
+	cacheRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_requests_total",
+		Help:      "Cache lookups, by cache and result (hit or miss).",
+	}, []string{"cache", "result"})
+	authRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "auth_request_duration_seconds",
+		Help:      "Auth service call latency, by operation.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"operation"})
+	authFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "auth_failures_total",
+		Help:      "Auth service calls that returned an error, by operation.",
+	}, []string{"operation"})
+	tableVersion = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "table_version",
+		Help:      "Current migration version of each table.",
+	}, []string{"table"})
+	migrationsSucceeded = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "migrations_succeeded",
+		Help:      "1 if all migrations completed at startup, 0 otherwise.",
+	})
+	bootstrapped = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "bootstrapped",
+		Help:      "1 if config bootstrap has completed, 0 otherwise.",
+	})
+)
+
+func HTTPMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		status := strconv.Itoa(c.Writer.Status())
+		httpRequests.WithLabelValues(c.Request.Method, route, status).Inc()
+		httpRequestDuration.WithLabelValues(c.Request.Method, route, status).Observe(time.Since(start).Seconds())
+	}
+}
+
+func TimeQuery(method string) func() {
+	start := time.Now()
+	return func() {
+		repositoryQueryDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+	}
+}
+
+func CacheHit(cache string) {
+	cacheRequests.WithLabelValues(cache, "hit").Inc()
+}
+
+func CacheMiss(cache string) {
+	cacheRequests.WithLabelValues(cache, "miss").Inc()
+}
+
+func ObserveAuthCall(operation string, start time.Time, err error) {
+	authRequestDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+	if err != nil {
+		authFailures.WithLabelValues(operation).Inc()
+	}
+}
+
+func SetTableVersion(table string, version int) {
+	tableVersion.WithLabelValues(table).Set(float64(version))
+}
+
+func SetMigrationsSucceeded(succeeded bool) {
+	migrationsSucceeded.Set(boolToFloat(succeeded))
+}
+
+func SetBootstrapped(isBootstrapped bool) {
+	bootstrapped.Set(boolToFloat(isBootstrapped))
+}
+
+func boolToFloat(value bool) float64 {
+	if value {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,45 @@
+package models
+
+const (
+	BatchOpCreate = "create"
+	BatchOpUpdate = "update"
+	BatchOpDelete = "delete"
+)
+
+type ProductBatchRequest struct {
+	AllOrNothing bool                    `json:"allOrNothing"`
+	Operations   []ProductBatchOperation `json:"operations"`
+}
+
+type ProductBatchOperation struct {
+	Op      string   `json:"op"`
+	Key     string   `json:"key,omitempty"`
+	Product *Product `json:"product,omitempty"`
+}
+
+type TestBatchRequest struct {
+	AllOrNothing bool                 `json:"allOrNothing"`
+	Operations   []TestBatchOperation `json:"operations"`
+}
+
+type TestBatchOperation struct {
+	Op   string `json:"op"`
+	Key  string `json:"key,omitempty"`
+	Test *Test  `json:"test,omitempty"`
+}
+
+type BatchResult struct {
+	AllOrNothing bool              `json:"allOrNothing"`
+	Committed    bool              `json:"committed"`
+	Succeeded    int               `json:"succeeded"`
+	Failed       int               `json:"failed"`
+	Results      []BatchItemResult `json:"results"`
+}
+
+type BatchItemResult struct {
+	Index  int    `json:"index"`
+	Op     string `json:"op"`
+	Key    string `json:"key"`
+	Status int    `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+const (
+	ChangeActionCreated = "created"
+	ChangeActionUpdated = "updated"
+	ChangeActionDeleted = "deleted"
+	ChangeActionRenamed = "renamed"
+)
+
+type Change struct {
+	ChangeID    int64     `json:"changeId"`
+	EntityType  string    `json:"entityType"`
+	EntityID    string    `json:"entityId,omitempty"`
+	EntityKey   string    `json:"entityKey"`
+	PreviousKey string    `json:"previousKey,omitempty"`
+	Action      string    `json:"action"`
+	ChangedBy   string    `json:"changedBy"`
+	ChangedAt   time.Time `json:"changedAt"`
+}
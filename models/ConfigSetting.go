@@ -1,6 +1,6 @@
 package models
 
 type ConfigSetting struct {
-	Name          string
-	SettingValues []string
+	Name          string   `json:"name"`
+	SettingValues []string `json:"settingValues"`
 }
@@ -0,0 +1,30 @@
+package models
+
+const (
+	ImportModeAllOrNothing = "all-or-nothing"
+	ImportModeBestEffort   = "best-effort"
+)
+
+const (
+	ImportWriteModeInsert = "insert"
+	ImportWriteModeUpsert = "upsert"
+)
+
+type ImportReport struct {
+	Mode      string           `json:"mode"`
+	WriteMode string           `json:"writeMode"`
+	DryRun    bool             `json:"dryRun"`
+	Committed bool             `json:"committed"`
+	TotalRows int              `json:"totalRows"`
+	Created   int              `json:"created"`
+	Updated   int              `json:"updated"`
+	Failed    int              `json:"failed"`
+	Errors    []ImportRowError `json:"errors"`
+}
+
+type ImportRowError struct {
+	Row     int    `json:"row"`
+	Key     string `json:"key,omitempty"`
+	Column  string `json:"column,omitempty"`
+	Message string `json:"message"`
+}
@@ -1,6 +1,9 @@
 package models
 
 type Product struct {
+	ID          string `json:"id"`
 	ProductCode string `json:"productCode"`
 	Description string `json:"description"`
+	CreatedBy   string `json:"createdBy"`
+	UpdatedBy   string `json:"updatedBy"`
 }
@@ -0,0 +1,9 @@
+package models
+
+type ProductRename struct {
+	NewProductCode string `json:"newProductCode"`
+}
+
+type TestRename struct {
+	NewTestName string `json:"newTestName"`
+}
@@ -1,9 +1,12 @@
 package models
 
 type Test struct {
+	ID                 string   `json:"id"`
 	TestName           string   `json:"testName"`
 	UnitType           string   `json:"unitType"`
 	References         []string `json:"references"`
 	Standards          []string `json:"standards"`
 	AvailableModifiers []string `json:"availableModifiers"`
+	CreatedBy          string   `json:"createdBy"`
+	UpdatedBy          string   `json:"updatedBy"`
 }
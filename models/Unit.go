@@ -1,9 +1,12 @@
 package models
 
 type Unit struct {
+	ID                string `json:"id"`
 	FullName          string `json:"fullName"`
 	FullNamePlural    string `json:"fullNamePlural"`
 	Abbreviation      string `json:"abbreviation"`
 	MeasurementSystem string `json:"measurementSystem"`
 	UnitType          string `json:"unitType"`
+	CreatedBy         string `json:"createdBy"`
+	UpdatedBy         string `json:"updatedBy"`
 }
@@ -1,5 +1,7 @@
 package models
 
+import "strings"
+
 type User struct {
 	UserID                   string   `json:"userId"`
 	GivenNames               []string `json:"givenNames"`
@@ -11,3 +13,11 @@ type User struct {
 	HashedPassword           string   `json:"-"`
 	AuthToken                string   `json:"authToken"`
 }
+
+func (user *User) DisplayName() string {
+	names := append(append([]string{}, user.GivenNames...), user.FamilyNames...)
+	if len(names) == 0 {
+		return user.UserID
+	}
+	return strings.Join(names, " ")
+}
@@ -0,0 +1,11 @@
+package models
+
+type WebhookSubscription struct {
+	SubscriptionID int64    `json:"subscriptionId"`
+	URL            string   `json:"url"`
+	Secret         string   `json:"secret,omitempty"`
+	EventTypes     []string `json:"eventTypes"`
+	IsActive       bool     `json:"isActive"`
+	CreatedBy      string   `json:"createdBy"`
+	UpdatedBy      string   `json:"updatedBy"`
+}
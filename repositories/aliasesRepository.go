@@ -0,0 +1,130 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PostgresAliasesRepository struct {
+	conn *pgxpool.Pool
+}
+
+func NewPostgresAliasesRepository(conn *pgxpool.Pool) *PostgresAliasesRepository {
+	return &PostgresAliasesRepository{conn: conn}
+}
+
+// resolveAliasSQL holds, for each entity type that can be renamed, the query
+// that follows an old key through the alias to the entity's current key.
+var resolveAliasSQL = map[string]string{
+	"products": "select p.product_code from key_aliases a join products p on p.id = a.entity_id where a.entity_type = 'products' and lower(a.old_key) = lower($1)",
+	"tests":    "select t.test_name from key_aliases a join tests t on t.id = a.entity_id where a.entity_type = 'tests' and lower(a.old_key) = lower($1)",
+}
+
+func (repo *PostgresAliasesRepository) Resolve(ctx context.Context, entityType string, key string) (_ string, err error) {
+	ctx, end := startOperation(ctx, "AliasesRepository.Resolve")
+	defer end(&err)
+	sql, ok := resolveAliasSQL[entityType]
+	if !ok {
+		return "", newDomainError(ErrNotFound, "alias", key, "")
+	}
+	var newKey string
+	if err := queryable(ctx, repo.conn).QueryRow(ctx, sql, NormalizeKey(key)).Scan(&newKey); err != nil {
+		return "", translateError(err, "alias", key)
+	}
+	return newKey, nil
+}
+
+// recordAlias points oldKey at the renamed entity. Aliases hold the entity id
+// rather than its new key, so later renames never leave a chain to follow. Old
+// keys compare case-insensitively like the keys themselves, and an alias that
+// matches the new key is dropped because the entity owns that name again.
+func recordAlias(ctx context.Context, tx pgx.Tx, entityType string, entityID string, oldKey string, newKey string, by string) error {
+	batch := &pgx.Batch{}
+	batch.Queue("delete from key_aliases where entity_type = $1 and lower(old_key) in (lower($2), lower($3))", entityType, oldKey, newKey)
+	batch.Queue(`
+insert into key_aliases (entity_type, old_key, entity_id, created_by)
+values ($1, $2, $3, $4)
+on conflict (entity_type, old_key) do update set entity_id = excluded.entity_id, created_by = excluded.created_by, created_at = now()
+	`, entityType, oldKey, entityID, by)
+	return tx.SendBatch(ctx, batch).Close()
+}
+
+func deleteAliases(ctx context.Context, tx pgx.Tx, entityType string, entityID string) error {
+	_, err := tx.Exec(ctx, "delete from key_aliases where entity_type = $1 and entity_id = $2", entityType, entityID)
+	return err
+}
+
+func (repo *PostgresAliasesRepository) Migrate(ctx context.Context) error {
+	var currentVersion int
+	row := repo.conn.QueryRow(ctx, "select current_version from table_versions where table_name = 'key_aliases'")
+	err := row.Scan(&currentVersion)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			currentVersion = 0
+		} else {
+			return err
+		}
+	}
+	if currentVersion < 1 {
+		_, err = repo.conn.Exec(ctx, `
+create table key_aliases (
+	entity_type text not null,
+	old_key text not null,
+	new_key text not null,
+	created_by text not null,
+	created_at timestamptz not null default now(),
+	primary key (entity_type, old_key)
+);
+create index key_aliases_new_key on key_aliases (entity_type, new_key)
+		`)
+		if err != nil {
+			return err
+		}
+		_, err = repo.conn.Exec(ctx, `
+insert into table_versions (table_name, current_version)
+values ('key_aliases', 1)
+		`)
+		if err != nil {
+			return err
+		}
+	}
+	if currentVersion < 2 {
+		_, err = repo.conn.Exec(ctx, `
+alter table key_aliases
+	add column entity_id uuid;
+update key_aliases a set entity_id = p.id from products p where a.entity_type = 'products' and p.product_code = a.new_key;
+update key_aliases a set entity_id = t.id from tests t where a.entity_type = 'tests' and t.test_name = a.new_key;
+delete from key_aliases where entity_id is null;
+alter table key_aliases
+	alter column entity_id set not null,
+	drop column new_key;
+create index key_aliases_entity_id on key_aliases (entity_type, entity_id)
+		`)
+		if err != nil {
+			return err
+		}
+		_, err = repo.conn.Exec(ctx, `
+update table_versions set current_version = 2 where table_name = 'key_aliases'
+		`)
+		if err != nil {
+			return err
+		}
+	}
+	if currentVersion < 3 {
+		_, err = repo.conn.Exec(ctx, `
+create index key_aliases_old_key_folded on key_aliases (entity_type, lower(old_key))
+		`)
+		if err != nil {
+			return err
+		}
+		_, err = repo.conn.Exec(ctx, `
+update table_versions set current_version = 3 where table_name = 'key_aliases'
+		`)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
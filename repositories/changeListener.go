@@ -0,0 +1,81 @@
+package repositories
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+)
+
+const changeNotificationChannel = "config_changes"
+
+type ChangeNotification struct {
+	EntityType string
+	Key        string
+}
+
+type ChangeListener struct {
+	conn             *pgxpool.Pool
+	mutex            sync.RWMutex
+	nextSubscriberID int
+	subscribers      map[int]func(ChangeNotification)
+}
+
+func NewChangeListener(conn *pgxpool.Pool) *ChangeListener {
+	return &ChangeListener{conn: conn, subscribers: make(map[int]func(ChangeNotification))}
+}
+
+func (listener *ChangeListener) Subscribe(subscriber func(ChangeNotification)) func() {
+	listener.mutex.Lock()
+	defer listener.mutex.Unlock()
+	subscriberID := listener.nextSubscriberID
+	listener.nextSubscriberID++
+	listener.subscribers[subscriberID] = subscriber
+	return func() {
+		listener.mutex.Lock()
+		defer listener.mutex.Unlock()
+		delete(listener.subscribers, subscriberID)
+	}
+}
+
+func (listener *ChangeListener) Run(ctx context.Context) error {
+	for {
+		err := listener.listen(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+		log.Ctx(ctx).Error().Err(err).Msg("change listener disconnected, reconnecting")
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(5 * time.Second):
+		}
+	}
+}
+
+func (listener *ChangeListener) listen(ctx context.Context) error {
+	conn, err := listener.conn.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+	if _, err := conn.Exec(ctx, "listen "+changeNotificationChannel); err != nil {
+		return err
+	}
+	for {
+		notification, err := conn.Conn().WaitForNotification(ctx)
+		if err != nil {
+			conn.Conn().Close(context.Background())
+			return err
+		}
+		entityType, key, _ := strings.Cut(notification.Payload, "|")
+		listener.mutex.RLock()
+		for _, subscriber := range listener.subscribers {
+			subscriber(ChangeNotification{EntityType: entityType, Key: key})
+		}
+		listener.mutex.RUnlock()
+	}
+}
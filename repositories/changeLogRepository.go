@@ -0,0 +1,258 @@
+package repositories
+
+import (
+	"config/models"
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PostgresChangeLogRepository struct {
+	conn *pgxpool.Pool
+}
+
+func NewPostgresChangeLogRepository(conn *pgxpool.Pool) *PostgresChangeLogRepository {
+	return &PostgresChangeLogRepository{conn: conn}
+}
+
+// changeLogLockID is the advisory lock every writer to change_log holds until it
+// commits. change_id comes from a sequence, and without the lock a transaction
+// could take id 10, commit after another one took and committed id 11, and a
+// stream that already resumed past 11 would never see 10. Holding the lock
+// makes ids visible strictly in order, at the cost of serializing writers.
+const changeLogLockID = 7_301_224_001
+
+func lockChangeLog(ctx context.Context, tx pgx.Tx) error {
+	_, err := tx.Exec(ctx, "select pg_advisory_xact_lock($1)", changeLogLockID)
+	return err
+}
+
+func recordChange(ctx context.Context, tx pgx.Tx, entityType string, entityID string, entityKey string, action string, by string) error {
+	return insertChange(ctx, tx, &models.Change{EntityType: entityType, EntityID: entityID, EntityKey: entityKey, Action: action, ChangedBy: by})
+}
+
+// recordRename also notifies the old key so other instances drop anything
+// they cached under it.
+func recordRename(ctx context.Context, tx pgx.Tx, entityType string, entityID string, oldKey string, newKey string, by string) error {
+	change := models.Change{EntityType: entityType, EntityID: entityID, EntityKey: newKey, PreviousKey: oldKey, Action: models.ChangeActionRenamed, ChangedBy: by}
+	if err := insertChange(ctx, tx, &change); err != nil {
+		return err
+	}
+	_, err := tx.Exec(ctx, "select pg_notify($1, $2)", changeNotificationChannel, entityType+"|"+oldKey)
+	return err
+}
+
+func insertChange(ctx context.Context, tx pgx.Tx, change *models.Change) error {
+	sql := `
+insert into change_log (entity_type, entity_id, entity_key, previous_key, action, changed_by)
+values ($1, nullif($2, '')::uuid, $3, nullif($4, ''), $5, $6)
+returning change_id, changed_at
+	`
+	if err := lockChangeLog(ctx, tx); err != nil {
+		return err
+	}
+	if err := tx.QueryRow(ctx, sql, change.EntityType, change.EntityID, change.EntityKey, change.PreviousKey, change.Action, change.ChangedBy).Scan(&change.ChangeID, &change.ChangedAt); err != nil {
+		return err
+	}
+	if err := enqueueWebhookEvent(ctx, tx, change); err != nil {
+		return err
+	}
+	_, err := tx.Exec(ctx, "select pg_notify($1, $2)", changeNotificationChannel, change.EntityType+"|"+change.EntityKey)
+	return err
+}
+
+// recordChanges is recordChange for many entities at once: one insert for the
+// change log rows, then a single batch for their webhook events and notifications.
+func recordChanges(ctx context.Context, tx pgx.Tx, entityType string, entityIDs []string, entityKeys []string, action string, by string) error {
+	sql := `
+insert into change_log (entity_type, entity_id, entity_key, action, changed_by)
+select $1, entity_id, entity_key, $4, $5
+from unnest($2::uuid[], $3::text[]) with ordinality as keys (entity_id, entity_key, ordinal)
+order by ordinal
+returning change_id, entity_id::text, entity_key, changed_at
+	`
+	if err := lockChangeLog(ctx, tx); err != nil {
+		return err
+	}
+	rows, err := tx.Query(ctx, sql, entityType, entityIDs, entityKeys, action, by)
+	if err != nil {
+		return err
+	}
+	var changes []models.Change
+	for rows.Next() {
+		change := models.Change{EntityType: entityType, Action: action, ChangedBy: by}
+		if err := rows.Scan(&change.ChangeID, &change.EntityID, &change.EntityKey, &change.ChangedAt); err != nil {
+			rows.Close()
+			return err
+		}
+		changes = append(changes, change)
+	}
+	rows.Close()
+	if rows.Err() != nil {
+		return rows.Err()
+	}
+	batch := &pgx.Batch{}
+	for i := range changes {
+		payload, err := json.Marshal(&changes[i])
+		if err != nil {
+			return err
+		}
+		batch.Queue(enqueueWebhookEventSQL, changes[i].ChangeID, entityType+"."+action, payload)
+		batch.Queue("select pg_notify($1, $2)", changeNotificationChannel, entityType+"|"+changes[i].EntityKey)
+	}
+	return tx.SendBatch(ctx, batch).Close()
+}
+
+func (repo *PostgresChangeLogRepository) GetLatestChangeID(ctx context.Context) (_ int64, err error) {
+	ctx, end := startOperation(ctx, "ChangeLogRepository.GetLatestChangeID")
+	defer end(&err)
+	var changeID int64
+	if err := queryable(ctx, repo.conn).QueryRow(ctx, "select coalesce(max(change_id), 0) from change_log").Scan(&changeID); err != nil {
+		return 0, err
+	}
+	return changeID, nil
+}
+
+func (repo *PostgresChangeLogRepository) GetSince(ctx context.Context, lastChangeID int64, limit int) (_ *[]models.Change, err error) {
+	ctx, end := startOperation(ctx, "ChangeLogRepository.GetSince")
+	defer end(&err)
+	sql := `
+select change_id, entity_type, coalesce(entity_id::text, ''), entity_key, coalesce(previous_key, ''), action, changed_by, changed_at
+from change_log
+where change_id > $1
+order by change_id
+limit $2
+	`
+	rows, err := queryable(ctx, repo.conn).Query(ctx, sql, lastChangeID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	changes := []models.Change{}
+	for rows.Next() {
+		var change models.Change
+		if err := rows.Scan(&change.ChangeID, &change.EntityType, &change.EntityID, &change.EntityKey, &change.PreviousKey, &change.Action, &change.ChangedBy, &change.ChangedAt); err != nil {
+			return nil, err
+		}
+		changes = append(changes, change)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return &changes, nil
+}
+
+// GetOldestChangeID returns the first change still in the log, or 0 when it
+// is empty; a stream cannot resume from before it.
+func (repo *PostgresChangeLogRepository) GetOldestChangeID(ctx context.Context) (_ int64, err error) {
+	ctx, end := startOperation(ctx, "ChangeLogRepository.GetOldestChangeID")
+	defer end(&err)
+	var changeID int64
+	if err := queryable(ctx, repo.conn).QueryRow(ctx, "select coalesce(min(change_id), 0) from change_log").Scan(&changeID); err != nil {
+		return 0, err
+	}
+	return changeID, nil
+}
+
+// Prune removes changes made before the cutoff. It stops short of the first
+// change a webhook event still refers to, so the log only ever loses a prefix
+// and GetOldestChangeID marks exactly how far back a stream can resume.
+func (repo *PostgresChangeLogRepository) Prune(ctx context.Context, before time.Time) (_ int64, err error) {
+	ctx, end := startOperation(ctx, "ChangeLogRepository.Prune")
+	defer end(&err)
+	sql := `
+delete from change_log
+where changed_at < $1
+	and change_id < coalesce((select min(change_id) from webhook_events), 9223372036854775807)
+	and change_id < coalesce((select min(change_id) from change_log where changed_at >= $1), 9223372036854775807)
+	`
+	tag, err := queryable(ctx, repo.conn).Exec(ctx, sql, before)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+func (repo *PostgresChangeLogRepository) Migrate(ctx context.Context) error {
+	var currentVersion int
+	row := repo.conn.QueryRow(ctx, "select current_version from table_versions where table_name = 'change_log'")
+	err := row.Scan(&currentVersion)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			currentVersion = 0
+		} else {
+			return err
+		}
+	}
+	if currentVersion < 1 {
+		_, err = repo.conn.Exec(ctx, `
+create table change_log (
+	change_id bigserial primary key,
+	entity_type text not null,
+	entity_key text not null,
+	action text not null,
+	changed_by text not null,
+	changed_at timestamptz not null default now()
+)
+		`)
+		if err != nil {
+			return err
+		}
+		_, err = repo.conn.Exec(ctx, `
+insert into table_versions (table_name, current_version)
+values ('change_log', 1)
+		`)
+		if err != nil {
+			return err
+		}
+	}
+	if currentVersion < 2 {
+		_, err = repo.conn.Exec(ctx, `
+alter table change_log
+	add column previous_key text
+		`)
+		if err != nil {
+			return err
+		}
+		_, err = repo.conn.Exec(ctx, `
+update table_versions set current_version = 2 where table_name = 'change_log'
+		`)
+		if err != nil {
+			return err
+		}
+	}
+	if currentVersion < 3 {
+		_, err = repo.conn.Exec(ctx, `
+alter table change_log
+	add column entity_id uuid;
+create index change_log_entity_id on change_log (entity_id)
+		`)
+		if err != nil {
+			return err
+		}
+		_, err = repo.conn.Exec(ctx, `
+update table_versions set current_version = 3 where table_name = 'change_log'
+		`)
+		if err != nil {
+			return err
+		}
+	}
+	if currentVersion < 4 {
+		_, err = repo.conn.Exec(ctx, `
+create index change_log_changed_at on change_log (changed_at)
+		`)
+		if err != nil {
+			return err
+		}
+		_, err = repo.conn.Exec(ctx, `
+update table_versions set current_version = 4 where table_name = 'change_log'
+		`)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package repositories
+
+import (
+	"config/models"
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// newTestPool connects to the disposable database named by TEST_DATABASE_URL
+// and migrates it; tests that need Postgres are skipped without one.
+func newTestPool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(pool.Close)
+	if err := MigratePostgres(ctx, pool); err != nil {
+		t.Fatal(err)
+	}
+	return pool
+}
+
+func TestChangeLogIDsBecomeVisibleInOrder(t *testing.T) {
+	ctx := context.Background()
+	pool := newTestPool(t)
+	repo := NewPostgresChangeLogRepository(pool)
+	cursor, err := repo.GetLatestChangeID(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := pool.Begin(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer first.Rollback(ctx)
+	if err := recordChange(ctx, first, "configSettings", "", "first", models.ChangeActionUpdated, "test"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The second writer starts after the first took its id but tries to commit
+	// before it; without the change log lock it would become visible first.
+	secondDone := make(chan error, 1)
+	go func() {
+		secondDone <- pgx.BeginFunc(ctx, pool, func(tx pgx.Tx) error {
+			return recordChange(ctx, tx, "configSettings", "", "second", models.ChangeActionUpdated, "test")
+		})
+	}()
+	select {
+	case err := <-secondDone:
+		t.Fatalf("expected the second writer to wait for the first, it finished with %v", err)
+	case <-time.After(200 * time.Millisecond):
+	}
+	changes, err := repo.GetSince(ctx, cursor, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(*changes) != 0 {
+		t.Fatalf("expected no visible changes while the first writer is open, got %+v", *changes)
+	}
+
+	if err := first.Commit(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-secondDone; err != nil {
+		t.Fatal(err)
+	}
+	changes, err = repo.GetSince(ctx, cursor, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(*changes) != 2 || (*changes)[0].EntityKey != "first" || (*changes)[1].EntityKey != "second" {
+		t.Fatalf("expected both changes in commit order, got %+v", *changes)
+	}
+}
@@ -1,68 +1,164 @@
 package repositories
 
 import (
+	"config/caching"
+	"config/models"
 	"context"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-type ConfigSettingsRepository struct {
-	conn *pgx.Conn
+type PostgresConfigSettingsRepository struct {
+	conn  *pgxpool.Pool
+	cache masterDataCache
 }
 
-func NewConfigSettingsRepository(conn *pgx.Conn) *ConfigSettingsRepository {
-	return &ConfigSettingsRepository{conn: conn}
+func NewPostgresConfigSettingsRepository(conn *pgxpool.Pool, cacheService caching.CacheService) *PostgresConfigSettingsRepository {
+	return &PostgresConfigSettingsRepository{conn: conn, cache: masterDataCache{cacheService}}
 }
 
-func (repo *ConfigSettingsRepository) GetUnderlyingConnection() *pgx.Conn {
-	return repo.conn
+func (repo *PostgresConfigSettingsRepository) GetMany(ctx context.Context) (_ *[]models.ConfigSetting, err error) {
+	ctx, end := startOperation(ctx, "ConfigSettingsRepository.GetMany")
+	defer end(&err)
+	settings := []models.ConfigSetting{}
+	if repo.cache.get(ctx, "CONFIG|ALL", &settings) {
+		return &settings, nil
+	}
+	rows, err := queryable(ctx, repo.conn).Query(ctx, `select name, setting_values from config_settings order by lower(name) collate "C", name collate "C"`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var setting models.ConfigSetting
+		if err := rows.Scan(&setting.Name, &setting.SettingValues); err != nil {
+			return nil, err
+		}
+		settings = append(settings, setting)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	repo.cache.set(ctx, "CONFIG|ALL", settings)
+	return &settings, nil
 }
 
-func (repo *ConfigSettingsRepository) GetOne(name string) (*[]string, error) {
+func (repo *PostgresConfigSettingsRepository) GetOne(ctx context.Context, name string) (_ *[]string, err error) {
+	ctx, end := startOperation(ctx, "ConfigSettingsRepository.GetOne")
+	defer end(&err)
 	var values []string
-	if err := repo.conn.QueryRow(
-		context.Background(),
+	if repo.cache.get(ctx, "CONFIG|ONE|"+name, &values) {
+		return &values, nil
+	}
+	if err := queryable(ctx, repo.conn).QueryRow(
+		ctx,
 		"select setting_values from config_settings where name = $1", name).Scan(&values); err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, nil
 		}
 		return nil, err
 	}
+	repo.cache.set(ctx, "CONFIG|ONE|"+name, values)
 	return &values, nil
 }
 
-func (repo *ConfigSettingsRepository) GetOneFlag(name string) (bool, error) {
+func (repo *PostgresConfigSettingsRepository) GetOneFlag(ctx context.Context, name string) (_ bool, err error) {
+	ctx, end := startOperation(ctx, "ConfigSettingsRepository.GetOneFlag")
+	defer end(&err)
 	var flag bool
-	if err := repo.conn.QueryRow(
-		context.Background(),
+	if repo.cache.get(ctx, "CONFIG|FLAG|"+name, &flag) {
+		return flag, nil
+	}
+	if err := queryable(ctx, repo.conn).QueryRow(
+		ctx,
 		"select setting_values[1]::boolean as flag from config_settings where name = $1", name).Scan(&flag); err != nil {
 		if err == pgx.ErrNoRows {
 			return false, nil
 		}
 		return false, err
 	}
+	repo.cache.set(ctx, "CONFIG|FLAG|"+name, flag)
 	return flag, nil
 }
 
-func (repo *ConfigSettingsRepository) Upsert(name string, values []string, tx pgx.Tx) error {
+func (repo *PostgresConfigSettingsRepository) Upsert(ctx context.Context, name string, values []string, by string) (err error) {
+	ctx, end := startOperation(ctx, "ConfigSettingsRepository.Upsert")
+	defer end(&err)
 	sql := `
-insert into config_settings (name, setting_values) 
+insert into config_settings (name, setting_values)
 	values ($1, $2)
 on conflict (name) do update
 set setting_values = $2
+returning (xmax = 0) as inserted
 	`
-	if tx != nil {
-		_, err := tx.Exec(context.Background(), sql, name, values)
-		return err
-	} else {
-		_, err := repo.conn.Exec(context.Background(), sql, name, values)
-		return err
+	err = inTransaction(ctx, repo.conn, func(tx pgx.Tx) error {
+		var inserted bool
+		if err := tx.QueryRow(ctx, sql, name, values).Scan(&inserted); err != nil {
+			return err
+		}
+		action := models.ChangeActionUpdated
+		if inserted {
+			action = models.ChangeActionCreated
+		}
+		return recordChange(ctx, tx, "configSettings", "", name, action, by)
+	})
+	if err != nil {
+		return translateError(err, "config setting", name)
+	}
+	repo.cache.invalidate(ctx, "configSettings", name)
+	return nil
+}
+
+func (repo *PostgresConfigSettingsRepository) Modify(ctx context.Context, name string, modify func(setting *models.ConfigSetting) error, by string) (_ *models.ConfigSetting, err error) {
+	ctx, end := startOperation(ctx, "ConfigSettingsRepository.Modify")
+	defer end(&err)
+	setting := models.ConfigSetting{Name: name}
+	err = inTransaction(ctx, repo.conn, func(tx pgx.Tx) error {
+		if err := tx.QueryRow(ctx, "select setting_values from config_settings where name = $1 for update", name).Scan(&setting.SettingValues); err != nil {
+			return err
+		}
+		if err := modify(&setting); err != nil {
+			return err
+		}
+		if setting.Name != name {
+			return newDomainError(ErrValidationFailed, "config setting", name, "name cannot be changed")
+		}
+		if _, err := tx.Exec(ctx, "update config_settings set setting_values = $2 where name = $1", name, setting.SettingValues); err != nil {
+			return err
+		}
+		return recordChange(ctx, tx, "configSettings", "", name, models.ChangeActionUpdated, by)
+	})
+	if err != nil {
+		return nil, translateError(err, "config setting", name)
 	}
+	repo.cache.invalidate(ctx, "configSettings", name)
+	return &setting, nil
+}
+
+func (repo *PostgresConfigSettingsRepository) Delete(ctx context.Context, name string, by string) (err error) {
+	ctx, end := startOperation(ctx, "ConfigSettingsRepository.Delete")
+	defer end(&err)
+	err = inTransaction(ctx, repo.conn, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, "delete from config_settings where name = $1", name)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() != 1 {
+			return newDomainError(ErrNotFound, "config setting", name, "")
+		}
+		return recordChange(ctx, tx, "configSettings", "", name, models.ChangeActionDeleted, by)
+	})
+	if err != nil {
+		return translateError(err, "config setting", name)
+	}
+	repo.cache.invalidate(ctx, "configSettings", name)
+	return nil
 }
 
-func (repo *ConfigSettingsRepository) Migrate() error {
+func (repo *PostgresConfigSettingsRepository) Migrate(ctx context.Context) error {
 	var currentVersion int
-	row := repo.conn.QueryRow(context.Background(), "select current_version from table_versions where table_name = 'config_settings'")
+	row := repo.conn.QueryRow(ctx, "select current_version from table_versions where table_name = 'config_settings'")
 	err := row.Scan(&currentVersion)
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -72,7 +168,7 @@ func (repo *ConfigSettingsRepository) Migrate() error {
 		}
 	}
 	if currentVersion < 1 {
-		_, err = repo.conn.Exec(context.Background(), `
+		_, err = repo.conn.Exec(ctx, `
 create table config_settings (
 	name text primary key,
 	setting_values text[] not null
@@ -81,7 +177,7 @@ create table config_settings (
 		if err != nil {
 			return err
 		}
-		_, err = repo.conn.Exec(context.Background(), `
+		_, err = repo.conn.Exec(ctx, `
 insert into table_versions (table_name, current_version)
 values ('config_settings', 1)		
 		`)
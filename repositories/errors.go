@@ -0,0 +1,87 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var (
+	ErrNotFound         = errors.New("not found")
+	ErrAlreadyExists    = errors.New("already exists")
+	ErrConflict         = errors.New("conflict")
+	ErrValidationFailed = errors.New("validation failed")
+)
+
+type DomainError struct {
+	Kind   error
+	Entity string
+	Key    string
+	Detail string
+	Err    error
+}
+
+func (err *DomainError) Error() string {
+	message := err.Entity
+	if err.Key != "" {
+		message += fmt.Sprintf(" '%v'", err.Key)
+	}
+	message += " " + err.Kind.Error()
+	if err.Detail != "" {
+		message += ": " + err.Detail
+	}
+	return message
+}
+
+func (err *DomainError) Is(target error) bool {
+	return target == err.Kind
+}
+
+func (err *DomainError) Unwrap() error {
+	return err.Err
+}
+
+func newDomainError(kind error, entity string, key string, detail string) error {
+	return &DomainError{Kind: kind, Entity: entity, Key: key, Detail: detail}
+}
+
+// checkAuthorship rejects a Modify callback that changed createdBy or
+// updatedBy, which only the repository writes.
+func checkAuthorship(entity string, key string, storedCreatedBy string, storedUpdatedBy string, createdBy string, updatedBy string) error {
+	if createdBy != storedCreatedBy {
+		return newDomainError(ErrValidationFailed, entity, key, "createdBy cannot be changed")
+	}
+	if updatedBy != storedUpdatedBy {
+		return newDomainError(ErrValidationFailed, entity, key, "updatedBy cannot be changed")
+	}
+	return nil
+}
+
+func translateError(err error, entity string, key string) error {
+	if err == nil {
+		return nil
+	}
+	var domainErr *DomainError
+	if errors.As(err, &domainErr) {
+		return err
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return &DomainError{Kind: ErrNotFound, Entity: entity, Key: key, Err: err}
+	}
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+	switch {
+	case pgErr.Code == "23505":
+		return &DomainError{Kind: ErrAlreadyExists, Entity: entity, Key: key, Detail: pgErr.Detail, Err: err}
+	case pgErr.Code == "23503", pgErr.Code == "40001", pgErr.Code == "40P01":
+		return &DomainError{Kind: ErrConflict, Entity: entity, Key: key, Detail: pgErr.Message, Err: err}
+	case pgErr.Code == "23502", pgErr.Code == "23514", strings.HasPrefix(pgErr.Code, "22"):
+		return &DomainError{Kind: ErrValidationFailed, Entity: entity, Key: key, Detail: pgErr.Message, Err: err}
+	}
+	return err
+}
@@ -0,0 +1,43 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestTranslateError(t *testing.T) {
+	cases := []struct {
+		err      error
+		expected error
+	}{
+		{pgx.ErrNoRows, ErrNotFound},
+		{fmt.Errorf("scanning: %w", pgx.ErrNoRows), ErrNotFound},
+		{&pgconn.PgError{Code: "23505"}, ErrAlreadyExists},
+		{&pgconn.PgError{Code: "23503"}, ErrConflict},
+		{&pgconn.PgError{Code: "40001"}, ErrConflict},
+		{&pgconn.PgError{Code: "23502"}, ErrValidationFailed},
+		{&pgconn.PgError{Code: "23514"}, ErrValidationFailed},
+		{&pgconn.PgError{Code: "22001"}, ErrValidationFailed},
+	}
+	for _, c := range cases {
+		translated := translateError(c.err, "product", "P-100")
+		if !errors.Is(translated, c.expected) {
+			t.Errorf("expected %v to translate to %v, got %v", c.err, c.expected, translated)
+		}
+		if !errors.Is(translated, c.err) && !errors.As(translated, new(*pgconn.PgError)) {
+			t.Errorf("expected %v to remain in the chain of %v", c.err, translated)
+		}
+	}
+
+	other := &pgconn.PgError{Code: "53300"}
+	if translated := translateError(other, "product", "P-100"); translated != other {
+		t.Errorf("expected unmapped errors to pass through, got %v", translated)
+	}
+	if translated := translateError(nil, "product", "P-100"); translated != nil {
+		t.Errorf("expected nil to pass through, got %v", translated)
+	}
+}
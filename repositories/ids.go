@@ -0,0 +1,26 @@
+package repositories
+
+import (
+	"crypto/rand"
+	"fmt"
+	"regexp"
+)
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// isUUID keeps malformed ids away from Postgres, which would reject them
+// with an invalid input error rather than simply finding nothing.
+func isUUID(id string) bool {
+	return uuidPattern.MatchString(id)
+}
+
+// newUUID generates a random (version 4) UUID, matching gen_random_uuid().
+func newUUID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
@@ -0,0 +1,41 @@
+package repositories
+
+import (
+	"config/metrics"
+	"config/tracing"
+	"context"
+	"time"
+)
+
+var queryTimeout = 30 * time.Second
+
+func SetQueryTimeout(timeout time.Duration) {
+	queryTimeout = timeout
+}
+
+// startOperation returns the function to defer with the address of the
+// operation's error result, so a failed operation marks its span as failed.
+func startOperation(ctx context.Context, name string) (context.Context, func(*error)) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	ctx, span := tracing.Start(ctx, name)
+	observe := metrics.TimeQuery(name)
+	return ctx, func(err *error) {
+		observe()
+		tracing.EndSpan(span, *err)
+		cancel()
+	}
+}
+
+// startStreamingOperation is startOperation without the client-side query
+// timeout, for reads that hand rows to the caller as they arrive and may
+// legitimately outlive it. The server-side statement_timeout set on every
+// pooled connection would still cancel them, so the streaming reads also run
+// inside withoutStatementTimeout; only the caller's context bounds them.
+func startStreamingOperation(ctx context.Context, name string) (context.Context, func(*error)) {
+	ctx, span := tracing.Start(ctx, name)
+	observe := metrics.TimeQuery(name)
+	return ctx, func(err *error) {
+		observe()
+		tracing.EndSpan(span, *err)
+	}
+}
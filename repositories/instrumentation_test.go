@@ -0,0 +1,38 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestFailedOperationsMarkTheirSpans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	operation := func(fail bool) (err error) {
+		_, end := startOperation(context.Background(), "ProductsRepository.GetOne")
+		defer end(&err)
+		if fail {
+			return errors.New("connection reset")
+		}
+		return nil
+	}
+	operation(true)
+	operation(false)
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("expected two spans, got %v", len(spans))
+	}
+	if status := spans[0].Status(); status.Code != codes.Error || status.Description != "connection reset" || len(spans[0].Events()) != 1 {
+		t.Fatalf("expected the failed span to record its error, got %+v", status)
+	}
+	if status := spans[1].Status(); status.Code == codes.Error {
+		t.Fatalf("expected the successful span not to be marked failed, got %+v", status)
+	}
+}
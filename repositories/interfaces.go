@@ -0,0 +1,89 @@
+package repositories
+
+import (
+	"config/models"
+	"context"
+	"time"
+)
+
+type Transactor interface {
+	WithinTransaction(ctx context.Context, work func(ctx context.Context) error) error
+}
+
+type ProductsRepository interface {
+	GetOne(ctx context.Context, productCode string) (*models.Product, error)
+	GetByID(ctx context.Context, id string) (*models.Product, error)
+	GetMany(ctx context.Context) (*[]models.Product, error)
+	ForEach(ctx context.Context, fn func(product *models.Product) error) error
+	Create(ctx context.Context, product *models.Product, by string) error
+	Update(ctx context.Context, product *models.Product, by string) error
+	Modify(ctx context.Context, productCode string, modify func(product *models.Product) error, by string) (*models.Product, error)
+	Rename(ctx context.Context, productCode string, newProductCode string, by string) (*models.Product, error)
+	Delete(ctx context.Context, productCode string, by string) error
+}
+
+type TestsRepository interface {
+	GetOne(ctx context.Context, testName string) (*models.Test, error)
+	GetByID(ctx context.Context, id string) (*models.Test, error)
+	GetMany(ctx context.Context, pageSize int, lastKey *string, criteria *models.TestCriteria) (*[]models.Test, error)
+	ForEach(ctx context.Context, criteria *models.TestCriteria, fn func(test *models.Test) error) error
+	Create(ctx context.Context, test *models.Test, by string) error
+	Update(ctx context.Context, test *models.Test, by string) error
+	Modify(ctx context.Context, testName string, modify func(test *models.Test) error, by string) (*models.Test, error)
+	Rename(ctx context.Context, testName string, newTestName string, by string) (*models.Test, error)
+	Delete(ctx context.Context, testName string, by string) error
+}
+
+type UnitsRepository interface {
+	GetOne(ctx context.Context, fullName string) (*models.Unit, error)
+	GetByID(ctx context.Context, id string) (*models.Unit, error)
+	GetMany(ctx context.Context) (*[]models.Unit, error)
+	ForEach(ctx context.Context, fn func(unit *models.Unit) error) error
+	Create(ctx context.Context, unit *models.Unit, by string) error
+	InsertMany(ctx context.Context, units *[]models.Unit, by string) error
+	Update(ctx context.Context, unit *models.Unit, by string) error
+	Modify(ctx context.Context, fullName string, modify func(unit *models.Unit) error, by string) (*models.Unit, error)
+	Delete(ctx context.Context, fullName string, by string) error
+}
+
+type ConfigSettingsRepository interface {
+	GetMany(ctx context.Context) (*[]models.ConfigSetting, error)
+	GetOne(ctx context.Context, name string) (*[]string, error)
+	GetOneFlag(ctx context.Context, name string) (bool, error)
+	Upsert(ctx context.Context, name string, values []string, by string) error
+	Modify(ctx context.Context, name string, modify func(setting *models.ConfigSetting) error, by string) (*models.ConfigSetting, error)
+	Delete(ctx context.Context, name string, by string) error
+}
+
+type AliasesRepository interface {
+	Resolve(ctx context.Context, entityType string, key string) (string, error)
+}
+
+type ChangeLogRepository interface {
+	GetLatestChangeID(ctx context.Context) (int64, error)
+	GetSince(ctx context.Context, lastChangeID int64, limit int) (*[]models.Change, error)
+	GetOldestChangeID(ctx context.Context) (int64, error)
+	Prune(ctx context.Context, before time.Time) (int64, error)
+}
+
+type WebhooksRepository interface {
+	GetSubscriptions(ctx context.Context) (*[]models.WebhookSubscription, error)
+	GetSubscription(ctx context.Context, subscriptionID int64) (*models.WebhookSubscription, error)
+	CreateSubscription(ctx context.Context, subscription *models.WebhookSubscription, by string) error
+	UpdateSubscription(ctx context.Context, subscription *models.WebhookSubscription, by string) error
+	DeleteSubscription(ctx context.Context, subscriptionID int64) (bool, error)
+	GetDeliveries(ctx context.Context, subscriptionID int64, status *string, limit int) (*[]models.WebhookDelivery, error)
+	ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) (*[]models.WebhookDelivery, error)
+	MarkDelivered(ctx context.Context, deliveryID int64, lease time.Time) error
+	MarkFailed(ctx context.Context, deliveryID int64, lease time.Time, lastError string, nextAttemptAt *time.Time) error
+	Replay(ctx context.Context, subscriptionID int64, deliveryID *int64, status *string) (int64, error)
+	PruneDeliveries(ctx context.Context, before time.Time) (int64, error)
+}
+
+type TableVersionsRepository interface {
+	GetAll(ctx context.Context) (map[string]int, error)
+}
+
+type ChangeNotifier interface {
+	Subscribe(subscriber func(ChangeNotification)) func()
+}
@@ -0,0 +1,113 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
+)
+
+// NormalizeKey is the form product codes and test names are stored in:
+// surrounding whitespace trimmed and inner runs of whitespace collapsed to a
+// single space. Case is kept for display but ignored for uniqueness.
+func NormalizeKey(key string) string {
+	return strings.Join(strings.Fields(key), " ")
+}
+
+// FoldKey is the form keys are compared in, matching the lower() indexes.
+func FoldKey(key string) string {
+	return strings.ToLower(NormalizeKey(key))
+}
+
+// findKey returns the stored key in values that key refers to, if any.
+func findKey[V any](values map[string]V, key string) (string, bool) {
+	key = NormalizeKey(key)
+	if _, ok := values[key]; ok {
+		return key, true
+	}
+	folded := strings.ToLower(key)
+	for stored := range values {
+		if strings.ToLower(stored) == folded {
+			return stored, true
+		}
+	}
+	return "", false
+}
+
+// normalizeKeyColumn brings existing keys into normalized form and adds the
+// case-insensitive lookup and unique indexes. Where existing keys already
+// collide once normalized, the first of each group is kept in the unique
+// index and the others are logged, left as they are and exempted by id, so
+// the migration can finish without letting any new variant in.
+func normalizeKeyColumn(ctx context.Context, tx pgx.Tx, table string, column string) error {
+	normalized := fmt.Sprintf(`regexp_replace(btrim(%v), '\s+', ' ', 'g')`, column)
+	sql := fmt.Sprintf(`
+select id::text, %[2]v, folded, rank
+from (
+	select id, %[2]v, lower(%[3]v) as folded,
+		row_number() over (partition by lower(%[3]v) order by %[2]v = %[3]v desc, %[2]v collate "C") as rank,
+		count(*) over (partition by lower(%[3]v)) as keys
+	from %[1]v
+) grouped
+where keys > 1
+order by folded, rank
+	`, table, column, normalized)
+	rows, err := tx.Query(ctx, sql)
+	if err != nil {
+		return err
+	}
+	var exempt, collisions []string
+	for rows.Next() {
+		var id, key, folded string
+		var rank int
+		if err := rows.Scan(&id, &key, &folded, &rank); err != nil {
+			rows.Close()
+			return err
+		}
+		if rank == 1 {
+			collisions = append(collisions, "["+key)
+			continue
+		}
+		exempt = append(exempt, id)
+		collisions[len(collisions)-1] += " | " + key
+	}
+	rows.Close()
+	if rows.Err() != nil {
+		return rows.Err()
+	}
+	if len(collisions) > 0 {
+		log.Warn().Msgf("%v %v values collide once normalized; the first of each was kept and the rest left as they are, rename or delete them: %v]", table, column, strings.Join(collisions, "], "))
+	}
+
+	sql = fmt.Sprintf(`
+update %[1]v set %[2]v = %[3]v where %[2]v <> %[3]v and id::text <> all($1::text[])
+	`, table, column, normalized)
+	if _, err := tx.Exec(ctx, sql, exempt); err != nil {
+		return err
+	}
+	sql = fmt.Sprintf(`create index %[1]v_%[2]v_folded on %[1]v (lower(%[2]v))`, table, column)
+	if _, err := tx.Exec(ctx, sql); err != nil {
+		return err
+	}
+	sql = fmt.Sprintf(`create unique index %[1]v_%[2]v_folded_unique on %[1]v (lower(%[2]v))`, table, column)
+	if len(exempt) > 0 {
+		// Index predicates cannot take parameters, so format quotes the
+		// exempt ids into the statement server-side.
+		template := fmt.Sprintf(`create unique index %[1]v_%[2]v_folded_unique on %[1]v (lower(%[2]v)) where id <> all (%%L::uuid[])`, table, column)
+		if err := tx.QueryRow(ctx, "select format($1, $2::text[])", template, exempt).Scan(&sql); err != nil {
+			return err
+		}
+	}
+	_, err = tx.Exec(ctx, sql)
+	return err
+}
+
+// keyMatch is the condition picking the one row a normalized key in $1
+// refers to. Folded keys are unique except where the key migration found
+// rows that already collided; among those the exact match wins, then the
+// first in key order, so a write never touches more than one of them.
+func keyMatch(table string, column string) string {
+	return fmt.Sprintf(`id = (select id from %[1]v where lower(%[2]v) = lower($1) order by %[2]v = $1 desc, %[2]v collate "C" limit 1)`, table, column)
+}
@@ -0,0 +1,90 @@
+package repositories
+
+import (
+	"config/models"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func TestNormalizeKey(t *testing.T) {
+	cases := []struct {
+		key      string
+		expected string
+	}{
+		{"Tensile Strength", "Tensile Strength"},
+		{"  Tensile \t Strength\n", "Tensile Strength"},
+		{"Tear  Strength (Elmendorf)", "Tear Strength (Elmendorf)"},
+		{" ", ""},
+	}
+	for _, c := range cases {
+		if actual := NormalizeKey(c.key); actual != c.expected {
+			t.Errorf("NormalizeKey(%q) = %q, expected %q", c.key, actual, c.expected)
+		}
+	}
+	if FoldKey(" Tensile  STRENGTH") != FoldKey("tensile strength") {
+		t.Error("expected keys differing only in case and spacing to fold together")
+	}
+}
+
+func TestKeyMigrationKeepsCollidingKeys(t *testing.T) {
+	ctx := context.Background()
+	setup := newTestPool(t)
+	schema := fmt.Sprintf("key_migration_%d", time.Now().UnixNano())
+	if _, err := setup.Exec(ctx, "create schema "+schema); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { setup.Exec(ctx, "drop schema "+schema+" cascade") })
+	config, err := pgxpool.ParseConfig(os.Getenv("TEST_DATABASE_URL"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.ConnConfig.RuntimeParams["search_path"] = schema
+	pool, err := pgxpool.NewWithConfig(ctx, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(pool.Close)
+	if err := MigratePostgres(ctx, pool); err != nil {
+		t.Fatal(err)
+	}
+
+	// Seed rows as they could exist before version 4, then run it again.
+	_, err = pool.Exec(ctx, `
+drop index products_product_code_folded;
+drop index products_product_code_folded_unique;
+insert into products (product_code, description) values ('Tensile Strength', 'first'), ('tensile  strength', 'second'), (' Plain  Weave ', 'plain');
+update table_versions set current_version = 3 where table_name = 'products'
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := NewPostgresProductsRepository(pool, nil)
+	if err := repo.Migrate(ctx); err != nil {
+		t.Fatalf("expected colliding keys not to stop the migration, got %v", err)
+	}
+
+	if product, err := repo.GetOne(ctx, "plain weave"); err != nil || product.ProductCode != "Plain Weave" {
+		t.Fatalf("expected keys without collisions to be normalized, got %+v (%v)", product, err)
+	}
+	if err := repo.Create(ctx, &models.Product{ProductCode: "PLAIN WEAVE"}, "test"); !errors.Is(err, ErrAlreadyExists) {
+		t.Fatalf("expected keys without collisions to stay unique, got %v", err)
+	}
+	if err := repo.Create(ctx, &models.Product{ProductCode: "TENSILE STRENGTH"}, "test"); !errors.Is(err, ErrAlreadyExists) {
+		t.Fatalf("expected a new variant of a colliding key to be rejected, got %v", err)
+	}
+	if product, err := repo.GetOne(ctx, "Tensile Strength"); err != nil || product.Description != "first" {
+		t.Fatalf("expected the exact match among colliding keys, got %+v (%v)", product, err)
+	}
+	if err := repo.Delete(ctx, "Tensile Strength", "test"); err != nil {
+		t.Fatal(err)
+	}
+	if product, err := repo.GetOne(ctx, "tensile strength"); err != nil || product.ProductCode != "tensile  strength" {
+		t.Fatalf("expected the delete to leave the other colliding key, got %+v (%v)", product, err)
+	}
+}
@@ -0,0 +1,93 @@
+package repositories
+
+import (
+	"config/caching"
+	"config/metrics"
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// masterDataCacheTTL is kept short because a read that races a write can fill
+// the cache with the row as it was before the write committed; invalidation
+// cannot undo a fill that lands after it, so expiry bounds how long that lasts.
+const masterDataCacheTTL = time.Minute
+
+const invalidateTimeout = 5 * time.Second
+
+type masterDataCache struct {
+	cacheService caching.CacheService
+}
+
+func (mdc *masterDataCache) get(ctx context.Context, key string, target interface{}) bool {
+	if mdc.cacheService == nil {
+		return false
+	}
+	found, value, err := mdc.cacheService.Get(ctx, key)
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msgf("unable to read '%s' from cache", key)
+		return false
+	}
+	if !found {
+		metrics.CacheMiss("masterData")
+		return false
+	}
+	if err := json.Unmarshal([]byte(value), target); err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msgf("unable to unmarshal cached value for '%s'", key)
+		metrics.CacheMiss("masterData")
+		return false
+	}
+	metrics.CacheHit("masterData")
+	return true
+}
+
+func (mdc *masterDataCache) set(ctx context.Context, key string, value interface{}) {
+	if mdc.cacheService == nil {
+		return
+	}
+	valueBytes, err := json.Marshal(value)
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msgf("unable to marshal value for '%s'", key)
+		return
+	}
+	if err := mdc.cacheService.Set(ctx, key, string(valueBytes), masterDataCacheTTL); err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msgf("unable to write '%s' to cache", key)
+	}
+}
+
+// invalidate runs once the surrounding transaction commits, so readers cannot
+// refill the cache from rows the transaction has not made visible yet. By then
+// the operation's context has been cancelled, so the deletes run on a fresh one
+// that only keeps the request's logger.
+func (mdc *masterDataCache) invalidate(ctx context.Context, entityType string, key string) {
+	logger := log.Ctx(ctx)
+	afterCommit(ctx, func() {
+		ctx, cancel := context.WithTimeout(logger.WithContext(context.Background()), invalidateTimeout)
+		defer cancel()
+		InvalidateMasterData(ctx, mdc.cacheService, ChangeNotification{EntityType: entityType, Key: key})
+	})
+}
+
+func InvalidateMasterData(ctx context.Context, cacheService caching.CacheService, change ChangeNotification) {
+	if cacheService == nil {
+		return
+	}
+	var keys []string
+	switch change.EntityType {
+	case "products":
+		keys = []string{"PRODUCTS|ALL", "PRODUCTS|ONE|" + FoldKey(change.Key)}
+	case "tests":
+		keys = []string{"TESTS|ONE|" + FoldKey(change.Key)}
+	case "units":
+		keys = []string{"UNITS|ALL"}
+	case "configSettings":
+		keys = []string{"CONFIG|ALL", "CONFIG|ONE|" + change.Key, "CONFIG|FLAG|" + change.Key}
+	}
+	for _, key := range keys {
+		if err := cacheService.Delete(ctx, key); err != nil {
+			log.Ctx(ctx).Warn().Err(err).Msgf("unable to invalidate '%s' in cache", key)
+		}
+	}
+}
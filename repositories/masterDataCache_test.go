@@ -0,0 +1,89 @@
+package repositories
+
+import (
+	"config/caching"
+	"config/models"
+	"context"
+	"testing"
+)
+
+// contextCheckingCache fails like a network cache would when handed a
+// cancelled context.
+type contextCheckingCache struct {
+	*caching.MemoryCacheService
+}
+
+func (cache contextCheckingCache) Delete(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return cache.MemoryCacheService.Delete(ctx, key)
+}
+
+func TestInvalidateWaitsForCommit(t *testing.T) {
+	ctx := context.Background()
+	cacheService := caching.NewMemoryCacheService(10)
+	cache := masterDataCache{cacheService}
+	cache.set(ctx, "CONFIG|ONE|Modifiers", []string{"warp"})
+	cache.set(ctx, "CONFIG|ALL", []string{})
+
+	var hooks []func()
+	txCtx := context.WithValue(ctx, afterCommitKey{}, &hooks)
+	cache.invalidate(txCtx, "configSettings", "Modifiers")
+	if found, _, _ := cacheService.Get(ctx, "CONFIG|ONE|Modifiers"); !found {
+		t.Fatal("expected the entry to survive until the transaction commits")
+	}
+
+	for _, hook := range hooks {
+		hook()
+	}
+	for _, key := range []string{"CONFIG|ONE|Modifiers", "CONFIG|ALL"} {
+		if found, _, _ := cacheService.Get(ctx, key); found {
+			t.Fatalf("expected %v to be invalidated after commit", key)
+		}
+	}
+}
+
+func TestInvalidateOutlivesTheOperationContext(t *testing.T) {
+	ctx := context.Background()
+	cacheService := contextCheckingCache{caching.NewMemoryCacheService(10)}
+	cache := masterDataCache{cacheService}
+	cache.set(ctx, "UNITS|ALL", []string{})
+
+	var hooks []func()
+	operationCtx, end := context.WithCancel(context.WithValue(ctx, afterCommitKey{}, &hooks))
+	cache.invalidate(operationCtx, "units", "inch")
+	end()
+	for _, hook := range hooks {
+		hook()
+	}
+	if found, _, _ := cacheService.Get(ctx, "UNITS|ALL"); found {
+		t.Fatal("expected the invalidation to run after the operation context was cancelled")
+	}
+}
+
+func TestInvalidateAfterCommittedOuterTransaction(t *testing.T) {
+	ctx := context.Background()
+	pool := newTestPool(t)
+	cacheService := contextCheckingCache{caching.NewMemoryCacheService(10)}
+	repo := NewPostgresProductsRepository(pool, cacheService)
+	product := &models.Product{ProductCode: "CACHE-" + t.Name()}
+	if err := repo.Create(ctx, product, "test"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { repo.Delete(ctx, product.ProductCode, "test") })
+	if _, err := repo.GetOne(ctx, product.ProductCode); err != nil {
+		t.Fatal(err)
+	}
+
+	err := NewPostgresTransactor(pool).WithinTransaction(ctx, func(ctx context.Context) error {
+		product.Description = "updated"
+		return repo.Update(ctx, product, "test")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found, _, _ := cacheService.Get(ctx, "PRODUCTS|ONE|"+FoldKey(product.ProductCode)); found {
+		t.Fatal("expected the committed update to invalidate the cached product")
+	}
+}
@@ -0,0 +1,53 @@
+package repositories
+
+import (
+	"context"
+)
+
+type MemoryAliasesRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryAliasesRepository(store *MemoryStore) *MemoryAliasesRepository {
+	return &MemoryAliasesRepository{store: store}
+}
+
+func (repo *MemoryAliasesRepository) Resolve(ctx context.Context, entityType string, key string) (string, error) {
+	defer repo.store.lock(ctx)()
+	if entityID, ok := repo.store.data.aliases[entityType][FoldKey(key)]; ok {
+		switch entityType {
+		case "products":
+			for productCode, product := range repo.store.data.products {
+				if product.ID == entityID {
+					return productCode, nil
+				}
+			}
+		case "tests":
+			for testName, test := range repo.store.data.tests {
+				if test.ID == entityID {
+					return testName, nil
+				}
+			}
+		}
+	}
+	return "", newDomainError(ErrNotFound, "alias", key, "")
+}
+
+func (store *MemoryStore) recordAlias(entityType string, entityID string, oldKey string, newKey string) {
+	aliases := store.data.aliases[entityType]
+	if aliases == nil {
+		aliases = make(map[string]string)
+		store.data.aliases[entityType] = aliases
+	}
+	delete(aliases, FoldKey(newKey))
+	aliases[FoldKey(oldKey)] = entityID
+}
+
+func (store *MemoryStore) deleteAliases(entityType string, entityID string) {
+	aliases := store.data.aliases[entityType]
+	for alias, target := range aliases {
+		if target == entityID {
+			delete(aliases, alias)
+		}
+	}
+}
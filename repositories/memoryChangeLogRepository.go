@@ -0,0 +1,53 @@
+package repositories
+
+import (
+	"config/models"
+	"context"
+	"time"
+)
+
+type MemoryChangeLogRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryChangeLogRepository(store *MemoryStore) *MemoryChangeLogRepository {
+	return &MemoryChangeLogRepository{store: store}
+}
+
+func (repo *MemoryChangeLogRepository) GetLatestChangeID(ctx context.Context) (int64, error) {
+	defer repo.store.lock(ctx)()
+	return repo.store.data.nextChangeID, nil
+}
+
+func (repo *MemoryChangeLogRepository) GetSince(ctx context.Context, lastChangeID int64, limit int) (*[]models.Change, error) {
+	defer repo.store.lock(ctx)()
+	changes := []models.Change{}
+	for _, change := range repo.store.data.changes {
+		if len(changes) == limit {
+			break
+		}
+		if change.ChangeID > lastChangeID {
+			changes = append(changes, change)
+		}
+	}
+	return &changes, nil
+}
+
+func (repo *MemoryChangeLogRepository) GetOldestChangeID(ctx context.Context) (int64, error) {
+	defer repo.store.lock(ctx)()
+	if len(repo.store.data.changes) == 0 {
+		return 0, nil
+	}
+	return repo.store.data.changes[0].ChangeID, nil
+}
+
+func (repo *MemoryChangeLogRepository) Prune(ctx context.Context, before time.Time) (int64, error) {
+	defer repo.store.lock(ctx)()
+	changes := repo.store.data.changes
+	pruned := 0
+	for pruned < len(changes) && changes[pruned].ChangedAt.Before(before) {
+		pruned++
+	}
+	repo.store.data.changes = append([]models.Change{}, changes[pruned:]...)
+	return int64(pruned), nil
+}
@@ -0,0 +1,85 @@
+package repositories
+
+import (
+	"config/models"
+	"context"
+	"strconv"
+)
+
+type MemoryConfigSettingsRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryConfigSettingsRepository(store *MemoryStore) *MemoryConfigSettingsRepository {
+	return &MemoryConfigSettingsRepository{store: store}
+}
+
+func (repo *MemoryConfigSettingsRepository) GetMany(ctx context.Context) (*[]models.ConfigSetting, error) {
+	defer repo.store.lock(ctx)()
+	settings := []models.ConfigSetting{}
+	for _, name := range sortedKeys(repo.store.data.configSettings) {
+		settings = append(settings, models.ConfigSetting{Name: name, SettingValues: copyStrings(repo.store.data.configSettings[name])})
+	}
+	return &settings, nil
+}
+
+func (repo *MemoryConfigSettingsRepository) GetOne(ctx context.Context, name string) (*[]string, error) {
+	defer repo.store.lock(ctx)()
+	values, ok := repo.store.data.configSettings[name]
+	if !ok {
+		return nil, nil
+	}
+	values = copyStrings(values)
+	return &values, nil
+}
+
+func (repo *MemoryConfigSettingsRepository) GetOneFlag(ctx context.Context, name string) (bool, error) {
+	defer repo.store.lock(ctx)()
+	values, ok := repo.store.data.configSettings[name]
+	if !ok || len(values) == 0 {
+		return false, nil
+	}
+	return strconv.ParseBool(values[0])
+}
+
+func (repo *MemoryConfigSettingsRepository) Upsert(ctx context.Context, name string, values []string, by string) error {
+	defer repo.store.lock(ctx)()
+	action := models.ChangeActionUpdated
+	if _, exists := repo.store.data.configSettings[name]; !exists {
+		action = models.ChangeActionCreated
+	}
+	repo.store.data.configSettings[name] = copyStrings(values)
+	repo.store.recordChange("configSettings", "", name, action, by)
+	return nil
+}
+
+func (repo *MemoryConfigSettingsRepository) Modify(ctx context.Context, name string, modify func(setting *models.ConfigSetting) error, by string) (*models.ConfigSetting, error) {
+	defer repo.store.lock(ctx)()
+	values, exists := repo.store.data.configSettings[name]
+	if !exists {
+		return nil, newDomainError(ErrNotFound, "config setting", name, "")
+	}
+	setting := models.ConfigSetting{Name: name, SettingValues: copyStrings(values)}
+	if err := modify(&setting); err != nil {
+		return nil, err
+	}
+	if setting.Name != name {
+		return nil, newDomainError(ErrValidationFailed, "config setting", name, "name cannot be changed")
+	}
+	if setting.SettingValues == nil {
+		return nil, newDomainError(ErrValidationFailed, "config setting", name, "settingValues cannot be null")
+	}
+	repo.store.data.configSettings[name] = copyStrings(setting.SettingValues)
+	repo.store.recordChange("configSettings", "", name, models.ChangeActionUpdated, by)
+	return &setting, nil
+}
+
+func (repo *MemoryConfigSettingsRepository) Delete(ctx context.Context, name string, by string) error {
+	defer repo.store.lock(ctx)()
+	if _, exists := repo.store.data.configSettings[name]; !exists {
+		return newDomainError(ErrNotFound, "config setting", name, "")
+	}
+	delete(repo.store.data.configSettings, name)
+	repo.store.recordChange("configSettings", "", name, models.ChangeActionDeleted, by)
+	return nil
+}
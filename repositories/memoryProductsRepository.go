@@ -0,0 +1,155 @@
+package repositories
+
+import (
+	"config/models"
+	"context"
+)
+
+type MemoryProductsRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryProductsRepository(store *MemoryStore) *MemoryProductsRepository {
+	return &MemoryProductsRepository{store: store}
+}
+
+func (repo *MemoryProductsRepository) GetOne(ctx context.Context, productCode string) (*models.Product, error) {
+	defer repo.store.lock(ctx)()
+	storedCode, ok := findKey(repo.store.data.products, productCode)
+	if !ok {
+		return nil, newDomainError(ErrNotFound, "product", productCode, "")
+	}
+	product := repo.store.data.products[storedCode]
+	return &product, nil
+}
+
+func (repo *MemoryProductsRepository) GetByID(ctx context.Context, id string) (*models.Product, error) {
+	defer repo.store.lock(ctx)()
+	for _, product := range repo.store.data.products {
+		if product.ID == id {
+			return &product, nil
+		}
+	}
+	return nil, newDomainError(ErrNotFound, "product", id, "")
+}
+
+func (repo *MemoryProductsRepository) GetMany(ctx context.Context) (*[]models.Product, error) {
+	defer repo.store.lock(ctx)()
+	var products []models.Product
+	for _, productCode := range sortedKeys(repo.store.data.products) {
+		products = append(products, repo.store.data.products[productCode])
+	}
+	return &products, nil
+}
+
+func (repo *MemoryProductsRepository) ForEach(ctx context.Context, fn func(product *models.Product) error) error {
+	products, err := repo.GetMany(ctx)
+	if err != nil {
+		return err
+	}
+	for i := range *products {
+		if err := fn(&(*products)[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (repo *MemoryProductsRepository) Create(ctx context.Context, product *models.Product, by string) error {
+	defer repo.store.lock(ctx)()
+	product.ProductCode = NormalizeKey(product.ProductCode)
+	if _, exists := findKey(repo.store.data.products, product.ProductCode); exists {
+		return newDomainError(ErrAlreadyExists, "product", product.ProductCode, "")
+	}
+	created := *product
+	created.ID = newUUID()
+	created.CreatedBy = by
+	created.UpdatedBy = by
+	repo.store.data.products[product.ProductCode] = created
+	product.ID = created.ID
+	repo.store.recordChange("products", created.ID, product.ProductCode, models.ChangeActionCreated, by)
+	return nil
+}
+
+func (repo *MemoryProductsRepository) Update(ctx context.Context, product *models.Product, by string) error {
+	defer repo.store.lock(ctx)()
+	storedCode, exists := findKey(repo.store.data.products, product.ProductCode)
+	if !exists {
+		return newDomainError(ErrNotFound, "product", product.ProductCode, "")
+	}
+	existing := repo.store.data.products[storedCode]
+	existing.Description = product.Description
+	existing.UpdatedBy = by
+	repo.store.data.products[storedCode] = existing
+	product.ID = existing.ID
+	product.ProductCode = storedCode
+	repo.store.recordChange("products", existing.ID, storedCode, models.ChangeActionUpdated, by)
+	return nil
+}
+
+func (repo *MemoryProductsRepository) Modify(ctx context.Context, productCode string, modify func(product *models.Product) error, by string) (*models.Product, error) {
+	defer repo.store.lock(ctx)()
+	storedCode, exists := findKey(repo.store.data.products, productCode)
+	if !exists {
+		return nil, newDomainError(ErrNotFound, "product", productCode, "")
+	}
+	product := repo.store.data.products[storedCode]
+	if err := modify(&product); err != nil {
+		return nil, err
+	}
+	if product.ProductCode != storedCode {
+		return nil, newDomainError(ErrValidationFailed, "product", storedCode, "productCode cannot be changed")
+	}
+	existing := repo.store.data.products[storedCode]
+	if product.ID != existing.ID {
+		return nil, newDomainError(ErrValidationFailed, "product", storedCode, "id cannot be changed")
+	}
+	if err := checkAuthorship("product", storedCode, existing.CreatedBy, existing.UpdatedBy, product.CreatedBy, product.UpdatedBy); err != nil {
+		return nil, err
+	}
+	product.UpdatedBy = by
+	repo.store.data.products[storedCode] = product
+	repo.store.recordChange("products", product.ID, storedCode, models.ChangeActionUpdated, by)
+	return &product, nil
+}
+
+func (repo *MemoryProductsRepository) Rename(ctx context.Context, productCode string, newProductCode string, by string) (*models.Product, error) {
+	defer repo.store.lock(ctx)()
+	newProductCode = NormalizeKey(newProductCode)
+	if newProductCode == "" {
+		return nil, newDomainError(ErrValidationFailed, "product", productCode, "new product code must not be empty")
+	}
+	storedCode, exists := findKey(repo.store.data.products, productCode)
+	if !exists {
+		return nil, newDomainError(ErrNotFound, "product", productCode, "")
+	}
+	if newProductCode == storedCode {
+		return nil, newDomainError(ErrValidationFailed, "product", productCode, "new product code must be different")
+	}
+	if other, exists := findKey(repo.store.data.products, newProductCode); exists && other != storedCode {
+		return nil, newDomainError(ErrAlreadyExists, "product", newProductCode, "")
+	}
+	product := repo.store.data.products[storedCode]
+	product.ProductCode = newProductCode
+	product.UpdatedBy = by
+	delete(repo.store.data.products, storedCode)
+	repo.store.data.products[newProductCode] = product
+	if FoldKey(storedCode) != FoldKey(newProductCode) {
+		repo.store.recordAlias("products", product.ID, storedCode, newProductCode)
+	}
+	repo.store.recordRename("products", product.ID, storedCode, newProductCode, by)
+	return &product, nil
+}
+
+func (repo *MemoryProductsRepository) Delete(ctx context.Context, productCode string, by string) error {
+	defer repo.store.lock(ctx)()
+	storedCode, exists := findKey(repo.store.data.products, productCode)
+	if !exists {
+		return newDomainError(ErrNotFound, "product", productCode, "")
+	}
+	product := repo.store.data.products[storedCode]
+	delete(repo.store.data.products, storedCode)
+	repo.store.deleteAliases("products", product.ID)
+	repo.store.recordChange("products", product.ID, storedCode, models.ChangeActionDeleted, by)
+	return nil
+}
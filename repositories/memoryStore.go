@@ -0,0 +1,223 @@
+package repositories
+
+import (
+	"config/models"
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type memoryTxKey struct{}
+
+type memoryWebhookEvent struct {
+	eventType string
+	payload   []byte
+	createdAt time.Time
+}
+
+type memoryData struct {
+	products       map[string]models.Product
+	tests          map[string]models.Test
+	units          []models.Unit
+	configSettings map[string][]string
+	aliases        map[string]map[string]string
+	changes        []models.Change
+	subscriptions  map[int64]models.WebhookSubscription
+	events         map[int64]memoryWebhookEvent
+	deliveries     map[int64]models.WebhookDelivery
+	nextChangeID   int64
+	nextSubID      int64
+	nextEventID    int64
+	nextDeliveryID int64
+	notifications  []ChangeNotification
+}
+
+type MemoryStore struct {
+	mutex            sync.Mutex
+	data             memoryData
+	subscribersMutex sync.RWMutex
+	nextSubscriberID int
+	subscribers      map[int]func(ChangeNotification)
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		data: memoryData{
+			products:       make(map[string]models.Product),
+			tests:          make(map[string]models.Test),
+			configSettings: make(map[string][]string),
+			aliases:        make(map[string]map[string]string),
+			subscriptions:  make(map[int64]models.WebhookSubscription),
+			events:         make(map[int64]memoryWebhookEvent),
+			deliveries:     make(map[int64]models.WebhookDelivery),
+		},
+		subscribers: make(map[int]func(ChangeNotification)),
+	}
+}
+
+// WithinTransaction snapshots the store only at the outermost level; cloning
+// it for every nested call made row-by-row work quadratic. A nested unit of
+// work that fails is not undone on its own, which matches a savepoint because
+// every memory repository write checks everything before changing anything.
+func (store *MemoryStore) WithinTransaction(ctx context.Context, work func(ctx context.Context) error) error {
+	if ctx.Value(memoryTxKey{}) == store {
+		return work(ctx)
+	}
+	unlock := store.lock(ctx)
+	snapshot := store.data.clone()
+	err := work(context.WithValue(ctx, memoryTxKey{}, store))
+	if err != nil {
+		store.data = snapshot
+	}
+	unlock()
+	return err
+}
+
+func (store *MemoryStore) Subscribe(subscriber func(ChangeNotification)) func() {
+	store.subscribersMutex.Lock()
+	defer store.subscribersMutex.Unlock()
+	subscriberID := store.nextSubscriberID
+	store.nextSubscriberID++
+	store.subscribers[subscriberID] = subscriber
+	return func() {
+		store.subscribersMutex.Lock()
+		defer store.subscribersMutex.Unlock()
+		delete(store.subscribers, subscriberID)
+	}
+}
+
+// lock is a no-op inside WithinTransaction, which already holds the mutex;
+// change notifications are only published once the outermost lock is released.
+func (store *MemoryStore) lock(ctx context.Context) func() {
+	if ctx.Value(memoryTxKey{}) == store {
+		return func() {}
+	}
+	store.mutex.Lock()
+	return func() {
+		notifications := store.data.notifications
+		store.data.notifications = nil
+		store.mutex.Unlock()
+		store.subscribersMutex.RLock()
+		defer store.subscribersMutex.RUnlock()
+		for _, notification := range notifications {
+			for _, subscriber := range store.subscribers {
+				subscriber(notification)
+			}
+		}
+	}
+}
+
+func (store *MemoryStore) recordChange(entityType string, entityID string, entityKey string, action string, by string) {
+	store.appendChange(models.Change{EntityType: entityType, EntityID: entityID, EntityKey: entityKey, Action: action, ChangedBy: by})
+}
+
+func (store *MemoryStore) recordRename(entityType string, entityID string, oldKey string, newKey string, by string) {
+	store.appendChange(models.Change{EntityType: entityType, EntityID: entityID, EntityKey: newKey, PreviousKey: oldKey, Action: models.ChangeActionRenamed, ChangedBy: by})
+	store.data.notifications = append(store.data.notifications, ChangeNotification{EntityType: entityType, Key: oldKey})
+}
+
+func (store *MemoryStore) appendChange(change models.Change) {
+	data := &store.data
+	data.nextChangeID++
+	change.ChangeID = data.nextChangeID
+	change.ChangedAt = time.Now()
+	data.changes = append(data.changes, change)
+	data.notifications = append(data.notifications, ChangeNotification{EntityType: change.EntityType, Key: change.EntityKey})
+
+	payload, _ := json.Marshal(change)
+	data.nextEventID++
+	eventType := change.EntityType + "." + change.Action
+	data.events[data.nextEventID] = memoryWebhookEvent{eventType: eventType, payload: payload, createdAt: change.ChangedAt}
+	for _, subscription := range data.subscriptions {
+		if !subscription.IsActive || (len(subscription.EventTypes) > 0 && !containsString(subscription.EventTypes, eventType)) {
+			continue
+		}
+		data.nextDeliveryID++
+		data.deliveries[data.nextDeliveryID] = models.WebhookDelivery{
+			DeliveryID:     data.nextDeliveryID,
+			SubscriptionID: subscription.SubscriptionID,
+			EventID:        data.nextEventID,
+			EventType:      eventType,
+			Status:         models.WebhookDeliveryStatusPending,
+			NextAttemptAt:  change.ChangedAt,
+		}
+	}
+}
+
+func (data memoryData) clone() memoryData {
+	copied := data
+	copied.products = make(map[string]models.Product, len(data.products))
+	for key, value := range data.products {
+		copied.products[key] = value
+	}
+	copied.tests = make(map[string]models.Test, len(data.tests))
+	for key, value := range data.tests {
+		copied.tests[key] = value
+	}
+	copied.units = append([]models.Unit(nil), data.units...)
+	copied.configSettings = make(map[string][]string, len(data.configSettings))
+	for key, value := range data.configSettings {
+		copied.configSettings[key] = value
+	}
+	copied.aliases = make(map[string]map[string]string, len(data.aliases))
+	for entityType, aliases := range data.aliases {
+		copied.aliases[entityType] = make(map[string]string, len(aliases))
+		for key, value := range aliases {
+			copied.aliases[entityType][key] = value
+		}
+	}
+	copied.changes = append([]models.Change(nil), data.changes...)
+	copied.subscriptions = make(map[int64]models.WebhookSubscription, len(data.subscriptions))
+	for key, value := range data.subscriptions {
+		copied.subscriptions[key] = value
+	}
+	copied.events = make(map[int64]memoryWebhookEvent, len(data.events))
+	for key, value := range data.events {
+		copied.events[key] = value
+	}
+	copied.deliveries = make(map[int64]models.WebhookDelivery, len(data.deliveries))
+	for key, value := range data.deliveries {
+		copied.deliveries[key] = value
+	}
+	copied.notifications = append([]ChangeNotification(nil), data.notifications...)
+	return copied
+}
+
+func copyStrings(values []string) []string {
+	if values == nil {
+		return nil
+	}
+	return append([]string{}, values...)
+}
+
+func containsString(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
+
+// sortedKeys orders keys the way the Postgres repositories do: case-insensitively
+// by code point, then by case, rather than in Go byte order.
+func sortedKeys[V any](values map[string]V) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keyLess(keys[i], keys[j])
+	})
+	return keys
+}
+
+func keyLess(a string, b string) bool {
+	if foldedA, foldedB := strings.ToLower(a), strings.ToLower(b); foldedA != foldedB {
+		return foldedA < foldedB
+	}
+	return a < b
+}
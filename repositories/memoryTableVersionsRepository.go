@@ -0,0 +1,13 @@
+package repositories
+
+import "context"
+
+type MemoryTableVersionsRepository struct{}
+
+func NewMemoryTableVersionsRepository() *MemoryTableVersionsRepository {
+	return &MemoryTableVersionsRepository{}
+}
+
+func (repo *MemoryTableVersionsRepository) GetAll(ctx context.Context) (map[string]int, error) {
+	return map[string]int{}, nil
+}
@@ -0,0 +1,235 @@
+package repositories
+
+import (
+	"config/models"
+	"context"
+	"regexp"
+	"strings"
+)
+
+const memoryPageSize = 500
+
+type MemoryTestsRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryTestsRepository(store *MemoryStore) *MemoryTestsRepository {
+	return &MemoryTestsRepository{store: store}
+}
+
+func (repo *MemoryTestsRepository) GetOne(ctx context.Context, testName string) (*models.Test, error) {
+	defer repo.store.lock(ctx)()
+	storedName, ok := findKey(repo.store.data.tests, testName)
+	if !ok {
+		return nil, newDomainError(ErrNotFound, "test", testName, "")
+	}
+	return copyTest(repo.store.data.tests[storedName]), nil
+}
+
+func (repo *MemoryTestsRepository) GetByID(ctx context.Context, id string) (*models.Test, error) {
+	defer repo.store.lock(ctx)()
+	for _, test := range repo.store.data.tests {
+		if test.ID == id {
+			return copyTest(test), nil
+		}
+	}
+	return nil, newDomainError(ErrNotFound, "test", id, "")
+}
+
+func (repo *MemoryTestsRepository) GetMany(ctx context.Context, pageSize int, lastKey *string, criteria *models.TestCriteria) (*[]models.Test, error) {
+	defer repo.store.lock(ctx)()
+	if pageSize < 0 {
+		return nil, newDomainError(ErrValidationFailed, "test", "", "pageSize must not be negative")
+	}
+	var namePattern *regexp.Regexp
+	if criteria.NamePattern != nil {
+		namePattern = likePattern("%" + *criteria.NamePattern + "%")
+	}
+	var tests []models.Test
+	for _, testName := range sortedKeys(repo.store.data.tests) {
+		if len(tests) == pageSize {
+			break
+		}
+		test := repo.store.data.tests[testName]
+		if lastKey != nil && !keyLess(*lastKey, testName) {
+			continue
+		}
+		if namePattern != nil && !namePattern.MatchString(testName) {
+			continue
+		}
+		if criteria.UnitTypeValues != nil && !containsString(*criteria.UnitTypeValues, test.UnitType) {
+			continue
+		}
+		tests = append(tests, *copyTest(test))
+	}
+	return &tests, nil
+}
+
+func (repo *MemoryTestsRepository) ForEach(ctx context.Context, criteria *models.TestCriteria, fn func(test *models.Test) error) error {
+	var lastKey *string
+	for {
+		page, err := repo.GetMany(ctx, memoryPageSize, lastKey, criteria)
+		if err != nil {
+			return err
+		}
+		for i := range *page {
+			if err := fn(&(*page)[i]); err != nil {
+				return err
+			}
+		}
+		if len(*page) < memoryPageSize {
+			return nil
+		}
+		lastKey = &(*page)[len(*page)-1].TestName
+	}
+}
+
+func (repo *MemoryTestsRepository) Create(ctx context.Context, test *models.Test, by string) error {
+	defer repo.store.lock(ctx)()
+	test.TestName = NormalizeKey(test.TestName)
+	if err := validateTestLists(test, test.TestName); err != nil {
+		return err
+	}
+	if _, exists := findKey(repo.store.data.tests, test.TestName); exists {
+		return newDomainError(ErrAlreadyExists, "test", test.TestName, "")
+	}
+	created := copyTest(*test)
+	created.ID = newUUID()
+	created.CreatedBy = by
+	created.UpdatedBy = by
+	repo.store.data.tests[test.TestName] = *created
+	test.ID = created.ID
+	repo.store.recordChange("tests", created.ID, test.TestName, models.ChangeActionCreated, by)
+	return nil
+}
+
+func (repo *MemoryTestsRepository) Update(ctx context.Context, test *models.Test, by string) error {
+	defer repo.store.lock(ctx)()
+	storedName, exists := findKey(repo.store.data.tests, test.TestName)
+	if !exists {
+		return newDomainError(ErrNotFound, "test", test.TestName, "")
+	}
+	existing := repo.store.data.tests[storedName]
+	if err := validateTestLists(test, storedName); err != nil {
+		return err
+	}
+	test.ID = existing.ID
+	test.TestName = storedName
+	updated := copyTest(*test)
+	updated.CreatedBy = existing.CreatedBy
+	updated.UpdatedBy = by
+	repo.store.data.tests[storedName] = *updated
+	repo.store.recordChange("tests", existing.ID, storedName, models.ChangeActionUpdated, by)
+	return nil
+}
+
+func (repo *MemoryTestsRepository) Modify(ctx context.Context, testName string, modify func(test *models.Test) error, by string) (*models.Test, error) {
+	defer repo.store.lock(ctx)()
+	storedName, exists := findKey(repo.store.data.tests, testName)
+	if !exists {
+		return nil, newDomainError(ErrNotFound, "test", testName, "")
+	}
+	existing := repo.store.data.tests[storedName]
+	test := copyTest(existing)
+	if err := modify(test); err != nil {
+		return nil, err
+	}
+	if test.TestName != storedName {
+		return nil, newDomainError(ErrValidationFailed, "test", storedName, "testName cannot be changed")
+	}
+	if test.ID != existing.ID {
+		return nil, newDomainError(ErrValidationFailed, "test", storedName, "id cannot be changed")
+	}
+	if err := checkAuthorship("test", storedName, existing.CreatedBy, existing.UpdatedBy, test.CreatedBy, test.UpdatedBy); err != nil {
+		return nil, err
+	}
+	if err := validateTestLists(test, storedName); err != nil {
+		return nil, err
+	}
+	test.UpdatedBy = by
+	repo.store.data.tests[storedName] = *copyTest(*test)
+	repo.store.recordChange("tests", test.ID, storedName, models.ChangeActionUpdated, by)
+	return test, nil
+}
+
+func (repo *MemoryTestsRepository) Rename(ctx context.Context, testName string, newTestName string, by string) (*models.Test, error) {
+	defer repo.store.lock(ctx)()
+	newTestName = NormalizeKey(newTestName)
+	if newTestName == "" {
+		return nil, newDomainError(ErrValidationFailed, "test", testName, "new test name must not be empty")
+	}
+	storedName, exists := findKey(repo.store.data.tests, testName)
+	if !exists {
+		return nil, newDomainError(ErrNotFound, "test", testName, "")
+	}
+	if newTestName == storedName {
+		return nil, newDomainError(ErrValidationFailed, "test", testName, "new test name must be different")
+	}
+	if other, exists := findKey(repo.store.data.tests, newTestName); exists && other != storedName {
+		return nil, newDomainError(ErrAlreadyExists, "test", newTestName, "")
+	}
+	test := repo.store.data.tests[storedName]
+	test.TestName = newTestName
+	test.UpdatedBy = by
+	delete(repo.store.data.tests, storedName)
+	repo.store.data.tests[newTestName] = test
+	if FoldKey(storedName) != FoldKey(newTestName) {
+		repo.store.recordAlias("tests", test.ID, storedName, newTestName)
+	}
+	repo.store.recordRename("tests", test.ID, storedName, newTestName, by)
+	return copyTest(test), nil
+}
+
+func (repo *MemoryTestsRepository) Delete(ctx context.Context, testName string, by string) error {
+	defer repo.store.lock(ctx)()
+	storedName, exists := findKey(repo.store.data.tests, testName)
+	if !exists {
+		return newDomainError(ErrNotFound, "test", testName, "")
+	}
+	test := repo.store.data.tests[storedName]
+	delete(repo.store.data.tests, storedName)
+	repo.store.deleteAliases("tests", test.ID)
+	repo.store.recordChange("tests", test.ID, storedName, models.ChangeActionDeleted, by)
+	return nil
+}
+
+// validateTestLists rejects the nil lists the Postgres columns are not null
+// for, so memory mode fails the same requests production does.
+func validateTestLists(test *models.Test, testName string) error {
+	if test.References == nil || test.Standards == nil || test.AvailableModifiers == nil {
+		return newDomainError(ErrValidationFailed, "test", testName, "references, standards and availableModifiers cannot be null")
+	}
+	return nil
+}
+
+func copyTest(test models.Test) *models.Test {
+	test.References = copyStrings(test.References)
+	test.Standards = copyStrings(test.Standards)
+	test.AvailableModifiers = copyStrings(test.AvailableModifiers)
+	return &test
+}
+
+// likePattern translates an ILIKE pattern, where % and _ are wildcards and
+// backslash escapes, into an equivalent case-insensitive regular expression.
+func likePattern(pattern string) *regexp.Regexp {
+	var expression strings.Builder
+	expression.WriteString("(?is)^")
+	escaped := false
+	for _, r := range pattern {
+		switch {
+		case escaped:
+			expression.WriteString(regexp.QuoteMeta(string(r)))
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '%':
+			expression.WriteString(".*")
+		case r == '_':
+			expression.WriteString(".")
+		default:
+			expression.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	expression.WriteString("$")
+	return regexp.MustCompile(expression.String())
+}
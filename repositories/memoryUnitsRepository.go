@@ -0,0 +1,149 @@
+package repositories
+
+import (
+	"config/models"
+	"context"
+	"sort"
+)
+
+type MemoryUnitsRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryUnitsRepository(store *MemoryStore) *MemoryUnitsRepository {
+	return &MemoryUnitsRepository{store: store}
+}
+
+func (repo *MemoryUnitsRepository) GetOne(ctx context.Context, fullName string) (*models.Unit, error) {
+	defer repo.store.lock(ctx)()
+	index := repo.indexOf(fullName)
+	if index < 0 {
+		return nil, newDomainError(ErrNotFound, "unit", fullName, "")
+	}
+	unit := repo.store.data.units[index]
+	return &unit, nil
+}
+
+func (repo *MemoryUnitsRepository) GetByID(ctx context.Context, id string) (*models.Unit, error) {
+	defer repo.store.lock(ctx)()
+	for _, unit := range repo.store.data.units {
+		if unit.ID == id {
+			return &unit, nil
+		}
+	}
+	return nil, newDomainError(ErrNotFound, "unit", id, "")
+}
+
+func (repo *MemoryUnitsRepository) GetMany(ctx context.Context) (*[]models.Unit, error) {
+	defer repo.store.lock(ctx)()
+	units := append([]models.Unit(nil), repo.store.data.units...)
+	return &units, nil
+}
+
+func (repo *MemoryUnitsRepository) ForEach(ctx context.Context, fn func(unit *models.Unit) error) error {
+	units, err := repo.GetMany(ctx)
+	if err != nil {
+		return err
+	}
+	sort.SliceStable(*units, func(i, j int) bool {
+		if (*units)[i].UnitType != (*units)[j].UnitType {
+			return (*units)[i].UnitType < (*units)[j].UnitType
+		}
+		return (*units)[i].FullName < (*units)[j].FullName
+	})
+	for i := range *units {
+		if err := fn(&(*units)[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (repo *MemoryUnitsRepository) InsertMany(ctx context.Context, units *[]models.Unit, by string) error {
+	return repo.store.WithinTransaction(ctx, func(ctx context.Context) error {
+		for i := range *units {
+			unit := &(*units)[i]
+			if repo.indexOf(unit.FullName) >= 0 {
+				return newDomainError(ErrAlreadyExists, "unit", unit.FullName, "")
+			}
+			unit.ID = newUUID()
+			unit.CreatedBy = by
+			unit.UpdatedBy = by
+			repo.store.data.units = append(repo.store.data.units, *unit)
+			repo.store.recordChange("units", unit.ID, unit.FullName, models.ChangeActionCreated, by)
+		}
+		return nil
+	})
+}
+
+func (repo *MemoryUnitsRepository) Create(ctx context.Context, unit *models.Unit, by string) error {
+	units := []models.Unit{*unit}
+	if err := repo.InsertMany(ctx, &units, by); err != nil {
+		return err
+	}
+	unit.ID = units[0].ID
+	return nil
+}
+
+func (repo *MemoryUnitsRepository) Update(ctx context.Context, unit *models.Unit, by string) error {
+	defer repo.store.lock(ctx)()
+	index := repo.indexOf(unit.FullName)
+	if index < 0 {
+		return newDomainError(ErrNotFound, "unit", unit.FullName, "")
+	}
+	updated := *unit
+	updated.ID = repo.store.data.units[index].ID
+	updated.CreatedBy = repo.store.data.units[index].CreatedBy
+	updated.UpdatedBy = by
+	repo.store.data.units[index] = updated
+	unit.ID = updated.ID
+	repo.store.recordChange("units", updated.ID, unit.FullName, models.ChangeActionUpdated, by)
+	return nil
+}
+
+func (repo *MemoryUnitsRepository) Modify(ctx context.Context, fullName string, modify func(unit *models.Unit) error, by string) (*models.Unit, error) {
+	defer repo.store.lock(ctx)()
+	index := repo.indexOf(fullName)
+	if index < 0 {
+		return nil, newDomainError(ErrNotFound, "unit", fullName, "")
+	}
+	unit := repo.store.data.units[index]
+	if err := modify(&unit); err != nil {
+		return nil, err
+	}
+	if unit.FullName != fullName {
+		return nil, newDomainError(ErrValidationFailed, "unit", fullName, "fullName cannot be changed")
+	}
+	existing := repo.store.data.units[index]
+	if unit.ID != existing.ID {
+		return nil, newDomainError(ErrValidationFailed, "unit", fullName, "id cannot be changed")
+	}
+	if err := checkAuthorship("unit", fullName, existing.CreatedBy, existing.UpdatedBy, unit.CreatedBy, unit.UpdatedBy); err != nil {
+		return nil, err
+	}
+	unit.UpdatedBy = by
+	repo.store.data.units[index] = unit
+	repo.store.recordChange("units", unit.ID, fullName, models.ChangeActionUpdated, by)
+	return &unit, nil
+}
+
+func (repo *MemoryUnitsRepository) Delete(ctx context.Context, fullName string, by string) error {
+	defer repo.store.lock(ctx)()
+	index := repo.indexOf(fullName)
+	if index < 0 {
+		return newDomainError(ErrNotFound, "unit", fullName, "")
+	}
+	id := repo.store.data.units[index].ID
+	repo.store.data.units = append(repo.store.data.units[:index:index], repo.store.data.units[index+1:]...)
+	repo.store.recordChange("units", id, fullName, models.ChangeActionDeleted, by)
+	return nil
+}
+
+func (repo *MemoryUnitsRepository) indexOf(fullName string) int {
+	for i, unit := range repo.store.data.units {
+		if unit.FullName == fullName {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,209 @@
+package repositories
+
+import (
+	"config/models"
+	"context"
+	"sort"
+	"strconv"
+	"time"
+)
+
+type MemoryWebhooksRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryWebhooksRepository(store *MemoryStore) *MemoryWebhooksRepository {
+	return &MemoryWebhooksRepository{store: store}
+}
+
+func (repo *MemoryWebhooksRepository) GetSubscriptions(ctx context.Context) (*[]models.WebhookSubscription, error) {
+	defer repo.store.lock(ctx)()
+	subscriptions := []models.WebhookSubscription{}
+	for _, subscription := range repo.store.data.subscriptions {
+		subscription.Secret = ""
+		subscription.EventTypes = copyStrings(subscription.EventTypes)
+		subscriptions = append(subscriptions, subscription)
+	}
+	sort.Slice(subscriptions, func(i, j int) bool {
+		return subscriptions[i].SubscriptionID < subscriptions[j].SubscriptionID
+	})
+	return &subscriptions, nil
+}
+
+func (repo *MemoryWebhooksRepository) GetSubscription(ctx context.Context, subscriptionID int64) (*models.WebhookSubscription, error) {
+	defer repo.store.lock(ctx)()
+	subscription, ok := repo.store.data.subscriptions[subscriptionID]
+	if !ok {
+		return nil, newDomainError(ErrNotFound, "webhook subscription", strconv.FormatInt(subscriptionID, 10), "")
+	}
+	subscription.Secret = ""
+	subscription.EventTypes = copyStrings(subscription.EventTypes)
+	return &subscription, nil
+}
+
+func (repo *MemoryWebhooksRepository) CreateSubscription(ctx context.Context, subscription *models.WebhookSubscription, by string) error {
+	defer repo.store.lock(ctx)()
+	repo.store.data.nextSubID++
+	subscription.SubscriptionID = repo.store.data.nextSubID
+	subscription.CreatedBy = by
+	subscription.UpdatedBy = by
+	created := *subscription
+	created.EventTypes = copyStrings(subscription.EventTypes)
+	repo.store.data.subscriptions[created.SubscriptionID] = created
+	return nil
+}
+
+func (repo *MemoryWebhooksRepository) UpdateSubscription(ctx context.Context, subscription *models.WebhookSubscription, by string) error {
+	defer repo.store.lock(ctx)()
+	existing, ok := repo.store.data.subscriptions[subscription.SubscriptionID]
+	if !ok {
+		return newDomainError(ErrNotFound, "webhook subscription", strconv.FormatInt(subscription.SubscriptionID, 10), "")
+	}
+	existing.URL = subscription.URL
+	if subscription.Secret != "" {
+		existing.Secret = subscription.Secret
+	}
+	existing.EventTypes = copyStrings(subscription.EventTypes)
+	existing.IsActive = subscription.IsActive
+	existing.UpdatedBy = by
+	repo.store.data.subscriptions[existing.SubscriptionID] = existing
+	return nil
+}
+
+func (repo *MemoryWebhooksRepository) DeleteSubscription(ctx context.Context, subscriptionID int64) (bool, error) {
+	defer repo.store.lock(ctx)()
+	if _, ok := repo.store.data.subscriptions[subscriptionID]; !ok {
+		return false, nil
+	}
+	delete(repo.store.data.subscriptions, subscriptionID)
+	for deliveryID, delivery := range repo.store.data.deliveries {
+		if delivery.SubscriptionID == subscriptionID {
+			delete(repo.store.data.deliveries, deliveryID)
+		}
+	}
+	return true, nil
+}
+
+func (repo *MemoryWebhooksRepository) GetDeliveries(ctx context.Context, subscriptionID int64, status *string, limit int) (*[]models.WebhookDelivery, error) {
+	defer repo.store.lock(ctx)()
+	deliveries := []models.WebhookDelivery{}
+	for _, delivery := range repo.sortedDeliveries() {
+		if delivery.SubscriptionID == subscriptionID && (status == nil || delivery.Status == *status) {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	sort.Slice(deliveries, func(i, j int) bool {
+		return deliveries[i].DeliveryID > deliveries[j].DeliveryID
+	})
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+	return &deliveries, nil
+}
+
+func (repo *MemoryWebhooksRepository) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) (*[]models.WebhookDelivery, error) {
+	defer repo.store.lock(ctx)()
+	now := time.Now()
+	deliveries := []models.WebhookDelivery{}
+	for _, delivery := range repo.sortedDeliveries() {
+		if len(deliveries) == limit {
+			break
+		}
+		if delivery.Status != models.WebhookDeliveryStatusPending || delivery.NextAttemptAt.After(now) {
+			continue
+		}
+		delivery.NextAttemptAt = now.Add(lease)
+		repo.store.data.deliveries[delivery.DeliveryID] = delivery
+		subscription := repo.store.data.subscriptions[delivery.SubscriptionID]
+		delivery.URL = subscription.URL
+		delivery.Secret = subscription.Secret
+		delivery.Payload = repo.store.data.events[delivery.EventID].payload
+		deliveries = append(deliveries, delivery)
+	}
+	return &deliveries, nil
+}
+
+func (repo *MemoryWebhooksRepository) MarkDelivered(ctx context.Context, deliveryID int64, lease time.Time) error {
+	defer repo.store.lock(ctx)()
+	delivery, ok := repo.store.data.deliveries[deliveryID]
+	if !ok || delivery.Status != models.WebhookDeliveryStatusPending || !delivery.NextAttemptAt.Equal(lease) {
+		return checkDeliveryLease(0, deliveryID)
+	}
+	deliveredAt := time.Now()
+	delivery.Status = models.WebhookDeliveryStatusDelivered
+	delivery.Attempts++
+	delivery.LastError = ""
+	delivery.DeliveredAt = &deliveredAt
+	repo.store.data.deliveries[deliveryID] = delivery
+	return nil
+}
+
+func (repo *MemoryWebhooksRepository) MarkFailed(ctx context.Context, deliveryID int64, lease time.Time, lastError string, nextAttemptAt *time.Time) error {
+	defer repo.store.lock(ctx)()
+	delivery, ok := repo.store.data.deliveries[deliveryID]
+	if !ok || delivery.Status != models.WebhookDeliveryStatusPending || !delivery.NextAttemptAt.Equal(lease) {
+		return checkDeliveryLease(0, deliveryID)
+	}
+	delivery.Attempts++
+	delivery.LastError = lastError
+	if nextAttemptAt == nil {
+		delivery.Status = models.WebhookDeliveryStatusDead
+	} else {
+		delivery.Status = models.WebhookDeliveryStatusPending
+		delivery.NextAttemptAt = *nextAttemptAt
+	}
+	repo.store.data.deliveries[deliveryID] = delivery
+	return nil
+}
+
+func (repo *MemoryWebhooksRepository) Replay(ctx context.Context, subscriptionID int64, deliveryID *int64, status *string) (int64, error) {
+	defer repo.store.lock(ctx)()
+	var replayed int64
+	for _, delivery := range repo.store.data.deliveries {
+		if delivery.SubscriptionID != subscriptionID || (deliveryID != nil && delivery.DeliveryID != *deliveryID) || (status != nil && delivery.Status != *status) {
+			continue
+		}
+		delivery.Status = models.WebhookDeliveryStatusPending
+		delivery.Attempts = 0
+		delivery.NextAttemptAt = time.Now()
+		delivery.DeliveredAt = nil
+		repo.store.data.deliveries[delivery.DeliveryID] = delivery
+		replayed++
+	}
+	return replayed, nil
+}
+
+func (repo *MemoryWebhooksRepository) PruneDeliveries(ctx context.Context, before time.Time) (int64, error) {
+	defer repo.store.lock(ctx)()
+	var pruned int64
+	referenced := make(map[int64]bool)
+	for deliveryID, delivery := range repo.store.data.deliveries {
+		lastTouched := delivery.NextAttemptAt
+		if delivery.DeliveredAt != nil {
+			lastTouched = *delivery.DeliveredAt
+		}
+		if delivery.Status != models.WebhookDeliveryStatusPending && lastTouched.Before(before) {
+			delete(repo.store.data.deliveries, deliveryID)
+			pruned++
+			continue
+		}
+		referenced[delivery.EventID] = true
+	}
+	for eventID, event := range repo.store.data.events {
+		if !referenced[eventID] && event.createdAt.Before(before) {
+			delete(repo.store.data.events, eventID)
+		}
+	}
+	return pruned, nil
+}
+
+func (repo *MemoryWebhooksRepository) sortedDeliveries() []models.WebhookDelivery {
+	deliveries := make([]models.WebhookDelivery, 0, len(repo.store.data.deliveries))
+	for _, delivery := range repo.store.data.deliveries {
+		deliveries = append(deliveries, delivery)
+	}
+	sort.Slice(deliveries, func(i, j int) bool {
+		return deliveries[i].DeliveryID < deliveries[j].DeliveryID
+	})
+	return deliveries
+}
@@ -1,34 +1,61 @@
 package repositories
 
 import (
+	"config/caching"
 	"config/models"
 	"context"
-	"fmt"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-type ProductsRepository struct {
-	conn *pgx.Conn
+type PostgresProductsRepository struct {
+	conn  *pgxpool.Pool
+	cache masterDataCache
 }
 
-func NewProductsRepository(conn *pgx.Conn) *ProductsRepository {
-	return &ProductsRepository{conn: conn}
+func NewPostgresProductsRepository(conn *pgxpool.Pool, cacheService caching.CacheService) *PostgresProductsRepository {
+	return &PostgresProductsRepository{conn: conn, cache: masterDataCache{cacheService}}
 }
 
-func (repo *ProductsRepository) GetOne(productCode string) (*models.Product, error) {
-	sql := "select product_code, description from products where product_code = $1"
+func (repo *PostgresProductsRepository) GetOne(ctx context.Context, productCode string) (_ *models.Product, err error) {
+	ctx, end := startOperation(ctx, "ProductsRepository.GetOne")
+	defer end(&err)
 	var product models.Product
-	if err := repo.conn.QueryRow(context.Background(), sql, productCode).Scan(&product.ProductCode, &product.Description); err != nil {
-		return nil, err
+	if repo.cache.get(ctx, "PRODUCTS|ONE|"+FoldKey(productCode), &product) {
+		return &product, nil
+	}
+	sql := "select id::text, product_code, description, coalesce(created_by, ''), coalesce(updated_by, '') from products where " + keyMatch("products", "product_code")
+	if err := queryable(ctx, repo.conn).QueryRow(ctx, sql, NormalizeKey(productCode)).Scan(&product.ID, &product.ProductCode, &product.Description, &product.CreatedBy, &product.UpdatedBy); err != nil {
+		return nil, translateError(err, "product", productCode)
 	}
+	repo.cache.set(ctx, "PRODUCTS|ONE|"+FoldKey(productCode), product)
 	return &product, nil
 }
 
-func (repo *ProductsRepository) GetMany() (*[]models.Product, error) {
-	sql := "select product_code, description from products"
+func (repo *PostgresProductsRepository) GetByID(ctx context.Context, id string) (_ *models.Product, err error) {
+	ctx, end := startOperation(ctx, "ProductsRepository.GetByID")
+	defer end(&err)
+	if !isUUID(id) {
+		return nil, newDomainError(ErrNotFound, "product", id, "")
+	}
+	var product models.Product
+	sql := "select id::text, product_code, description, coalesce(created_by, ''), coalesce(updated_by, '') from products where id = $1"
+	if err := queryable(ctx, repo.conn).QueryRow(ctx, sql, id).Scan(&product.ID, &product.ProductCode, &product.Description, &product.CreatedBy, &product.UpdatedBy); err != nil {
+		return nil, translateError(err, "product", id)
+	}
+	return &product, nil
+}
+
+func (repo *PostgresProductsRepository) GetMany(ctx context.Context) (_ *[]models.Product, err error) {
+	ctx, end := startOperation(ctx, "ProductsRepository.GetMany")
+	defer end(&err)
 	var products []models.Product
-	rows, err := repo.conn.Query(context.Background(), sql)
+	if repo.cache.get(ctx, "PRODUCTS|ALL", &products) {
+		return &products, nil
+	}
+	sql := "select id::text, product_code, description, coalesce(created_by, ''), coalesce(updated_by, '') from products"
+	rows, err := queryable(ctx, repo.conn).Query(ctx, sql)
 	if err != nil {
 		return nil, err
 	}
@@ -38,42 +65,185 @@ func (repo *ProductsRepository) GetMany() (*[]models.Product, error) {
 	}
 	for rows.Next() {
 		var product models.Product
-		if err := rows.Scan(&product.ProductCode, &product.Description); err != nil {
+		if err := rows.Scan(&product.ID, &product.ProductCode, &product.Description, &product.CreatedBy, &product.UpdatedBy); err != nil {
 			return nil, err
 		}
 		products = append(products, product)
 	}
+	repo.cache.set(ctx, "PRODUCTS|ALL", products)
 	return &products, nil
 }
 
-func (repo *ProductsRepository) Create(product *models.Product) error {
+func (repo *PostgresProductsRepository) ForEach(ctx context.Context, fn func(product *models.Product) error) (err error) {
+	ctx, end := startStreamingOperation(ctx, "ProductsRepository.ForEach")
+	defer end(&err)
+	sql := `select id::text, product_code, description, coalesce(created_by, ''), coalesce(updated_by, '') from products order by lower(product_code) collate "C", product_code collate "C"`
+	return withoutStatementTimeout(ctx, repo.conn, func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, sql)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var product models.Product
+			if err := rows.Scan(&product.ID, &product.ProductCode, &product.Description, &product.CreatedBy, &product.UpdatedBy); err != nil {
+				return err
+			}
+			if err := fn(&product); err != nil {
+				return err
+			}
+		}
+		return rows.Err()
+	})
+}
+
+func (repo *PostgresProductsRepository) Create(ctx context.Context, product *models.Product, by string) (err error) {
+	ctx, end := startOperation(ctx, "ProductsRepository.Create")
+	defer end(&err)
 	sql := `
-insert into products (product_code, description)
-values ($1, $2)
+insert into products (product_code, description, created_by, updated_by)
+values ($1, $2, $3, $3)
+returning id::text
 	`
-	tag, err := repo.conn.Exec(context.Background(), sql, product.ProductCode, product.Description)
-	if tag.RowsAffected() != 1 {
-		return fmt.Errorf("%v rows affected by insert (expected 1)", tag.RowsAffected())
+	product.ProductCode = NormalizeKey(product.ProductCode)
+	err = inTransaction(ctx, repo.conn, func(tx pgx.Tx) error {
+		if err := tx.QueryRow(ctx, sql, product.ProductCode, product.Description, by).Scan(&product.ID); err != nil {
+			return err
+		}
+		return recordChange(ctx, tx, "products", product.ID, product.ProductCode, models.ChangeActionCreated, by)
+	})
+	if err != nil {
+		return translateError(err, "product", product.ProductCode)
 	}
-	return err
+	repo.cache.invalidate(ctx, "products", product.ProductCode)
+	return nil
 }
 
-func (repo *ProductsRepository) Update(product *models.Product) error {
+func (repo *PostgresProductsRepository) Update(ctx context.Context, product *models.Product, by string) (err error) {
+	ctx, end := startOperation(ctx, "ProductsRepository.Update")
+	defer end(&err)
 	sql := `
 update products
-set description = $2
-where product_code = $1
+set description = $2, updated_by = $3
+where ` + keyMatch("products", "product_code") + `
+returning id::text, product_code
 	`
-	tag, err := repo.conn.Exec(context.Background(), sql, product.ProductCode, product.Description)
-	if tag.RowsAffected() != 1 {
-		return fmt.Errorf("%v rows affected by update (expected 1)", tag.RowsAffected())
+	err = inTransaction(ctx, repo.conn, func(tx pgx.Tx) error {
+		if err := tx.QueryRow(ctx, sql, NormalizeKey(product.ProductCode), product.Description, by).Scan(&product.ID, &product.ProductCode); err != nil {
+			return err
+		}
+		return recordChange(ctx, tx, "products", product.ID, product.ProductCode, models.ChangeActionUpdated, by)
+	})
+	if err != nil {
+		return translateError(err, "product", product.ProductCode)
 	}
-	return err
+	repo.cache.invalidate(ctx, "products", product.ProductCode)
+	return nil
 }
 
-func (repo *ProductsRepository) Migrate() error {
+func (repo *PostgresProductsRepository) Modify(ctx context.Context, productCode string, modify func(product *models.Product) error, by string) (_ *models.Product, err error) {
+	ctx, end := startOperation(ctx, "ProductsRepository.Modify")
+	defer end(&err)
+	var product models.Product
+	err = inTransaction(ctx, repo.conn, func(tx pgx.Tx) error {
+		sql := "select id::text, product_code, description, coalesce(created_by, ''), coalesce(updated_by, '') from products where " + keyMatch("products", "product_code") + " for update"
+		if err := tx.QueryRow(ctx, sql, NormalizeKey(productCode)).Scan(&product.ID, &product.ProductCode, &product.Description, &product.CreatedBy, &product.UpdatedBy); err != nil {
+			return err
+		}
+		id, storedCode, createdBy, updatedBy := product.ID, product.ProductCode, product.CreatedBy, product.UpdatedBy
+		if err := modify(&product); err != nil {
+			return err
+		}
+		if product.ProductCode != storedCode {
+			return newDomainError(ErrValidationFailed, "product", storedCode, "productCode cannot be changed")
+		}
+		if product.ID != id {
+			return newDomainError(ErrValidationFailed, "product", storedCode, "id cannot be changed")
+		}
+		if err := checkAuthorship("product", storedCode, createdBy, updatedBy, product.CreatedBy, product.UpdatedBy); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, "update products set description = $2, updated_by = $3 where id = $1", id, product.Description, by); err != nil {
+			return err
+		}
+		product.UpdatedBy = by
+		return recordChange(ctx, tx, "products", id, storedCode, models.ChangeActionUpdated, by)
+	})
+	if err != nil {
+		return nil, translateError(err, "product", productCode)
+	}
+	repo.cache.invalidate(ctx, "products", product.ProductCode)
+	return &product, nil
+}
+
+func (repo *PostgresProductsRepository) Rename(ctx context.Context, productCode string, newProductCode string, by string) (_ *models.Product, err error) {
+	ctx, end := startOperation(ctx, "ProductsRepository.Rename")
+	defer end(&err)
+	newProductCode = NormalizeKey(newProductCode)
+	if newProductCode == "" {
+		return nil, newDomainError(ErrValidationFailed, "product", productCode, "new product code must not be empty")
+	}
+	var product models.Product
+	var oldProductCode string
+	err = inTransaction(ctx, repo.conn, func(tx pgx.Tx) error {
+		sql := `
+with old as (
+	select id, product_code from products where ` + keyMatch("products", "product_code") + ` for update
+)
+update products p
+set product_code = $2, updated_by = $3
+from old
+where p.id = old.id
+returning old.product_code, p.id::text, p.product_code, p.description, coalesce(p.created_by, ''), coalesce(p.updated_by, '')
+		`
+		if err := tx.QueryRow(ctx, sql, NormalizeKey(productCode), newProductCode, by).Scan(&oldProductCode, &product.ID, &product.ProductCode, &product.Description, &product.CreatedBy, &product.UpdatedBy); err != nil {
+			if err == pgx.ErrNoRows {
+				return newDomainError(ErrNotFound, "product", productCode, "")
+			}
+			return err
+		}
+		if oldProductCode == newProductCode {
+			return newDomainError(ErrValidationFailed, "product", productCode, "new product code must be different")
+		}
+		// A change of case only needs no alias: lookups already ignore case.
+		if FoldKey(oldProductCode) != FoldKey(newProductCode) {
+			if err := recordAlias(ctx, tx, "products", product.ID, oldProductCode, newProductCode, by); err != nil {
+				return err
+			}
+		}
+		return recordRename(ctx, tx, "products", product.ID, oldProductCode, newProductCode, by)
+	})
+	if err != nil {
+		return nil, translateError(err, "product", newProductCode)
+	}
+	repo.cache.invalidate(ctx, "products", oldProductCode)
+	repo.cache.invalidate(ctx, "products", newProductCode)
+	return &product, nil
+}
+
+func (repo *PostgresProductsRepository) Delete(ctx context.Context, productCode string, by string) (err error) {
+	ctx, end := startOperation(ctx, "ProductsRepository.Delete")
+	defer end(&err)
+	err = inTransaction(ctx, repo.conn, func(tx pgx.Tx) error {
+		var id, storedCode string
+		if err := tx.QueryRow(ctx, "delete from products where "+keyMatch("products", "product_code")+" returning id::text, product_code", NormalizeKey(productCode)).Scan(&id, &storedCode); err != nil {
+			return err
+		}
+		if err := deleteAliases(ctx, tx, "products", id); err != nil {
+			return err
+		}
+		return recordChange(ctx, tx, "products", id, storedCode, models.ChangeActionDeleted, by)
+	})
+	if err != nil {
+		return translateError(err, "product", productCode)
+	}
+	repo.cache.invalidate(ctx, "products", productCode)
+	return nil
+}
+
+func (repo *PostgresProductsRepository) Migrate(ctx context.Context) error {
 	var currentVersion int
-	row := repo.conn.QueryRow(context.Background(), "select current_version from table_versions where table_name = 'products'")
+	row := repo.conn.QueryRow(ctx, "select current_version from table_versions where table_name = 'products'")
 	err := row.Scan(&currentVersion)
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -83,7 +253,7 @@ func (repo *ProductsRepository) Migrate() error {
 		}
 	}
 	if currentVersion < 1 {
-		_, err = repo.conn.Exec(context.Background(), `
+		_, err = repo.conn.Exec(ctx, `
 create table products (
 	product_code text primary key,
 	description text not null
@@ -92,7 +262,7 @@ create table products (
 		if err != nil {
 			return err
 		}
-		_, err = repo.conn.Exec(context.Background(), `
+		_, err = repo.conn.Exec(ctx, `
 insert into table_versions (table_name, current_version)
 values ('products', 1)
 		`)
@@ -100,5 +270,50 @@ values ('products', 1)
 			return err
 		}
 	}
+	if currentVersion < 2 {
+		_, err = repo.conn.Exec(ctx, `
+alter table products
+	add column created_by text,
+	add column updated_by text
+		`)
+		if err != nil {
+			return err
+		}
+		_, err = repo.conn.Exec(ctx, `
+update table_versions set current_version = 2 where table_name = 'products'
+		`)
+		if err != nil {
+			return err
+		}
+	}
+	if currentVersion < 3 {
+		_, err = repo.conn.Exec(ctx, `
+alter table products
+	add column id uuid not null default gen_random_uuid();
+create unique index products_id on products (id);
+update change_log c set entity_id = p.id from products p where c.entity_type = 'products' and c.entity_key = p.product_code and c.entity_id is null
+		`)
+		if err != nil {
+			return err
+		}
+		_, err = repo.conn.Exec(ctx, `
+update table_versions set current_version = 3 where table_name = 'products'
+		`)
+		if err != nil {
+			return err
+		}
+	}
+	if currentVersion < 4 {
+		err = pgx.BeginFunc(ctx, repo.conn, func(tx pgx.Tx) error {
+			if err := normalizeKeyColumn(ctx, tx, "products", "product_code"); err != nil {
+				return err
+			}
+			_, err := tx.Exec(ctx, "update table_versions set current_version = 4 where table_name = 'products'")
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
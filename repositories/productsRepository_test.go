@@ -0,0 +1,66 @@
+package repositories
+
+import (
+	"config/models"
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func TestProductsForEachOutlivesTheStatementTimeout(t *testing.T) {
+	ctx := context.Background()
+	setup := newTestPool(t)
+	config, err := pgxpool.ParseConfig(os.Getenv("TEST_DATABASE_URL"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.ConnConfig.RuntimeParams["statement_timeout"] = "300"
+	pool, err := pgxpool.NewWithConfig(ctx, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(pool.Close)
+
+	// Enough rows that the server blocks on a full socket while the consumer
+	// stalls, which is when a statement timeout would cancel the export.
+	_, err = setup.Exec(ctx, `
+insert into products (product_code, description)
+select 'STREAM-' || n, repeat('x', 1000) from generate_series(1, 20000) n`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { setup.Exec(ctx, `delete from products where product_code like 'STREAM-%'`) })
+
+	repo := NewPostgresProductsRepository(pool, nil)
+	transactor := NewPostgresTransactor(pool)
+	count := 0
+	err = transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		err := repo.ForEach(ctx, func(product *models.Product) error {
+			if count == 0 {
+				time.Sleep(time.Second)
+			}
+			count++
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		var timeout string
+		if err := queryable(ctx, pool).QueryRow(ctx, "show statement_timeout").Scan(&timeout); err != nil {
+			return err
+		}
+		if timeout != "300ms" {
+			t.Errorf("expected the enclosing transaction to get its statement timeout back, got %v", timeout)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("expected a slow consumer to finish the export, got %v", err)
+	}
+	if count < 20000 {
+		t.Fatalf("expected every product, got %v", count)
+	}
+}
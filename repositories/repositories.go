@@ -0,0 +1,67 @@
+package repositories
+
+import (
+	"config/caching"
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Repositories struct {
+	Transactor     Transactor
+	TableVersions  TableVersionsRepository
+	Products       ProductsRepository
+	Tests          TestsRepository
+	Units          UnitsRepository
+	ConfigSettings ConfigSettingsRepository
+	Aliases        AliasesRepository
+	ChangeLog      ChangeLogRepository
+	Webhooks       WebhooksRepository
+}
+
+func NewPostgresRepositories(conn *pgxpool.Pool, cacheService caching.CacheService) *Repositories {
+	return &Repositories{
+		Transactor:     NewPostgresTransactor(conn),
+		TableVersions:  NewPostgresTableVersionsRepository(conn),
+		Products:       NewPostgresProductsRepository(conn, cacheService),
+		Tests:          NewPostgresTestsRepository(conn, cacheService),
+		Units:          NewPostgresUnitsRepository(conn, cacheService),
+		ConfigSettings: NewPostgresConfigSettingsRepository(conn, cacheService),
+		Aliases:        NewPostgresAliasesRepository(conn),
+		ChangeLog:      NewPostgresChangeLogRepository(conn),
+		Webhooks:       NewPostgresWebhooksRepository(conn),
+	}
+}
+
+func NewMemoryRepositories(store *MemoryStore) *Repositories {
+	return &Repositories{
+		Transactor:     store,
+		TableVersions:  NewMemoryTableVersionsRepository(),
+		Products:       NewMemoryProductsRepository(store),
+		Tests:          NewMemoryTestsRepository(store),
+		Units:          NewMemoryUnitsRepository(store),
+		ConfigSettings: NewMemoryConfigSettingsRepository(store),
+		Aliases:        NewMemoryAliasesRepository(store),
+		ChangeLog:      NewMemoryChangeLogRepository(store),
+		Webhooks:       NewMemoryWebhooksRepository(store),
+	}
+}
+
+func MigratePostgres(ctx context.Context, conn *pgxpool.Pool) error {
+	migrations := []func(context.Context) error{
+		NewPostgresTableVersionsRepository(conn).Migrate,
+		NewPostgresChangeLogRepository(conn).Migrate,
+		NewPostgresWebhooksRepository(conn).Migrate,
+		NewPostgresConfigSettingsRepository(conn, nil).Migrate,
+		NewPostgresUnitsRepository(conn, nil).Migrate,
+		NewPostgresProductsRepository(conn, nil).Migrate,
+		NewPostgresTestsRepository(conn, nil).Migrate,
+		NewPostgresAliasesRepository(conn).Migrate,
+	}
+	for _, migrate := range migrations {
+		if err := migrate(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -4,20 +4,42 @@ import (
 	"context"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/rs/zerolog/log"
 )
 
-type TableVersionsRepository struct {
-	conn *pgx.Conn
+type PostgresTableVersionsRepository struct {
+	conn *pgxpool.Pool
 }
 
-func NewTableVersionsRepository(conn *pgx.Conn) *TableVersionsRepository {
-	return &TableVersionsRepository{conn: conn}
+func NewPostgresTableVersionsRepository(conn *pgxpool.Pool) *PostgresTableVersionsRepository {
+	return &PostgresTableVersionsRepository{conn: conn}
 }
 
-func (repo *TableVersionsRepository) Migrate() error {
+func (repo *PostgresTableVersionsRepository) GetAll(ctx context.Context) (map[string]int, error) {
+	rows, err := queryable(ctx, repo.conn).Query(ctx, "select table_name, current_version from table_versions")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	versions := make(map[string]int)
+	for rows.Next() {
+		var tableName string
+		var currentVersion int
+		if err := rows.Scan(&tableName, &currentVersion); err != nil {
+			return nil, err
+		}
+		versions[tableName] = currentVersion
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return versions, nil
+}
+
+func (repo *PostgresTableVersionsRepository) Migrate(ctx context.Context) error {
 	var exists bool
-	err := repo.conn.QueryRow(context.Background(), "select exists (select 1 from pg_tables where tablename = 'table_versions')").Scan(&exists)
+	err := repo.conn.QueryRow(ctx, "select exists (select 1 from pg_tables where tablename = 'table_versions')").Scan(&exists)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			log.Info().Msg("table_versions not found")
@@ -29,7 +51,7 @@ func (repo *TableVersionsRepository) Migrate() error {
 	var currentVersion int
 	if exists {
 		log.Info().Msg("table_versions found")
-		err = repo.conn.QueryRow(context.Background(), "select current_version from table_versions where table_name = 'table_versions'").Scan(&currentVersion)
+		err = repo.conn.QueryRow(ctx, "select current_version from table_versions where table_name = 'table_versions'").Scan(&currentVersion)
 		if err != nil {
 			if err == pgx.ErrNoRows {
 				currentVersion = 0
@@ -42,7 +64,7 @@ func (repo *TableVersionsRepository) Migrate() error {
 	}
 	if currentVersion < 1 {
 		log.Info().Msg("creating table table_versions")
-		_, err = repo.conn.Exec(context.Background(), `
+		_, err = repo.conn.Exec(ctx, `
 create table table_versions (
 	table_name text primary key,
 	current_version int not null
@@ -51,7 +73,7 @@ create table table_versions (
 		if err != nil {
 			return err
 		}
-		_, err = repo.conn.Exec(context.Background(), `
+		_, err = repo.conn.Exec(ctx, `
 insert into table_versions (table_name, current_version)
 values ('table_versions', 1)
 		`)
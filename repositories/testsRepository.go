@@ -1,38 +1,75 @@
 package repositories
 
 import (
+	"config/caching"
 	"config/models"
 	"context"
-	"fmt"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-type TestsRepository struct {
-	conn *pgx.Conn
+type PostgresTestsRepository struct {
+	conn  *pgxpool.Pool
+	cache masterDataCache
 }
 
-func NewTestsRepository(conn *pgx.Conn) *TestsRepository {
-	return &TestsRepository{conn: conn}
+func NewPostgresTestsRepository(conn *pgxpool.Pool, cacheService caching.CacheService) *PostgresTestsRepository {
+	return &PostgresTestsRepository{conn: conn, cache: masterDataCache{cacheService}}
 }
 
-func (repo *TestsRepository) GetOne(testName string) (*models.Test, error) {
-	sql := `select test_name, unit_type, "references", standards, available_modifiers from tests where test_name = $1`
+func (repo *PostgresTestsRepository) GetOne(ctx context.Context, testName string) (_ *models.Test, err error) {
+	ctx, end := startOperation(ctx, "TestsRepository.GetOne")
+	defer end(&err)
 	var test models.Test
-	if err := repo.conn.QueryRow(context.Background(), sql, testName).Scan(&test.TestName, &test.UnitType, &test.References, &test.Standards, &test.AvailableModifiers); err != nil {
-		return nil, err
+	if repo.cache.get(ctx, "TESTS|ONE|"+FoldKey(testName), &test) {
+		return &test, nil
+	}
+	sql := `
+select id::text, test_name, unit_type, "references", standards, available_modifiers, coalesce(created_by, ''), coalesce(updated_by, '')
+from tests
+where ` + keyMatch("tests", "test_name") + `
+	`
+	if err := queryable(ctx, repo.conn).QueryRow(ctx, sql, NormalizeKey(testName)).Scan(&test.ID, &test.TestName, &test.UnitType, &test.References, &test.Standards, &test.AvailableModifiers, &test.CreatedBy, &test.UpdatedBy); err != nil {
+		return nil, translateError(err, "test", testName)
+	}
+	repo.cache.set(ctx, "TESTS|ONE|"+FoldKey(testName), test)
+	return &test, nil
+}
+
+func (repo *PostgresTestsRepository) GetByID(ctx context.Context, id string) (_ *models.Test, err error) {
+	ctx, end := startOperation(ctx, "TestsRepository.GetByID")
+	defer end(&err)
+	if !isUUID(id) {
+		return nil, newDomainError(ErrNotFound, "test", id, "")
+	}
+	sql := `
+select id::text, test_name, unit_type, "references", standards, available_modifiers, coalesce(created_by, ''), coalesce(updated_by, '')
+from tests
+where id = $1
+	`
+	var test models.Test
+	if err := queryable(ctx, repo.conn).QueryRow(ctx, sql, id).Scan(&test.ID, &test.TestName, &test.UnitType, &test.References, &test.Standards, &test.AvailableModifiers, &test.CreatedBy, &test.UpdatedBy); err != nil {
+		return nil, translateError(err, "test", id)
 	}
 	return &test, nil
 }
 
-func (repo *TestsRepository) GetMany(pageSize int, lastKey *string, criteria *models.TestCriteria) (*[]models.Test, error) {
+func (repo *PostgresTestsRepository) GetMany(ctx context.Context, pageSize int, lastKey *string, criteria *models.TestCriteria) (_ *[]models.Test, err error) {
+	ctx, end := startOperation(ctx, "TestsRepository.GetMany")
+	defer end(&err)
+	if pageSize < 0 {
+		return nil, newDomainError(ErrValidationFailed, "test", "", "pageSize must not be negative")
+	}
+	// Pages are ordered case-insensitively by code point rather than by the
+	// database collation, so the memory repository can page identically.
 	sql := `
- SELECT test_name, unit_type, "references", standards, available_modifiers
+ SELECT id::text, test_name, unit_type, "references", standards, available_modifiers, coalesce(created_by, ''), coalesce(updated_by, '')
  FROM tests
- WHERE ($2::text is null OR test_name > $2::text)
+ WHERE ($2::text is null OR (lower(test_name) COLLATE "C", test_name COLLATE "C") > (lower($2::text) COLLATE "C", $2::text COLLATE "C"))
     AND ($3::text is null OR test_name ILIKE $3::text)
     AND ($4::text[] is null OR unit_type = any($4::text[]))
- ORDER BY test_name
+ ORDER BY lower(test_name) COLLATE "C", test_name COLLATE "C"
  LIMIT $1;
 	`
 	namePattern := criteria.NamePattern
@@ -40,7 +77,7 @@ func (repo *TestsRepository) GetMany(pageSize int, lastKey *string, criteria *mo
 		newPattern := "%" + (*namePattern) + "%"
 		namePattern = &newPattern
 	}
-	rows, err := repo.conn.Query(context.Background(), sql, pageSize, lastKey, namePattern, criteria.UnitTypeValues)
+	rows, err := queryable(ctx, repo.conn).Query(ctx, sql, pageSize, lastKey, namePattern, criteria.UnitTypeValues)
 	if err != nil {
 		return nil, err
 	}
@@ -51,7 +88,7 @@ func (repo *TestsRepository) GetMany(pageSize int, lastKey *string, criteria *mo
 	var tests []models.Test
 	for rows.Next() {
 		var test models.Test
-		if err := rows.Scan(&test.TestName, &test.UnitType, &test.References, &test.Standards, &test.AvailableModifiers); err != nil {
+		if err := rows.Scan(&test.ID, &test.TestName, &test.UnitType, &test.References, &test.Standards, &test.AvailableModifiers, &test.CreatedBy, &test.UpdatedBy); err != nil {
 			return nil, err
 		}
 		tests = append(tests, test)
@@ -59,37 +96,197 @@ func (repo *TestsRepository) GetMany(pageSize int, lastKey *string, criteria *mo
 	return &tests, nil
 }
 
-func (repo *TestsRepository) Create(test *models.Test) error {
+func (repo *PostgresTestsRepository) ForEach(ctx context.Context, criteria *models.TestCriteria, fn func(test *models.Test) error) (err error) {
+	ctx, end := startStreamingOperation(ctx, "TestsRepository.ForEach")
+	defer end(&err)
 	sql := `
-insert into tests (test_name, unit_type, "references", standards, available_modifiers)
-values ($1, $2, $3, $4, $5)	
+ SELECT id::text, test_name, unit_type, "references", standards, available_modifiers, coalesce(created_by, ''), coalesce(updated_by, '')
+ FROM tests
+ WHERE ($1::text is null OR test_name ILIKE $1::text)
+    AND ($2::text[] is null OR unit_type = any($2::text[]))
+ ORDER BY lower(test_name) COLLATE "C", test_name COLLATE "C"
 	`
-	tag, err := repo.conn.Exec(context.Background(), sql, test.TestName, test.UnitType, test.References, test.Standards, test.AvailableModifiers)
-	if err != nil {
-		return err
+	namePattern := criteria.NamePattern
+	if namePattern != nil {
+		newPattern := "%" + (*namePattern) + "%"
+		namePattern = &newPattern
 	}
-	if tag.RowsAffected() != 1 {
-		return fmt.Errorf("%v rows affected by insert (expected 1)", tag.RowsAffected())
+	return withoutStatementTimeout(ctx, repo.conn, func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, sql, namePattern, criteria.UnitTypeValues)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var test models.Test
+			if err := rows.Scan(&test.ID, &test.TestName, &test.UnitType, &test.References, &test.Standards, &test.AvailableModifiers, &test.CreatedBy, &test.UpdatedBy); err != nil {
+				return err
+			}
+			if err := fn(&test); err != nil {
+				return err
+			}
+		}
+		return rows.Err()
+	})
+}
+
+func (repo *PostgresTestsRepository) Create(ctx context.Context, test *models.Test, by string) (err error) {
+	ctx, end := startOperation(ctx, "TestsRepository.Create")
+	defer end(&err)
+	sql := `
+insert into tests (test_name, unit_type, "references", standards, available_modifiers, created_by, updated_by)
+values ($1, $2, $3, $4, $5, $6, $6)
+returning id::text
+	`
+	test.TestName = NormalizeKey(test.TestName)
+	err = inTransaction(ctx, repo.conn, func(tx pgx.Tx) error {
+		if err := tx.QueryRow(ctx, sql, test.TestName, test.UnitType, test.References, test.Standards, test.AvailableModifiers, by).Scan(&test.ID); err != nil {
+			return err
+		}
+		return recordChange(ctx, tx, "tests", test.ID, test.TestName, models.ChangeActionCreated, by)
+	})
+	if err != nil {
+		return translateError(err, "test", test.TestName)
 	}
-	return err
+	repo.cache.invalidate(ctx, "tests", test.TestName)
+	return nil
 }
 
-func (repo *TestsRepository) Update(test *models.Test) error {
+func (repo *PostgresTestsRepository) Update(ctx context.Context, test *models.Test, by string) (err error) {
+	ctx, end := startOperation(ctx, "TestsRepository.Update")
+	defer end(&err)
 	sql := `
 update tests
-set unit_type = $2, "references" = $3, standards = $4, available_modifiers = $5
-where test_name = $1
+set unit_type = $2, "references" = $3, standards = $4, available_modifiers = $5, updated_by = $6
+where ` + keyMatch("tests", "test_name") + `
+returning id::text, test_name
 	`
-	tag, err := repo.conn.Exec(context.Background(), sql, test.TestName, test.UnitType, test.References, test.Standards, test.AvailableModifiers)
-	if tag.RowsAffected() != 1 {
-		return fmt.Errorf("%v rows affected by update (expected 1)", tag.RowsAffected())
+	err = inTransaction(ctx, repo.conn, func(tx pgx.Tx) error {
+		if err := tx.QueryRow(ctx, sql, NormalizeKey(test.TestName), test.UnitType, test.References, test.Standards, test.AvailableModifiers, by).Scan(&test.ID, &test.TestName); err != nil {
+			return err
+		}
+		return recordChange(ctx, tx, "tests", test.ID, test.TestName, models.ChangeActionUpdated, by)
+	})
+	if err != nil {
+		return translateError(err, "test", test.TestName)
+	}
+	repo.cache.invalidate(ctx, "tests", test.TestName)
+	return nil
+}
+
+func (repo *PostgresTestsRepository) Modify(ctx context.Context, testName string, modify func(test *models.Test) error, by string) (_ *models.Test, err error) {
+	ctx, end := startOperation(ctx, "TestsRepository.Modify")
+	defer end(&err)
+	var test models.Test
+	err = inTransaction(ctx, repo.conn, func(tx pgx.Tx) error {
+		sql := `
+select id::text, test_name, unit_type, "references", standards, available_modifiers, coalesce(created_by, ''), coalesce(updated_by, '')
+from tests
+where ` + keyMatch("tests", "test_name") + `
+for update
+		`
+		if err := tx.QueryRow(ctx, sql, NormalizeKey(testName)).Scan(&test.ID, &test.TestName, &test.UnitType, &test.References, &test.Standards, &test.AvailableModifiers, &test.CreatedBy, &test.UpdatedBy); err != nil {
+			return err
+		}
+		id, storedName, createdBy, updatedBy := test.ID, test.TestName, test.CreatedBy, test.UpdatedBy
+		if err := modify(&test); err != nil {
+			return err
+		}
+		if test.TestName != storedName {
+			return newDomainError(ErrValidationFailed, "test", storedName, "testName cannot be changed")
+		}
+		if test.ID != id {
+			return newDomainError(ErrValidationFailed, "test", storedName, "id cannot be changed")
+		}
+		if err := checkAuthorship("test", storedName, createdBy, updatedBy, test.CreatedBy, test.UpdatedBy); err != nil {
+			return err
+		}
+		sql = `
+update tests
+set unit_type = $2, "references" = $3, standards = $4, available_modifiers = $5, updated_by = $6
+where id = $1
+		`
+		if _, err := tx.Exec(ctx, sql, id, test.UnitType, test.References, test.Standards, test.AvailableModifiers, by); err != nil {
+			return err
+		}
+		test.UpdatedBy = by
+		return recordChange(ctx, tx, "tests", id, storedName, models.ChangeActionUpdated, by)
+	})
+	if err != nil {
+		return nil, translateError(err, "test", testName)
+	}
+	repo.cache.invalidate(ctx, "tests", test.TestName)
+	return &test, nil
+}
+
+func (repo *PostgresTestsRepository) Rename(ctx context.Context, testName string, newTestName string, by string) (_ *models.Test, err error) {
+	ctx, end := startOperation(ctx, "TestsRepository.Rename")
+	defer end(&err)
+	newTestName = NormalizeKey(newTestName)
+	if newTestName == "" {
+		return nil, newDomainError(ErrValidationFailed, "test", testName, "new test name must not be empty")
+	}
+	var test models.Test
+	var oldTestName string
+	err = inTransaction(ctx, repo.conn, func(tx pgx.Tx) error {
+		sql := `
+with old as (
+	select id, test_name from tests where ` + keyMatch("tests", "test_name") + ` for update
+)
+update tests t
+set test_name = $2, updated_by = $3
+from old
+where t.id = old.id
+returning old.test_name, t.id::text, t.test_name, t.unit_type, t."references", t.standards, t.available_modifiers, coalesce(t.created_by, ''), coalesce(t.updated_by, '')
+		`
+		if err := tx.QueryRow(ctx, sql, NormalizeKey(testName), newTestName, by).Scan(&oldTestName, &test.ID, &test.TestName, &test.UnitType, &test.References, &test.Standards, &test.AvailableModifiers, &test.CreatedBy, &test.UpdatedBy); err != nil {
+			if err == pgx.ErrNoRows {
+				return newDomainError(ErrNotFound, "test", testName, "")
+			}
+			return err
+		}
+		if oldTestName == newTestName {
+			return newDomainError(ErrValidationFailed, "test", testName, "new test name must be different")
+		}
+		// A change of case only needs no alias: lookups already ignore case.
+		if FoldKey(oldTestName) != FoldKey(newTestName) {
+			if err := recordAlias(ctx, tx, "tests", test.ID, oldTestName, newTestName, by); err != nil {
+				return err
+			}
+		}
+		return recordRename(ctx, tx, "tests", test.ID, oldTestName, newTestName, by)
+	})
+	if err != nil {
+		return nil, translateError(err, "test", newTestName)
+	}
+	repo.cache.invalidate(ctx, "tests", oldTestName)
+	repo.cache.invalidate(ctx, "tests", newTestName)
+	return &test, nil
+}
+
+func (repo *PostgresTestsRepository) Delete(ctx context.Context, testName string, by string) (err error) {
+	ctx, end := startOperation(ctx, "TestsRepository.Delete")
+	defer end(&err)
+	err = inTransaction(ctx, repo.conn, func(tx pgx.Tx) error {
+		var id, storedName string
+		if err := tx.QueryRow(ctx, "delete from tests where "+keyMatch("tests", "test_name")+" returning id::text, test_name", NormalizeKey(testName)).Scan(&id, &storedName); err != nil {
+			return err
+		}
+		if err := deleteAliases(ctx, tx, "tests", id); err != nil {
+			return err
+		}
+		return recordChange(ctx, tx, "tests", id, storedName, models.ChangeActionDeleted, by)
+	})
+	if err != nil {
+		return translateError(err, "test", testName)
 	}
-	return err
+	repo.cache.invalidate(ctx, "tests", testName)
+	return nil
 }
 
-func (repo *TestsRepository) Migrate() error {
+func (repo *PostgresTestsRepository) Migrate(ctx context.Context) error {
 	var currentVersion int
-	row := repo.conn.QueryRow(context.Background(), "select current_version from table_versions where table_name = 'tests'")
+	row := repo.conn.QueryRow(ctx, "select current_version from table_versions where table_name = 'tests'")
 	err := row.Scan(&currentVersion)
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -99,7 +296,7 @@ func (repo *TestsRepository) Migrate() error {
 		}
 	}
 	if currentVersion < 1 {
-		_, err = repo.conn.Exec(context.Background(), `
+		_, err = repo.conn.Exec(ctx, `
 create table tests (
 	test_name text primary key,
 	unit_type text not null,
@@ -111,7 +308,7 @@ create table tests (
 		if err != nil {
 			return err
 		}
-		_, err = repo.conn.Exec(context.Background(), `
+		_, err = repo.conn.Exec(ctx, `
 insert into table_versions (table_name, current_version)
 values ('tests', 1)
 		`)
@@ -119,5 +316,50 @@ values ('tests', 1)
 			return err
 		}
 	}
+	if currentVersion < 2 {
+		_, err = repo.conn.Exec(ctx, `
+alter table tests
+	add column created_by text,
+	add column updated_by text
+		`)
+		if err != nil {
+			return err
+		}
+		_, err = repo.conn.Exec(ctx, `
+update table_versions set current_version = 2 where table_name = 'tests'
+		`)
+		if err != nil {
+			return err
+		}
+	}
+	if currentVersion < 3 {
+		_, err = repo.conn.Exec(ctx, `
+alter table tests
+	add column id uuid not null default gen_random_uuid();
+create unique index tests_id on tests (id);
+update change_log c set entity_id = t.id from tests t where c.entity_type = 'tests' and c.entity_key = t.test_name and c.entity_id is null
+		`)
+		if err != nil {
+			return err
+		}
+		_, err = repo.conn.Exec(ctx, `
+update table_versions set current_version = 3 where table_name = 'tests'
+		`)
+		if err != nil {
+			return err
+		}
+	}
+	if currentVersion < 4 {
+		err = pgx.BeginFunc(ctx, repo.conn, func(tx pgx.Tx) error {
+			if err := normalizeKeyColumn(ctx, tx, "tests", "test_name"); err != nil {
+				return err
+			}
+			_, err := tx.Exec(ctx, "update table_versions set current_version = 4 where table_name = 'tests'")
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
@@ -0,0 +1,108 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type postgresTxKey struct{}
+
+type afterCommitKey struct{}
+
+type querier interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+type PostgresTransactor struct {
+	conn *pgxpool.Pool
+}
+
+func NewPostgresTransactor(conn *pgxpool.Pool) *PostgresTransactor {
+	return &PostgresTransactor{conn: conn}
+}
+
+// WithinTransaction nested inside another one runs in a savepoint, so a failed
+// inner unit of work can be rolled back without aborting the outer transaction.
+func (transactor *PostgresTransactor) WithinTransaction(ctx context.Context, work func(ctx context.Context) error) error {
+	if tx, ok := ctx.Value(postgresTxKey{}).(pgx.Tx); ok {
+		savepoint, err := tx.Begin(ctx)
+		if err != nil {
+			return err
+		}
+		if err := work(context.WithValue(ctx, postgresTxKey{}, savepoint)); err != nil {
+			savepoint.Rollback(ctx)
+			return err
+		}
+		return savepoint.Commit(ctx)
+	}
+	var hooks []func()
+	err := inTransaction(ctx, transactor.conn, func(tx pgx.Tx) error {
+		return work(context.WithValue(context.WithValue(ctx, postgresTxKey{}, tx), afterCommitKey{}, &hooks))
+	})
+	if err != nil {
+		return err
+	}
+	for _, hook := range hooks {
+		hook()
+	}
+	return nil
+}
+
+// afterCommit defers fn until the transaction carried by ctx commits, or runs
+// it straight away when there is none. Hooks registered inside a savepoint that
+// is later rolled back still run; they only ever invalidate caches.
+func afterCommit(ctx context.Context, fn func()) {
+	if hooks, ok := ctx.Value(afterCommitKey{}).(*[]func()); ok {
+		*hooks = append(*hooks, fn)
+		return
+	}
+	fn()
+}
+
+func inTransaction(ctx context.Context, conn *pgxpool.Pool, work func(tx pgx.Tx) error) error {
+	if tx, ok := ctx.Value(postgresTxKey{}).(pgx.Tx); ok {
+		return work(tx)
+	}
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	if err := work(tx); err != nil {
+		tx.Rollback(ctx)
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// withoutStatementTimeout runs work in a transaction whose statement_timeout
+// is lifted, for streamed reads whose single query stays open for as long as
+// the caller takes to consume the rows. The server-wide limit from the
+// connection string is restored before an enclosing transaction carries on.
+func withoutStatementTimeout(ctx context.Context, conn *pgxpool.Pool, work func(tx pgx.Tx) error) error {
+	return inTransaction(ctx, conn, func(tx pgx.Tx) error {
+		var previous string
+		if err := tx.QueryRow(ctx, "select current_setting('statement_timeout')").Scan(&previous); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, "set local statement_timeout = 0"); err != nil {
+			return err
+		}
+		if err := work(tx); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx, "select set_config('statement_timeout', $1, true)", previous)
+		return err
+	})
+}
+
+func queryable(ctx context.Context, conn *pgxpool.Pool) querier {
+	if tx, ok := ctx.Value(postgresTxKey{}).(pgx.Tx); ok {
+		return tx
+	}
+	return conn
+}
@@ -1,59 +1,248 @@
 package repositories
 
 import (
+	"config/caching"
 	"config/models"
 	"context"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-type UnitsRepository struct {
-	conn *pgx.Conn
+type PostgresUnitsRepository struct {
+	conn  *pgxpool.Pool
+	cache masterDataCache
 }
 
-func NewUnitsRepository(conn *pgx.Conn) *UnitsRepository {
-	return &UnitsRepository{conn: conn}
+func NewPostgresUnitsRepository(conn *pgxpool.Pool, cacheService caching.CacheService) *PostgresUnitsRepository {
+	return &PostgresUnitsRepository{conn: conn, cache: masterDataCache{cacheService}}
 }
 
-func (repo *UnitsRepository) GetMany() (*[]models.Unit, error) {
-	sql := "select full_name, full_name_plural, abbreviation, measurement_system, unit_type from units"
-	rows, err := repo.conn.Query(context.Background(), sql)
+func (repo *PostgresUnitsRepository) GetMany(ctx context.Context) (_ *[]models.Unit, err error) {
+	ctx, end := startOperation(ctx, "UnitsRepository.GetMany")
+	defer end(&err)
+	var units []models.Unit
+	if repo.cache.get(ctx, "UNITS|ALL", &units) {
+		return &units, nil
+	}
+	sql := `
+select id::text, full_name, full_name_plural, abbreviation, measurement_system, unit_type, coalesce(created_by, ''), coalesce(updated_by, '')
+from units
+	`
+	rows, err := queryable(ctx, repo.conn).Query(ctx, sql)
 	if err != nil {
 		return nil, err
 	}
-	var units []models.Unit
+	defer rows.Close()
 	for rows.Next() {
 		var unit models.Unit
-		if err := rows.Scan(&unit.FullName, &unit.FullNamePlural, &unit.Abbreviation, &unit.MeasurementSystem, &unit.UnitType); err != nil {
+		if err := rows.Scan(&unit.ID, &unit.FullName, &unit.FullNamePlural, &unit.Abbreviation, &unit.MeasurementSystem, &unit.UnitType, &unit.CreatedBy, &unit.UpdatedBy); err != nil {
 			return nil, err
 		}
 		units = append(units, unit)
 	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	repo.cache.set(ctx, "UNITS|ALL", units)
 	return &units, nil
 }
 
-func (repo *UnitsRepository) InsertMany(units *[]models.Unit, by string, tx pgx.Tx) error {
+func (repo *PostgresUnitsRepository) ForEach(ctx context.Context, fn func(unit *models.Unit) error) (err error) {
+	ctx, end := startStreamingOperation(ctx, "UnitsRepository.ForEach")
+	defer end(&err)
 	sql := `
-insert into units (full_name, full_name_plural, abbreviation, measurement_system, unit_type)	
-values ($1, $2, $3, $4, $5)
+select id::text, full_name, full_name_plural, abbreviation, measurement_system, unit_type, coalesce(created_by, ''), coalesce(updated_by, '')
+from units
+order by unit_type, full_name
 	`
-	for _, unit := range *units {
-		if tx != nil {
-			if _, err := tx.Exec(context.Background(), sql, unit.FullName, unit.FullNamePlural, unit.Abbreviation, unit.MeasurementSystem, unit.UnitType); err != nil {
+	return withoutStatementTimeout(ctx, repo.conn, func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, sql)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var unit models.Unit
+			if err := rows.Scan(&unit.ID, &unit.FullName, &unit.FullNamePlural, &unit.Abbreviation, &unit.MeasurementSystem, &unit.UnitType, &unit.CreatedBy, &unit.UpdatedBy); err != nil {
 				return err
 			}
-		} else {
-			if _, err := repo.conn.Exec(context.Background(), sql, unit.FullName, unit.FullNamePlural, unit.Abbreviation, unit.MeasurementSystem, unit.UnitType); err != nil {
+			if err := fn(&unit); err != nil {
 				return err
 			}
 		}
+		return rows.Err()
+	})
+}
+
+func (repo *PostgresUnitsRepository) GetOne(ctx context.Context, fullName string) (_ *models.Unit, err error) {
+	ctx, end := startOperation(ctx, "UnitsRepository.GetOne")
+	defer end(&err)
+	sql := `
+select id::text, full_name, full_name_plural, abbreviation, measurement_system, unit_type, coalesce(created_by, ''), coalesce(updated_by, '')
+from units
+where full_name = $1
+	`
+	var unit models.Unit
+	if err := queryable(ctx, repo.conn).QueryRow(ctx, sql, fullName).Scan(&unit.ID, &unit.FullName, &unit.FullNamePlural, &unit.Abbreviation, &unit.MeasurementSystem, &unit.UnitType, &unit.CreatedBy, &unit.UpdatedBy); err != nil {
+		return nil, translateError(err, "unit", fullName)
+	}
+	return &unit, nil
+}
+
+func (repo *PostgresUnitsRepository) GetByID(ctx context.Context, id string) (_ *models.Unit, err error) {
+	ctx, end := startOperation(ctx, "UnitsRepository.GetByID")
+	defer end(&err)
+	if !isUUID(id) {
+		return nil, newDomainError(ErrNotFound, "unit", id, "")
 	}
+	sql := `
+select id::text, full_name, full_name_plural, abbreviation, measurement_system, unit_type, coalesce(created_by, ''), coalesce(updated_by, '')
+from units
+where id = $1
+	`
+	var unit models.Unit
+	if err := queryable(ctx, repo.conn).QueryRow(ctx, sql, id).Scan(&unit.ID, &unit.FullName, &unit.FullNamePlural, &unit.Abbreviation, &unit.MeasurementSystem, &unit.UnitType, &unit.CreatedBy, &unit.UpdatedBy); err != nil {
+		return nil, translateError(err, "unit", id)
+	}
+	return &unit, nil
+}
+
+func (repo *PostgresUnitsRepository) Create(ctx context.Context, unit *models.Unit, by string) error {
+	units := []models.Unit{*unit}
+	if err := repo.InsertMany(ctx, &units, by); err != nil {
+		return err
+	}
+	unit.ID = units[0].ID
 	return nil
 }
 
-func (repo *UnitsRepository) Migrate() error {
+func (repo *PostgresUnitsRepository) Update(ctx context.Context, unit *models.Unit, by string) (err error) {
+	ctx, end := startOperation(ctx, "UnitsRepository.Update")
+	defer end(&err)
+	sql := `
+update units
+set full_name_plural = $2, abbreviation = $3, measurement_system = $4, unit_type = $5, updated_by = $6
+where full_name = $1
+returning id::text
+	`
+	err = inTransaction(ctx, repo.conn, func(tx pgx.Tx) error {
+		if err := tx.QueryRow(ctx, sql, unit.FullName, unit.FullNamePlural, unit.Abbreviation, unit.MeasurementSystem, unit.UnitType, by).Scan(&unit.ID); err != nil {
+			return err
+		}
+		return recordChange(ctx, tx, "units", unit.ID, unit.FullName, models.ChangeActionUpdated, by)
+	})
+	if err != nil {
+		return translateError(err, "unit", unit.FullName)
+	}
+	repo.cache.invalidate(ctx, "units", unit.FullName)
+	return nil
+}
+
+func (repo *PostgresUnitsRepository) Modify(ctx context.Context, fullName string, modify func(unit *models.Unit) error, by string) (_ *models.Unit, err error) {
+	ctx, end := startOperation(ctx, "UnitsRepository.Modify")
+	defer end(&err)
+	var unit models.Unit
+	err = inTransaction(ctx, repo.conn, func(tx pgx.Tx) error {
+		sql := `
+select id::text, full_name, full_name_plural, abbreviation, measurement_system, unit_type, coalesce(created_by, ''), coalesce(updated_by, '')
+from units
+where full_name = $1
+for update
+		`
+		if err := tx.QueryRow(ctx, sql, fullName).Scan(&unit.ID, &unit.FullName, &unit.FullNamePlural, &unit.Abbreviation, &unit.MeasurementSystem, &unit.UnitType, &unit.CreatedBy, &unit.UpdatedBy); err != nil {
+			return err
+		}
+		id, createdBy, updatedBy := unit.ID, unit.CreatedBy, unit.UpdatedBy
+		if err := modify(&unit); err != nil {
+			return err
+		}
+		if unit.FullName != fullName {
+			return newDomainError(ErrValidationFailed, "unit", fullName, "fullName cannot be changed")
+		}
+		if unit.ID != id {
+			return newDomainError(ErrValidationFailed, "unit", fullName, "id cannot be changed")
+		}
+		if err := checkAuthorship("unit", fullName, createdBy, updatedBy, unit.CreatedBy, unit.UpdatedBy); err != nil {
+			return err
+		}
+		sql = `
+update units
+set full_name_plural = $2, abbreviation = $3, measurement_system = $4, unit_type = $5, updated_by = $6
+where full_name = $1
+		`
+		if _, err := tx.Exec(ctx, sql, fullName, unit.FullNamePlural, unit.Abbreviation, unit.MeasurementSystem, unit.UnitType, by); err != nil {
+			return err
+		}
+		unit.UpdatedBy = by
+		return recordChange(ctx, tx, "units", unit.ID, fullName, models.ChangeActionUpdated, by)
+	})
+	if err != nil {
+		return nil, translateError(err, "unit", fullName)
+	}
+	repo.cache.invalidate(ctx, "units", fullName)
+	return &unit, nil
+}
+
+func (repo *PostgresUnitsRepository) Delete(ctx context.Context, fullName string, by string) (err error) {
+	ctx, end := startOperation(ctx, "UnitsRepository.Delete")
+	defer end(&err)
+	err = inTransaction(ctx, repo.conn, func(tx pgx.Tx) error {
+		var id string
+		if err := tx.QueryRow(ctx, "delete from units where full_name = $1 returning id::text", fullName).Scan(&id); err != nil {
+			return err
+		}
+		return recordChange(ctx, tx, "units", id, fullName, models.ChangeActionDeleted, by)
+	})
+	if err != nil {
+		return translateError(err, "unit", fullName)
+	}
+	repo.cache.invalidate(ctx, "units", fullName)
+	return nil
+}
+
+func (repo *PostgresUnitsRepository) InsertMany(ctx context.Context, units *[]models.Unit, by string) (err error) {
+	ctx, end := startOperation(ctx, "UnitsRepository.InsertMany")
+	defer end(&err)
+	sql := `
+insert into units (full_name, full_name_plural, abbreviation, measurement_system, unit_type, created_by, updated_by)
+values ($1, $2, $3, $4, $5, $6, $6)
+returning id::text
+	`
+	if len(*units) == 0 {
+		return nil
+	}
+	err = inTransaction(ctx, repo.conn, func(tx pgx.Tx) error {
+		batch := &pgx.Batch{}
+		fullNames := make([]string, len(*units))
+		for i, unit := range *units {
+			batch.Queue(sql, unit.FullName, unit.FullNamePlural, unit.Abbreviation, unit.MeasurementSystem, unit.UnitType, by)
+			fullNames[i] = unit.FullName
+		}
+		results := tx.SendBatch(ctx, batch)
+		ids := make([]string, len(*units))
+		for i := range *units {
+			if err := results.QueryRow().Scan(&ids[i]); err != nil {
+				results.Close()
+				return err
+			}
+			(*units)[i].ID = ids[i]
+		}
+		if err := results.Close(); err != nil {
+			return err
+		}
+		return recordChanges(ctx, tx, "units", ids, fullNames, models.ChangeActionCreated, by)
+	})
+	if err != nil {
+		return translateError(err, "unit", "")
+	}
+	repo.cache.invalidate(ctx, "units", "")
+	return nil
+}
+
+func (repo *PostgresUnitsRepository) Migrate(ctx context.Context) error {
 	var currentVersion int
-	row := repo.conn.QueryRow(context.Background(), "select current_version from table_versions where table_name = 'units'")
+	row := repo.conn.QueryRow(ctx, "select current_version from table_versions where table_name = 'units'")
 	err := row.Scan(&currentVersion)
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -63,7 +252,7 @@ func (repo *UnitsRepository) Migrate() error {
 		}
 	}
 	if currentVersion < 1 {
-		_, err = repo.conn.Exec(context.Background(), `
+		_, err = repo.conn.Exec(ctx, `
 create table units (
 	full_name text primary key,
 	full_name_plural text not null,
@@ -75,7 +264,7 @@ create table units (
 		if err != nil {
 			return err
 		}
-		_, err = repo.conn.Exec(context.Background(), `
+		_, err = repo.conn.Exec(ctx, `
 insert into table_versions (table_name, current_version)
 values ('units', 1)
 		`)
@@ -83,5 +272,38 @@ values ('units', 1)
 			return err
 		}
 	}
+	if currentVersion < 2 {
+		_, err = repo.conn.Exec(ctx, `
+alter table units
+	add column created_by text,
+	add column updated_by text
+		`)
+		if err != nil {
+			return err
+		}
+		_, err = repo.conn.Exec(ctx, `
+update table_versions set current_version = 2 where table_name = 'units'
+		`)
+		if err != nil {
+			return err
+		}
+	}
+	if currentVersion < 3 {
+		_, err = repo.conn.Exec(ctx, `
+alter table units
+	add column id uuid not null default gen_random_uuid();
+create unique index units_id on units (id);
+update change_log c set entity_id = u.id from units u where c.entity_type = 'units' and c.entity_key = u.full_name and c.entity_id is null
+		`)
+		if err != nil {
+			return err
+		}
+		_, err = repo.conn.Exec(ctx, `
+update table_versions set current_version = 3 where table_name = 'units'
+		`)
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
@@ -0,0 +1,337 @@
+package repositories
+
+import (
+	"config/models"
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PostgresWebhooksRepository struct {
+	conn *pgxpool.Pool
+}
+
+func NewPostgresWebhooksRepository(conn *pgxpool.Pool) *PostgresWebhooksRepository {
+	return &PostgresWebhooksRepository{conn: conn}
+}
+
+const enqueueWebhookEventSQL = `
+with event as (
+	insert into webhook_events (change_id, event_type, payload)
+	values ($1, $2, $3)
+	returning event_id, event_type
+)
+insert into webhook_deliveries (event_id, subscription_id, status, attempts, next_attempt_at)
+select e.event_id, s.subscription_id, 'pending', 0, now()
+from event e
+join webhook_subscriptions s on s.is_active and (cardinality(s.event_types) = 0 or e.event_type = any(s.event_types))
+`
+
+func enqueueWebhookEvent(ctx context.Context, tx pgx.Tx, change *models.Change) error {
+	payload, err := json.Marshal(change)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, enqueueWebhookEventSQL, change.ChangeID, change.EntityType+"."+change.Action, payload)
+	return err
+}
+
+func (repo *PostgresWebhooksRepository) GetSubscriptions(ctx context.Context) (_ *[]models.WebhookSubscription, err error) {
+	ctx, end := startOperation(ctx, "WebhooksRepository.GetSubscriptions")
+	defer end(&err)
+	sql := `
+select subscription_id, url, event_types, is_active, created_by, updated_by
+from webhook_subscriptions
+order by subscription_id
+	`
+	rows, err := queryable(ctx, repo.conn).Query(ctx, sql)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	subscriptions := []models.WebhookSubscription{}
+	for rows.Next() {
+		var subscription models.WebhookSubscription
+		if err := rows.Scan(&subscription.SubscriptionID, &subscription.URL, &subscription.EventTypes, &subscription.IsActive, &subscription.CreatedBy, &subscription.UpdatedBy); err != nil {
+			return nil, err
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return &subscriptions, nil
+}
+
+func (repo *PostgresWebhooksRepository) GetSubscription(ctx context.Context, subscriptionID int64) (_ *models.WebhookSubscription, err error) {
+	ctx, end := startOperation(ctx, "WebhooksRepository.GetSubscription")
+	defer end(&err)
+	sql := `
+select subscription_id, url, event_types, is_active, created_by, updated_by
+from webhook_subscriptions
+where subscription_id = $1
+	`
+	var subscription models.WebhookSubscription
+	if err := queryable(ctx, repo.conn).QueryRow(ctx, sql, subscriptionID).Scan(&subscription.SubscriptionID, &subscription.URL, &subscription.EventTypes, &subscription.IsActive, &subscription.CreatedBy, &subscription.UpdatedBy); err != nil {
+		return nil, translateError(err, "webhook subscription", strconv.FormatInt(subscriptionID, 10))
+	}
+	return &subscription, nil
+}
+
+func (repo *PostgresWebhooksRepository) CreateSubscription(ctx context.Context, subscription *models.WebhookSubscription, by string) (err error) {
+	ctx, end := startOperation(ctx, "WebhooksRepository.CreateSubscription")
+	defer end(&err)
+	sql := `
+insert into webhook_subscriptions (url, secret, event_types, is_active, created_by, updated_by)
+values ($1, $2, $3, $4, $5, $5)
+returning subscription_id
+	`
+	subscription.CreatedBy = by
+	subscription.UpdatedBy = by
+	err = queryable(ctx, repo.conn).QueryRow(ctx, sql, subscription.URL, subscription.Secret, subscription.EventTypes, subscription.IsActive, by).Scan(&subscription.SubscriptionID)
+	return translateError(err, "webhook subscription", "")
+}
+
+func (repo *PostgresWebhooksRepository) UpdateSubscription(ctx context.Context, subscription *models.WebhookSubscription, by string) (err error) {
+	ctx, end := startOperation(ctx, "WebhooksRepository.UpdateSubscription")
+	defer end(&err)
+	sql := `
+update webhook_subscriptions
+set url = $2, secret = coalesce(nullif($3, ''), secret), event_types = $4, is_active = $5, updated_by = $6
+where subscription_id = $1
+	`
+	tag, err := queryable(ctx, repo.conn).Exec(ctx, sql, subscription.SubscriptionID, subscription.URL, subscription.Secret, subscription.EventTypes, subscription.IsActive, by)
+	if err != nil {
+		return translateError(err, "webhook subscription", strconv.FormatInt(subscription.SubscriptionID, 10))
+	}
+	if tag.RowsAffected() != 1 {
+		return newDomainError(ErrNotFound, "webhook subscription", strconv.FormatInt(subscription.SubscriptionID, 10), "")
+	}
+	return nil
+}
+
+func (repo *PostgresWebhooksRepository) DeleteSubscription(ctx context.Context, subscriptionID int64) (_ bool, err error) {
+	ctx, end := startOperation(ctx, "WebhooksRepository.DeleteSubscription")
+	defer end(&err)
+	tag, err := queryable(ctx, repo.conn).Exec(ctx, "delete from webhook_subscriptions where subscription_id = $1", subscriptionID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (repo *PostgresWebhooksRepository) GetDeliveries(ctx context.Context, subscriptionID int64, status *string, limit int) (_ *[]models.WebhookDelivery, err error) {
+	ctx, end := startOperation(ctx, "WebhooksRepository.GetDeliveries")
+	defer end(&err)
+	sql := `
+select d.delivery_id, d.subscription_id, d.event_id, e.event_type, d.status, d.attempts, d.next_attempt_at, d.last_error, d.delivered_at
+from webhook_deliveries d
+join webhook_events e on e.event_id = d.event_id
+where d.subscription_id = $1
+	and ($2::text is null or d.status = $2::text)
+order by d.delivery_id desc
+limit $3
+	`
+	rows, err := queryable(ctx, repo.conn).Query(ctx, sql, subscriptionID, status, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	deliveries := []models.WebhookDelivery{}
+	for rows.Next() {
+		var delivery models.WebhookDelivery
+		if err := rows.Scan(&delivery.DeliveryID, &delivery.SubscriptionID, &delivery.EventID, &delivery.EventType, &delivery.Status, &delivery.Attempts, &delivery.NextAttemptAt, &delivery.LastError, &delivery.DeliveredAt); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return &deliveries, nil
+}
+
+func (repo *PostgresWebhooksRepository) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) (_ *[]models.WebhookDelivery, err error) {
+	ctx, end := startOperation(ctx, "WebhooksRepository.ClaimDueDeliveries")
+	defer end(&err)
+	sql := `
+update webhook_deliveries d
+set next_attempt_at = now() + $2::interval
+from webhook_events e, webhook_subscriptions s
+where d.delivery_id in (
+		select delivery_id
+		from webhook_deliveries
+		where status = 'pending' and next_attempt_at <= now()
+		order by delivery_id
+		limit $1
+		for update skip locked
+	)
+	and e.event_id = d.event_id
+	and s.subscription_id = d.subscription_id
+returning d.delivery_id, d.subscription_id, d.event_id, e.event_type, d.status, d.attempts, d.next_attempt_at, d.last_error, s.url, s.secret, e.payload
+	`
+	rows, err := queryable(ctx, repo.conn).Query(ctx, sql, limit, lease)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	deliveries := []models.WebhookDelivery{}
+	for rows.Next() {
+		var delivery models.WebhookDelivery
+		if err := rows.Scan(&delivery.DeliveryID, &delivery.SubscriptionID, &delivery.EventID, &delivery.EventType, &delivery.Status, &delivery.Attempts, &delivery.NextAttemptAt, &delivery.LastError, &delivery.URL, &delivery.Secret, &delivery.Payload); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return &deliveries, nil
+}
+
+// MarkDelivered and MarkFailed only apply while the caller still holds the
+// lease it claimed the delivery with: the lease is the next_attempt_at the
+// claim set, which changes as soon as another dispatcher claims it again.
+func (repo *PostgresWebhooksRepository) MarkDelivered(ctx context.Context, deliveryID int64, lease time.Time) (err error) {
+	ctx, end := startOperation(ctx, "WebhooksRepository.MarkDelivered")
+	defer end(&err)
+	sql := `
+update webhook_deliveries
+set status = 'delivered', attempts = attempts + 1, last_error = '', delivered_at = now()
+where delivery_id = $1 and status = 'pending' and next_attempt_at = $2
+	`
+	tag, err := queryable(ctx, repo.conn).Exec(ctx, sql, deliveryID, lease)
+	if err != nil {
+		return err
+	}
+	return checkDeliveryLease(tag.RowsAffected(), deliveryID)
+}
+
+func (repo *PostgresWebhooksRepository) MarkFailed(ctx context.Context, deliveryID int64, lease time.Time, lastError string, nextAttemptAt *time.Time) (err error) {
+	ctx, end := startOperation(ctx, "WebhooksRepository.MarkFailed")
+	defer end(&err)
+	sql := `
+update webhook_deliveries
+set status = case when $4::timestamptz is null then 'dead' else 'pending' end,
+	attempts = attempts + 1,
+	last_error = $3,
+	next_attempt_at = coalesce($4::timestamptz, next_attempt_at)
+where delivery_id = $1 and status = 'pending' and next_attempt_at = $2
+	`
+	tag, err := queryable(ctx, repo.conn).Exec(ctx, sql, deliveryID, lease, lastError, nextAttemptAt)
+	if err != nil {
+		return err
+	}
+	return checkDeliveryLease(tag.RowsAffected(), deliveryID)
+}
+
+func checkDeliveryLease(rowsAffected int64, deliveryID int64) error {
+	if rowsAffected == 0 {
+		return newDomainError(ErrConflict, "webhook delivery", strconv.FormatInt(deliveryID, 10), "the lease on the delivery has passed to another dispatcher")
+	}
+	return nil
+}
+
+func (repo *PostgresWebhooksRepository) Replay(ctx context.Context, subscriptionID int64, deliveryID *int64, status *string) (_ int64, err error) {
+	ctx, end := startOperation(ctx, "WebhooksRepository.Replay")
+	defer end(&err)
+	sql := `
+update webhook_deliveries
+set status = 'pending', attempts = 0, next_attempt_at = now(), delivered_at = null
+where subscription_id = $1
+	and ($2::bigint is null or delivery_id = $2::bigint)
+	and ($3::text is null or status = $3::text)
+	`
+	tag, err := queryable(ctx, repo.conn).Exec(ctx, sql, subscriptionID, deliveryID, status)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// PruneDeliveries removes delivered and dead deliveries last touched before
+// the cutoff, then the events no delivery refers to any more.
+func (repo *PostgresWebhooksRepository) PruneDeliveries(ctx context.Context, before time.Time) (_ int64, err error) {
+	ctx, end := startOperation(ctx, "WebhooksRepository.PruneDeliveries")
+	defer end(&err)
+	var pruned int64
+	err = inTransaction(ctx, repo.conn, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, `
+delete from webhook_deliveries
+where status in ('delivered', 'dead') and coalesce(delivered_at, next_attempt_at) < $1
+		`, before)
+		if err != nil {
+			return err
+		}
+		pruned = tag.RowsAffected()
+		_, err = tx.Exec(ctx, `
+delete from webhook_events e
+where e.created_at < $1
+	and not exists (select 1 from webhook_deliveries d where d.event_id = e.event_id)
+		`, before)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return pruned, nil
+}
+
+func (repo *PostgresWebhooksRepository) Migrate(ctx context.Context) error {
+	var currentVersion int
+	row := repo.conn.QueryRow(ctx, "select current_version from table_versions where table_name = 'webhooks'")
+	err := row.Scan(&currentVersion)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			currentVersion = 0
+		} else {
+			return err
+		}
+	}
+	if currentVersion < 1 {
+		_, err = repo.conn.Exec(ctx, `
+create table webhook_subscriptions (
+	subscription_id bigserial primary key,
+	url text not null,
+	secret text not null,
+	event_types text[] not null,
+	is_active boolean not null,
+	created_by text not null,
+	updated_by text not null
+);
+create table webhook_events (
+	event_id bigserial primary key,
+	change_id bigint not null references change_log (change_id),
+	event_type text not null,
+	payload jsonb not null,
+	created_at timestamptz not null default now()
+);
+create table webhook_deliveries (
+	delivery_id bigserial primary key,
+	event_id bigint not null references webhook_events (event_id),
+	subscription_id bigint not null references webhook_subscriptions (subscription_id) on delete cascade,
+	status text not null,
+	attempts int not null,
+	next_attempt_at timestamptz not null,
+	last_error text not null default '',
+	delivered_at timestamptz
+);
+create index webhook_deliveries_due on webhook_deliveries (next_attempt_at) where status = 'pending'
+		`)
+		if err != nil {
+			return err
+		}
+		_, err = repo.conn.Exec(ctx, `
+insert into table_versions (table_name, current_version)
+values ('webhooks', 1)
+		`)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
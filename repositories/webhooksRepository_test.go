@@ -0,0 +1,50 @@
+package repositories
+
+import (
+	"config/models"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWebhookDeliveriesRespectTheLeaseAndArePruned(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	repo := NewMemoryWebhooksRepository(store)
+	if err := repo.CreateSubscription(ctx, &models.WebhookSubscription{URL: "https://hooks.example.com", EventTypes: []string{}, IsActive: true}, "test"); err != nil {
+		t.Fatal(err)
+	}
+	if err := NewMemoryProductsRepository(store).Create(ctx, &models.Product{ProductCode: "P-100"}, "test"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The first claim's lease has already run out when the second dispatcher
+	// claims the same delivery.
+	expired, err := repo.ClaimDueDeliveries(ctx, 10, -time.Millisecond)
+	if err != nil || len(*expired) != 1 {
+		t.Fatalf("expected one claimed delivery, got %v (%v)", expired, err)
+	}
+	current, err := repo.ClaimDueDeliveries(ctx, 10, time.Minute)
+	if err != nil || len(*current) != 1 {
+		t.Fatalf("expected the expired lease to be claimed again, got %v (%v)", current, err)
+	}
+	stale := (*expired)[0]
+	if err := repo.MarkDelivered(ctx, stale.DeliveryID, stale.NextAttemptAt); !errors.Is(err, ErrConflict) {
+		t.Fatalf("expected the stale dispatcher to lose the delivery, got %v", err)
+	}
+	held := (*current)[0]
+	if err := repo.MarkFailed(ctx, held.DeliveryID, held.NextAttemptAt, "gone", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if pruned, err := repo.PruneDeliveries(ctx, time.Now().Add(-time.Hour)); err != nil || pruned != 0 {
+		t.Fatalf("expected a recent dead delivery to be kept, got %v (%v)", pruned, err)
+	}
+	if pruned, err := repo.PruneDeliveries(ctx, time.Now().Add(time.Hour)); err != nil || pruned != 1 {
+		t.Fatalf("expected the dead delivery to be pruned, got %v (%v)", pruned, err)
+	}
+	if len(store.data.events) != 0 {
+		t.Fatalf("expected the event without deliveries to be pruned, got %v", len(store.data.events))
+	}
+}
@@ -0,0 +1,259 @@
+package routers
+
+import (
+	"config/models"
+	"config/openapi"
+	"config/utilities"
+	_ "embed"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+//go:embed docs/index.html
+var docsPage []byte
+
+type apiRoute struct {
+	method      string
+	path        string
+	tag         string
+	summary     string
+	permission  string
+	parameters  []openapi.Parameter
+	requestBody interface{}
+	uploads     bool
+	status      int
+	response    interface{}
+	contentType string
+	errors      []int
+	reports     map[int]interface{}
+	patch       bool
+}
+
+func queryParameter(name string, description string, schema *openapi.Schema) openapi.Parameter {
+	return openapi.Parameter{Name: name, In: "query", Description: description, Schema: schema}
+}
+
+var (
+	stringSchema  = &openapi.Schema{Type: "string"}
+	integerSchema = &openapi.Schema{Type: "integer", Format: "int32"}
+	idSchema      = &openapi.Schema{Type: "integer", Format: "int64"}
+	explode       = true
+)
+
+var exportFormatParameter = queryParameter("format", "csv (default), ndjson or xlsx; list fields are joined with \"; \" in csv and xlsx", &openapi.Schema{Type: "string", Enum: []string{"csv", "ndjson", "xlsx"}})
+
+var importParameters = []openapi.Parameter{
+	queryParameter("mode", "all-or-nothing (default) or best-effort", &openapi.Schema{Type: "string", Enum: []string{models.ImportModeAllOrNothing, models.ImportModeBestEffort}}),
+	queryParameter("writeMode", "insert (default) reports rows that already exist as errors; upsert updates them", &openapi.Schema{Type: "string", Enum: []string{models.ImportWriteModeInsert, models.ImportWriteModeUpsert}}),
+	queryParameter("dryRun", "validate and report without saving anything", &openapi.Schema{Type: "boolean"}),
+	queryParameter("batchSize", "rows written per transaction (default 500)", integerSchema),
+	queryParameter("format", "csv or xlsx when it cannot be told from the content type or file name", stringSchema),
+	queryParameter("sheet", "worksheet to read from an xlsx file (default the first)", stringSchema),
+	{Name: "columns", In: "query", Description: "maps a field to a header in the file, e.g. columns[productCode]=Code", Style: "deepObject", Explode: &explode, Schema: &openapi.Schema{Type: "object", AdditionalProperties: stringSchema}},
+}
+
+var apiRoutes = []apiRoute{
+	{method: http.MethodGet, path: "/healthz", tag: "health", summary: "Report that the process is alive", status: http.StatusOK, response: map[string]string{}},
+	{method: http.MethodGet, path: "/readyz", tag: "health", summary: "Report readiness of each dependency; not ready once the server starts draining", status: http.StatusOK, response: utilities.ReadinessReport{}, errors: []int{http.StatusServiceUnavailable}},
+
+	{method: http.MethodPost, path: "/products/import", tag: "products", summary: "Create products, or with writeMode=upsert also update them, from a CSV or XLSX file", permission: "product-import",
+		parameters: importParameters, uploads: true, status: http.StatusOK, response: models.ImportReport{}, errors: []int{http.StatusBadRequest},
+		reports: map[int]interface{}{http.StatusUnprocessableEntity: models.ImportReport{}}},
+	{method: http.MethodPost, path: "/products/batch", tag: "products", summary: "Create, update and delete products in one transaction; needs the create, edit or delete permission for each kind of operation used", permission: "product-create",
+		requestBody: models.ProductBatchRequest{}, status: http.StatusOK, response: models.BatchResult{}, errors: []int{http.StatusBadRequest},
+		reports: map[int]interface{}{http.StatusUnprocessableEntity: models.BatchResult{}}},
+	{method: http.MethodGet, path: "/products/:productCode", tag: "products", summary: "Get a product; a renamed product code redirects to the new one", permission: "product-view", status: http.StatusOK, response: models.Product{}, errors: []int{http.StatusPermanentRedirect, http.StatusNotFound}},
+	{method: http.MethodGet, path: "/products/", tag: "products", summary: "List all products", permission: "product-search", status: http.StatusOK, response: []models.Product{}},
+	{method: http.MethodGet, path: "/by-id/products/:id", tag: "products", summary: "Get a product by its immutable id", permission: "product-view", status: http.StatusOK, response: models.Product{}, errors: []int{http.StatusNotFound}},
+	{method: http.MethodGet, path: "/exports/products", tag: "products", summary: "Stream all products as CSV, NDJSON or XLSX", permission: "product-search",
+		parameters: []openapi.Parameter{exportFormatParameter}, status: http.StatusOK, response: models.Product{}, contentType: "text/csv", errors: []int{http.StatusBadRequest}},
+	{method: http.MethodPost, path: "/products/", tag: "products", summary: "Create a product", permission: "product-create", requestBody: models.Product{}, status: http.StatusCreated, errors: []int{http.StatusBadRequest, http.StatusConflict, http.StatusUnprocessableEntity}},
+	{method: http.MethodPut, path: "/products/:productCode", tag: "products", summary: "Replace a product", permission: "product-edit", requestBody: models.Product{}, status: http.StatusOK, errors: []int{http.StatusPermanentRedirect, http.StatusBadRequest, http.StatusNotFound, http.StatusUnprocessableEntity}},
+	{method: http.MethodPatch, path: "/products/:productCode", tag: "products", summary: "Partially update a product", permission: "product-edit", patch: true, status: http.StatusOK, response: models.Product{}, errors: []int{http.StatusPermanentRedirect, http.StatusBadRequest, http.StatusNotFound, http.StatusUnsupportedMediaType, http.StatusUnprocessableEntity}},
+	{method: http.MethodPost, path: "/products/:productCode/rename", tag: "products", summary: "Change a product code, leaving an alias that redirects from the old one", permission: "product-rename", requestBody: models.ProductRename{}, status: http.StatusOK, response: models.Product{}, errors: []int{http.StatusPermanentRedirect, http.StatusBadRequest, http.StatusNotFound, http.StatusConflict, http.StatusUnprocessableEntity}},
+	{method: http.MethodDelete, path: "/products/:productCode", tag: "products", summary: "Delete a product", permission: "product-delete", status: http.StatusNoContent, errors: []int{http.StatusPermanentRedirect, http.StatusNotFound, http.StatusConflict}},
+
+	{method: http.MethodPost, path: "/tests/import", tag: "tests", summary: "Create tests, or with writeMode=upsert also update them, from a CSV or XLSX file", permission: "test-import",
+		parameters: importParameters, uploads: true, status: http.StatusOK, response: models.ImportReport{}, errors: []int{http.StatusBadRequest},
+		reports: map[int]interface{}{http.StatusUnprocessableEntity: models.ImportReport{}}},
+	{method: http.MethodPost, path: "/tests/batch", tag: "tests", summary: "Create, update and delete tests in one transaction; needs the create, edit or delete permission for each kind of operation used", permission: "test-create",
+		requestBody: models.TestBatchRequest{}, status: http.StatusOK, response: models.BatchResult{}, errors: []int{http.StatusBadRequest},
+		reports: map[int]interface{}{http.StatusUnprocessableEntity: models.BatchResult{}}},
+	{method: http.MethodGet, path: "/tests/:testName", tag: "tests", summary: "Get a test; a renamed test name redirects to the new one", permission: "test-view", status: http.StatusOK, response: models.Test{}, errors: []int{http.StatusPermanentRedirect, http.StatusNotFound}},
+	{method: http.MethodGet, path: "/tests/", tag: "tests", summary: "Search tests one keyset page at a time, ordered by name", permission: "test-search",
+		parameters: []openapi.Parameter{
+			{Name: "pageSize", In: "query", Description: "maximum number of tests to return", Required: true, Schema: integerSchema},
+			queryParameter("lastKey", "name of the last test on the previous page", stringSchema),
+			queryParameter("namePattern", "case-insensitive substring of the test name; % and _ are wildcards", stringSchema),
+			{Name: "unitType", In: "query", Description: "unit types to include; repeat for several", Explode: &explode, Schema: &openapi.Schema{Type: "array", Items: stringSchema}},
+		},
+		status: http.StatusOK, response: []models.Test{}, errors: []int{http.StatusBadRequest}},
+	{method: http.MethodGet, path: "/by-id/tests/:id", tag: "tests", summary: "Get a test by its immutable id", permission: "test-view", status: http.StatusOK, response: models.Test{}, errors: []int{http.StatusNotFound}},
+	{method: http.MethodGet, path: "/exports/tests", tag: "tests", summary: "Stream tests matching the search criteria as CSV, NDJSON or XLSX", permission: "test-search",
+		parameters: []openapi.Parameter{
+			exportFormatParameter,
+			queryParameter("namePattern", "case-insensitive substring of the test name; % and _ are wildcards", stringSchema),
+			{Name: "unitType", In: "query", Description: "unit types to include; repeat for several", Explode: &explode, Schema: &openapi.Schema{Type: "array", Items: stringSchema}},
+		},
+		status: http.StatusOK, response: models.Test{}, contentType: "text/csv", errors: []int{http.StatusBadRequest}},
+	{method: http.MethodPost, path: "/tests/", tag: "tests", summary: "Create a test", permission: "test-create", requestBody: models.Test{}, status: http.StatusCreated, errors: []int{http.StatusBadRequest, http.StatusConflict, http.StatusUnprocessableEntity}},
+	{method: http.MethodPut, path: "/tests/:testName", tag: "tests", summary: "Replace a test", permission: "test-edit", requestBody: models.Test{}, status: http.StatusOK, errors: []int{http.StatusPermanentRedirect, http.StatusBadRequest, http.StatusNotFound, http.StatusUnprocessableEntity}},
+	{method: http.MethodPatch, path: "/tests/:testName", tag: "tests", summary: "Partially update a test", permission: "test-edit", patch: true, status: http.StatusOK, response: models.Test{}, errors: []int{http.StatusPermanentRedirect, http.StatusBadRequest, http.StatusNotFound, http.StatusUnsupportedMediaType, http.StatusUnprocessableEntity}},
+	{method: http.MethodPost, path: "/tests/:testName/rename", tag: "tests", summary: "Change a test name, leaving an alias that redirects from the old one", permission: "test-rename", requestBody: models.TestRename{}, status: http.StatusOK, response: models.Test{}, errors: []int{http.StatusPermanentRedirect, http.StatusBadRequest, http.StatusNotFound, http.StatusConflict, http.StatusUnprocessableEntity}},
+	{method: http.MethodDelete, path: "/tests/:testName", tag: "tests", summary: "Delete a test", permission: "test-delete", status: http.StatusNoContent, errors: []int{http.StatusPermanentRedirect, http.StatusNotFound, http.StatusConflict}},
+
+	{method: http.MethodGet, path: "/units/", tag: "units", summary: "List all units", permission: "unit-search", status: http.StatusOK, response: []models.Unit{}},
+	{method: http.MethodGet, path: "/by-id/units/:id", tag: "units", summary: "Get a unit by its immutable id", permission: "unit-view", status: http.StatusOK, response: models.Unit{}, errors: []int{http.StatusNotFound}},
+	{method: http.MethodGet, path: "/exports/units", tag: "units", summary: "Stream all units as CSV, NDJSON or XLSX", permission: "unit-search",
+		parameters: []openapi.Parameter{exportFormatParameter}, status: http.StatusOK, response: models.Unit{}, contentType: "text/csv", errors: []int{http.StatusBadRequest}},
+	{method: http.MethodGet, path: "/units/:fullName", tag: "units", summary: "Get a unit", permission: "unit-view", status: http.StatusOK, response: models.Unit{}, errors: []int{http.StatusNotFound}},
+	{method: http.MethodPost, path: "/units/", tag: "units", summary: "Create a unit", permission: "unit-create", requestBody: models.Unit{}, status: http.StatusCreated, errors: []int{http.StatusBadRequest, http.StatusConflict, http.StatusUnprocessableEntity}},
+	{method: http.MethodPut, path: "/units/:fullName", tag: "units", summary: "Replace a unit", permission: "unit-edit", requestBody: models.Unit{}, status: http.StatusOK, errors: []int{http.StatusBadRequest, http.StatusNotFound, http.StatusUnprocessableEntity}},
+	{method: http.MethodPatch, path: "/units/:fullName", tag: "units", summary: "Partially update a unit", permission: "unit-edit", patch: true, status: http.StatusOK, response: models.Unit{}, errors: []int{http.StatusBadRequest, http.StatusNotFound, http.StatusUnsupportedMediaType, http.StatusUnprocessableEntity}},
+	{method: http.MethodDelete, path: "/units/:fullName", tag: "units", summary: "Delete a unit", permission: "unit-delete", status: http.StatusNoContent, errors: []int{http.StatusNotFound, http.StatusConflict}},
+
+	{method: http.MethodGet, path: "/config-settings/", tag: "config-settings", summary: "List all config settings", permission: "config-view", status: http.StatusOK, response: []models.ConfigSetting{}},
+	{method: http.MethodGet, path: "/config-settings/:name", tag: "config-settings", summary: "Get a config setting", permission: "config-view", status: http.StatusOK, response: models.ConfigSetting{}, errors: []int{http.StatusNotFound}},
+	{method: http.MethodPut, path: "/config-settings/:name", tag: "config-settings", summary: "Create or replace a config setting", permission: "config-edit", requestBody: models.ConfigSetting{}, status: http.StatusOK, errors: []int{http.StatusBadRequest, http.StatusUnprocessableEntity}},
+	{method: http.MethodPatch, path: "/config-settings/:name", tag: "config-settings", summary: "Partially update a config setting", permission: "config-edit", patch: true, status: http.StatusOK, response: models.ConfigSetting{}, errors: []int{http.StatusBadRequest, http.StatusNotFound, http.StatusUnsupportedMediaType, http.StatusUnprocessableEntity}},
+	{method: http.MethodDelete, path: "/config-settings/:name", tag: "config-settings", summary: "Delete a config setting", permission: "config-delete", status: http.StatusNoContent, errors: []int{http.StatusNotFound}},
+
+	{method: http.MethodGet, path: "/webhooks/", tag: "webhooks", summary: "List webhook subscriptions", permission: "webhook-view", status: http.StatusOK, response: []models.WebhookSubscription{}},
+	{method: http.MethodGet, path: "/webhooks/:subscriptionId", tag: "webhooks", summary: "Get a webhook subscription", permission: "webhook-view", status: http.StatusOK, response: models.WebhookSubscription{}, errors: []int{http.StatusBadRequest, http.StatusNotFound}},
+	{method: http.MethodPost, path: "/webhooks/", tag: "webhooks", summary: "Create a webhook subscription; the generated secret is only returned here", permission: "webhook-create", requestBody: models.WebhookSubscription{}, status: http.StatusCreated, response: models.WebhookSubscription{}, errors: []int{http.StatusBadRequest}},
+	{method: http.MethodPut, path: "/webhooks/:subscriptionId", tag: "webhooks", summary: "Replace a webhook subscription", permission: "webhook-edit", requestBody: models.WebhookSubscription{}, status: http.StatusOK, errors: []int{http.StatusBadRequest, http.StatusNotFound}},
+	{method: http.MethodDelete, path: "/webhooks/:subscriptionId", tag: "webhooks", summary: "Delete a webhook subscription", permission: "webhook-delete", status: http.StatusNoContent, errors: []int{http.StatusBadRequest, http.StatusNotFound}},
+	{method: http.MethodGet, path: "/webhooks/:subscriptionId/deliveries", tag: "webhooks", summary: "List deliveries for a subscription, newest first", permission: "webhook-view",
+		parameters: []openapi.Parameter{
+			queryParameter("status", "only deliveries in this status (pending, delivered, dead)", stringSchema),
+			queryParameter("limit", "maximum number of deliveries to return (default 100)", integerSchema),
+		},
+		status: http.StatusOK, response: []models.WebhookDelivery{}, errors: []int{http.StatusBadRequest}},
+	{method: http.MethodPost, path: "/webhooks/:subscriptionId/replay", tag: "webhooks", summary: "Queue deliveries for another attempt", permission: "webhook-replay",
+		parameters: []openapi.Parameter{
+			queryParameter("deliveryId", "replay only this delivery", idSchema),
+			queryParameter("status", "replay deliveries in this status (default dead)", stringSchema),
+		},
+		status: http.StatusAccepted, response: map[string]int64{}, errors: []int{http.StatusBadRequest}},
+
+	{method: http.MethodGet, path: "/changes/stream", tag: "changes", summary: "Stream configuration changes as server-sent events", permission: "change-view",
+		parameters: []openapi.Parameter{
+			{Name: "Last-Event-ID", In: "header", Description: "resume after this change id", Schema: idSchema},
+			queryParameter("lastEventId", "resume after this change id when the header cannot be set", idSchema),
+		},
+		status: http.StatusOK, response: models.Change{}, contentType: "text/event-stream", errors: []int{http.StatusBadRequest, http.StatusGone}},
+}
+
+func APIDocument() *openapi.Document {
+	document := openapi.NewDocument("QME Config Service", "1.0.0", "Master data for products, tests and units of measure.")
+	document.Components.SecuritySchemes["bearerAuth"] = openapi.SecurityScheme{Type: "http", Scheme: "bearer"}
+	problem := document.SchemaFor(Problem{})
+	for _, route := range apiRoutes {
+		path, parameters := openAPIPath(route.path)
+		operation := &openapi.Operation{
+			OperationID:        operationID(route.method, route.path),
+			Summary:            route.summary,
+			Tags:               []string{route.tag},
+			Parameters:         append(parameters, route.parameters...),
+			Responses:          make(map[string]openapi.Response),
+			RequiredPermission: route.permission,
+		}
+		if route.requestBody != nil {
+			operation.RequestBody = &openapi.RequestBody{
+				Required: true,
+				Content:  map[string]openapi.MediaType{"application/json": {Schema: document.SchemaFor(route.requestBody)}},
+			}
+		}
+		if route.uploads {
+			file := &openapi.Schema{Type: "string", Format: "binary"}
+			operation.RequestBody = &openapi.RequestBody{
+				Required: true,
+				Content: map[string]openapi.MediaType{
+					"text/csv":            {Schema: stringSchema},
+					xlsxContentType:       {Schema: file},
+					"multipart/form-data": {Schema: &openapi.Schema{Type: "object", Properties: map[string]*openapi.Schema{"file": file}, Required: []string{"file"}}},
+				},
+			}
+		}
+		if route.patch {
+			patchDocument := &openapi.Schema{Type: "object", Description: "RFC 7396 merge patch; arrays are replaced as a whole"}
+			jsonPatch := &openapi.Schema{Type: "array", Description: "RFC 6902 operations, e.g. add to /availableModifiers/-", Items: &openapi.Schema{Type: "object"}}
+			operation.RequestBody = &openapi.RequestBody{
+				Required: true,
+				Content:  map[string]openapi.MediaType{mergePatchContentType: {Schema: patchDocument}, jsonPatchContentType: {Schema: jsonPatch}},
+			}
+		}
+		for status, report := range route.reports {
+			operation.Responses[strconv.Itoa(status)] = openapi.Response{
+				Description: http.StatusText(status),
+				Content:     map[string]openapi.MediaType{"application/json": {Schema: document.SchemaFor(report)}},
+			}
+		}
+		success := openapi.Response{Description: http.StatusText(route.status)}
+		if route.response != nil {
+			contentType := route.contentType
+			if contentType == "" {
+				contentType = "application/json"
+			}
+			success.Content = map[string]openapi.MediaType{contentType: {Schema: document.SchemaFor(route.response)}}
+		}
+		operation.Responses[strconv.Itoa(route.status)] = success
+		errorStatuses := route.errors
+		if route.permission != "" {
+			operation.Description = "Requires the " + route.permission + " permission."
+			operation.Security = []map[string][]string{{"bearerAuth": {}}}
+			errorStatuses = append(errorStatuses, http.StatusUnauthorized, http.StatusForbidden)
+		}
+		errorStatuses = append(errorStatuses, http.StatusInternalServerError)
+		for _, status := range errorStatuses {
+			operation.Responses[strconv.Itoa(status)] = openapi.Response{
+				Description: http.StatusText(status),
+				Content:     map[string]openapi.MediaType{problemContentType: {Schema: problem}},
+			}
+		}
+		document.AddOperation(strings.ToLower(route.method), path, operation)
+	}
+	return document
+}
+
+func RegisterDocs(r *gin.Engine) {
+	document := APIDocument()
+	r.GET("/openapi.json", func(c *gin.Context) {
+		c.JSON(http.StatusOK, document)
+	})
+	r.GET("/docs", func(c *gin.Context) {
+		c.Data(http.StatusOK, "text/html; charset=utf-8", docsPage)
+	})
+}
+
+func openAPIPath(ginPath string) (string, []openapi.Parameter) {
+	var parameters []openapi.Parameter
+	segments := strings.Split(ginPath, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			name := strings.TrimPrefix(segment, ":")
+			schema := stringSchema
+			if strings.HasSuffix(name, "Id") {
+				schema = idSchema
+			}
+			parameters = append(parameters, openapi.Parameter{Name: name, In: "path", Required: true, Schema: schema})
+			segments[i] = "{" + name + "}"
+		}
+	}
+	return strings.Join(segments, "/"), parameters
+}
+
+func operationID(method string, ginPath string) string {
+	var words []string
+	for _, segment := range strings.Split(ginPath, "/") {
+		segment = strings.TrimPrefix(segment, ":")
+		if segment == "" {
+			continue
+		}
+		words = append(words, strings.ToUpper(segment[:1])+segment[1:])
+	}
+	return strings.ToLower(method) + strings.Join(words, "")
+}
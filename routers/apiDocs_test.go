@@ -0,0 +1,105 @@
+package routers_test
+
+import (
+	"config/openapi"
+	"config/routers"
+	"config/testharness"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+var undocumentedRoutes = map[string]bool{
+	"GET /openapi.json": true,
+	"GET /docs":         true,
+}
+
+func TestAPIDocumentMatchesRoutes(t *testing.T) {
+	harness := testharness.New(t)
+	document := routers.APIDocument()
+
+	registered := make(map[string]bool)
+	for _, route := range harness.Engine.Routes() {
+		key := route.Method + " " + route.Path
+		if undocumentedRoutes[key] {
+			continue
+		}
+		path := openAPIPath(route.Path)
+		registered[strings.ToLower(route.Method)+" "+path] = true
+		item, ok := document.Paths[path]
+		if !ok || (*item)[strings.ToLower(route.Method)] == nil {
+			t.Errorf("route %v is registered but missing from the OpenAPI document", key)
+		}
+	}
+	for path, item := range document.Paths {
+		for method, operation := range *item {
+			if !registered[method+" "+path] {
+				t.Errorf("OpenAPI document describes %v %v but no such route is registered", strings.ToUpper(method), path)
+			}
+			checkOperation(t, document, method, path, operation)
+		}
+	}
+}
+
+func checkOperation(t *testing.T, document *openapi.Document, method string, path string, operation *openapi.Operation) {
+	t.Helper()
+	for _, segment := range strings.Split(path, "/") {
+		if !strings.HasPrefix(segment, "{") {
+			continue
+		}
+		name := strings.Trim(segment, "{}")
+		found := false
+		for _, parameter := range operation.Parameters {
+			found = found || (parameter.In == "path" && parameter.Name == name)
+		}
+		if !found {
+			t.Errorf("%v %v does not describe path parameter %v", method, path, name)
+		}
+	}
+	for _, response := range operation.Responses {
+		for _, media := range response.Content {
+			if ref := media.Schema.Ref; ref != "" && document.Components.Schemas[strings.TrimPrefix(ref, "#/components/schemas/")] == nil {
+				t.Errorf("%v %v refers to undefined schema %v", method, path, ref)
+			}
+		}
+	}
+}
+
+func TestAPIDocumentIsServed(t *testing.T) {
+	harness := testharness.New(t)
+	anonymous := harness.Anonymous()
+
+	recorder := anonymous.Get("/openapi.json")
+	testharness.ExpectStatus(t, recorder, http.StatusOK)
+	document := testharness.Decode[openapi.Document](t, recorder)
+	search := (*document.Paths["/tests/"])["get"]
+	if search == nil || search.RequiredPermission != "test-search" {
+		t.Fatalf("expected the test search operation, got %+v", search)
+	}
+	var names []string
+	for _, parameter := range search.Parameters {
+		names = append(names, parameter.Name)
+	}
+	if strings.Join(names, ",") != "pageSize,lastKey,namePattern,unitType" {
+		t.Fatalf("unexpected test search parameters %v", names)
+	}
+	if test := document.Components.Schemas["Test"]; test == nil || test.Properties["availableModifiers"].Type != "array" {
+		t.Fatalf("expected the Test schema to be generated from models.Test, got %+v", test)
+	}
+
+	recorder = anonymous.Get("/docs")
+	testharness.ExpectStatus(t, recorder, http.StatusOK)
+	if !strings.Contains(recorder.Body.String(), "openapi.json") {
+		t.Fatal("expected the docs page to load openapi.json")
+	}
+}
+
+func openAPIPath(ginPath string) string {
+	segments := strings.Split(ginPath, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			segments[i] = "{" + strings.TrimPrefix(segment, ":") + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
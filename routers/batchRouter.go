@@ -0,0 +1,206 @@
+package routers
+
+import (
+	"config/models"
+	"config/repositories"
+	"config/utilities"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+const maxBatchOperations = 1000
+
+var errBatchRolledBack = errors.New("batch rolled back")
+
+type batchOperation[T any] struct {
+	op   string
+	key  string
+	item *T
+}
+
+type batchTarget[T any] struct {
+	entity     string
+	entityType string
+	permission string
+	key        func(item *T) string
+	setKey     func(item *T, key string)
+	create     func(ctx context.Context, item *T, by string) error
+	update     func(ctx context.Context, item *T, by string) error
+	delete     func(ctx context.Context, key string, by string) error
+}
+
+var batchPermissionSuffixes = map[string]string{
+	models.BatchOpCreate: "-create",
+	models.BatchOpUpdate: "-edit",
+	models.BatchOpDelete: "-delete",
+}
+
+func RegisterBatches(r *gin.Engine, transactor repositories.Transactor, productsRepo repositories.ProductsRepository, testsRepo repositories.TestsRepository, aliasesRepo repositories.AliasesRepository, permissionsHelper *utilities.PermissionsHelper) {
+	products := batchTarget[models.Product]{
+		entity:     "product",
+		entityType: "products",
+		permission: "product",
+		key:        func(product *models.Product) string { return product.ProductCode },
+		setKey:     func(product *models.Product, key string) { product.ProductCode = key },
+		create:     productsRepo.Create,
+		update:     productsRepo.Update,
+		delete:     productsRepo.Delete,
+	}
+	tests := batchTarget[models.Test]{
+		entity:     "test",
+		entityType: "tests",
+		permission: "test",
+		key:        func(test *models.Test) string { return test.TestName },
+		setKey:     func(test *models.Test, key string) { test.TestName = key },
+		create:     testsRepo.Create,
+		update:     testsRepo.Update,
+		delete:     testsRepo.Delete,
+	}
+	r.POST("/products/batch", func(c *gin.Context) {
+		var request models.ProductBatchRequest
+		bindErr := c.ShouldBindJSON(&request)
+		operations := make([]batchOperation[models.Product], len(request.Operations))
+		for i, operation := range request.Operations {
+			operations[i] = batchOperation[models.Product]{op: operation.Op, key: operation.Key, item: operation.Product}
+		}
+		runBatch(c, transactor, aliasesRepo, permissionsHelper, products, request.AllOrNothing, operations, bindErr)
+	})
+	r.POST("/tests/batch", func(c *gin.Context) {
+		var request models.TestBatchRequest
+		bindErr := c.ShouldBindJSON(&request)
+		operations := make([]batchOperation[models.Test], len(request.Operations))
+		for i, operation := range request.Operations {
+			operations[i] = batchOperation[models.Test]{op: operation.Op, key: operation.Key, item: operation.Test}
+		}
+		runBatch(c, transactor, aliasesRepo, permissionsHelper, tests, request.AllOrNothing, operations, bindErr)
+	})
+}
+
+// runBatch checks each permission the batch needs once, then applies every
+// operation in its own savepoint inside a single transaction, so a failed item
+// can be reported without losing the others unless allOrNothing is set.
+func runBatch[T any](c *gin.Context, transactor repositories.Transactor, aliasesRepo repositories.AliasesRepository, permissionsHelper *utilities.PermissionsHelper, target batchTarget[T], allOrNothing bool, operations []batchOperation[T], bindErr error) {
+	permissions := []string{}
+	for _, op := range []string{models.BatchOpCreate, models.BatchOpUpdate, models.BatchOpDelete} {
+		for _, operation := range operations {
+			if operation.op == op {
+				permissions = append(permissions, target.permission+batchPermissionSuffixes[op])
+				break
+			}
+		}
+	}
+	if len(permissions) == 0 {
+		permissions = append(permissions, target.permission+batchPermissionSuffixes[models.BatchOpCreate])
+	}
+	for _, permission := range permissions {
+		permissionsResult := checkPermissions(c, permission, permissionsHelper)
+		if permissionsResult != http.StatusOK {
+			abortWithProblem(c, permissionsResult, "")
+			return
+		}
+	}
+	if bindErr != nil {
+		log.Ctx(c.Request.Context()).Warn().Err(bindErr).Msg("request body could not be bound")
+		abortWithProblem(c, http.StatusBadRequest, "request body is not valid JSON for this resource: "+bindErr.Error())
+		return
+	}
+	if len(operations) == 0 || len(operations) > maxBatchOperations {
+		abortWithProblem(c, http.StatusBadRequest, fmt.Sprintf("a batch must have between 1 and %v operations", maxBatchOperations))
+		return
+	}
+
+	by := currentUserID(c)
+	result := &models.BatchResult{AllOrNothing: allOrNothing, Results: make([]models.BatchItemResult, len(operations))}
+	err := transactor.WithinTransaction(c.Request.Context(), func(ctx context.Context) error {
+		for i, operation := range operations {
+			itemResult := &result.Results[i]
+			*itemResult = models.BatchItemResult{Index: i, Op: operation.op, Key: operation.key}
+			status, err := applyBatchOperation(ctx, transactor, aliasesRepo, target, operation, by, itemResult)
+			var domainError *repositories.DomainError
+			switch {
+			case err == nil:
+				itemResult.Status = status
+				result.Succeeded++
+				continue
+			case status == http.StatusBadRequest:
+				itemResult.Status = status
+			case errors.As(err, &domainError):
+				itemResult.Status = statusForError(err)
+			default:
+				return err
+			}
+			itemResult.Error = err.Error()
+			result.Failed++
+		}
+		if allOrNothing && result.Failed > 0 {
+			return errBatchRolledBack
+		}
+		return nil
+	})
+	switch {
+	case errors.Is(err, errBatchRolledBack):
+		for i := range result.Results {
+			if result.Results[i].Error == "" {
+				result.Results[i].Status = http.StatusFailedDependency
+				result.Results[i].Error = "rolled back because another operation in the batch failed"
+			}
+		}
+		result.Succeeded = 0
+		log.Ctx(c.Request.Context()).Warn().Msgf("%v batch rolled back after %v failed operations", target.entity, result.Failed)
+		c.JSON(http.StatusUnprocessableEntity, result)
+	case err != nil:
+		abortWithError(c, err, "error applying "+target.entity+" batch")
+	default:
+		result.Committed = true
+		log.Ctx(c.Request.Context()).Info().Msgf("%v batch applied: %v succeeded, %v failed", target.entity, result.Succeeded, result.Failed)
+		c.JSON(http.StatusOK, result)
+	}
+}
+
+// applyBatchOperation applies one operation in its own savepoint. An update
+// or delete of a key that has since been renamed is retried under the new
+// key, as the keyed routes would redirect it.
+func applyBatchOperation[T any](ctx context.Context, transactor repositories.Transactor, aliasesRepo repositories.AliasesRepository, target batchTarget[T], operation batchOperation[T], by string, itemResult *models.BatchItemResult) (int, error) {
+	var status int
+	var work func(ctx context.Context, key string) error
+	var key string
+	switch operation.op {
+	case models.BatchOpCreate, models.BatchOpUpdate:
+		if operation.item == nil {
+			return http.StatusBadRequest, fmt.Errorf("%v is required for %v", target.entity, operation.op)
+		}
+		key = target.key(operation.item)
+		if operation.key != "" && repositories.FoldKey(operation.key) != repositories.FoldKey(key) {
+			return http.StatusBadRequest, fmt.Errorf("key '%v' does not match %v '%v'", operation.key, target.entity, key)
+		}
+		itemResult.Key = key
+		status, work = http.StatusCreated, func(ctx context.Context, key string) error { return target.create(ctx, operation.item, by) }
+		if operation.op == models.BatchOpUpdate {
+			status, work = http.StatusOK, func(ctx context.Context, key string) error {
+				target.setKey(operation.item, key)
+				return target.update(ctx, operation.item, by)
+			}
+		}
+	case models.BatchOpDelete:
+		if operation.key == "" {
+			return http.StatusBadRequest, fmt.Errorf("key is required for delete")
+		}
+		key = operation.key
+		status, work = http.StatusNoContent, func(ctx context.Context, key string) error { return target.delete(ctx, key, by) }
+	default:
+		return http.StatusBadRequest, fmt.Errorf("unknown op '%v'; expected create, update or delete", operation.op)
+	}
+	err := transactor.WithinTransaction(ctx, func(ctx context.Context) error { return work(ctx, key) })
+	if errors.Is(err, repositories.ErrNotFound) && operation.op != models.BatchOpCreate {
+		if newKey, ok := resolveRenamed(ctx, aliasesRepo, target.entityType, key); ok {
+			itemResult.Key = newKey
+			err = transactor.WithinTransaction(ctx, func(ctx context.Context) error { return work(ctx, newKey) })
+		}
+	}
+	return status, err
+}
@@ -0,0 +1,86 @@
+package routers_test
+
+import (
+	"config/models"
+	"config/testharness"
+	"net/http"
+	"testing"
+)
+
+func TestTestsBatch(t *testing.T) {
+	harness := testharness.New(t)
+	editor := harness.AsUser("editor", "test-view", "test-create", "test-edit", "test-delete")
+	createTests(t, editor, models.Test{TestName: "Thickness", UnitType: "linear", References: []string{}, Standards: []string{}, AvailableModifiers: []string{}})
+	test := func(name string, unitType string) *models.Test {
+		return &models.Test{TestName: name, UnitType: unitType, References: []string{}, Standards: []string{}, AvailableModifiers: []string{}}
+	}
+	operations := []models.TestBatchOperation{
+		{Op: models.BatchOpCreate, Test: test("Tear Strength", "pressure")},
+		{Op: models.BatchOpUpdate, Test: test("Thickness", "area")},
+		{Op: models.BatchOpCreate, Test: test("Thickness", "linear")},
+		{Op: models.BatchOpDelete, Key: "Missing"},
+		{Op: "rename", Key: "Thickness"},
+	}
+
+	recorder := editor.Post("/tests/batch", models.TestBatchRequest{AllOrNothing: true, Operations: operations})
+	testharness.ExpectStatus(t, recorder, http.StatusUnprocessableEntity)
+	result := testharness.Decode[models.BatchResult](t, recorder)
+	expected := []int{http.StatusFailedDependency, http.StatusFailedDependency, http.StatusConflict, http.StatusNotFound, http.StatusBadRequest}
+	if result.Committed || result.Failed != 3 || len(result.Results) != len(expected) {
+		t.Fatalf("unexpected all-or-nothing result %+v", result)
+	}
+	for i, status := range expected {
+		if result.Results[i].Status != status {
+			t.Fatalf("expected item %v to have status %v, got %+v", i, status, result.Results[i])
+		}
+	}
+	testharness.ExpectStatus(t, editor.Get("/tests/Tear%20Strength"), http.StatusNotFound)
+
+	recorder = editor.Post("/tests/batch", models.TestBatchRequest{Operations: operations})
+	testharness.ExpectStatus(t, recorder, http.StatusOK)
+	result = testharness.Decode[models.BatchResult](t, recorder)
+	if !result.Committed || result.Succeeded != 2 || result.Failed != 3 || result.Results[0].Status != http.StatusCreated || result.Results[1].Key != "Thickness" {
+		t.Fatalf("unexpected best-effort result %+v", result)
+	}
+	testharness.ExpectStatus(t, editor.Get("/tests/Tear%20Strength"), http.StatusOK)
+	if updated := testharness.Decode[models.Test](t, editor.Get("/tests/Thickness")); updated.UnitType != "area" {
+		t.Fatalf("expected batch update to stick, got %+v", updated)
+	}
+}
+
+func TestProductsBatchChecksEachPermission(t *testing.T) {
+	harness := testharness.New(t)
+	creator := harness.AsUser("creator", "product-create")
+	request := models.ProductBatchRequest{Operations: []models.ProductBatchOperation{
+		{Op: models.BatchOpCreate, Product: &models.Product{ProductCode: "P-100", Description: "Plain weave"}},
+		{Op: models.BatchOpDelete, Key: "P-200"},
+	}}
+	testharness.ExpectStatus(t, creator.Post("/products/batch", request), http.StatusForbidden)
+
+	request.Operations = request.Operations[:1]
+	testharness.ExpectStatus(t, creator.Post("/products/batch", request), http.StatusOK)
+	testharness.ExpectStatus(t, creator.Post("/products/batch", models.ProductBatchRequest{}), http.StatusBadRequest)
+	if calls := harness.Auth.Calls("/secure/authz-checks/product-delete"); calls != 1 {
+		t.Fatalf("expected one product-delete check, got %v", calls)
+	}
+}
+
+func TestBatchKeysIgnoreCaseAndSpacing(t *testing.T) {
+	harness := testharness.New(t)
+	editor := harness.AsUser("editor", "test-view", "test-create", "test-edit")
+	createTests(t, editor, models.Test{TestName: "Tensile Strength", UnitType: "pressure", References: []string{}, Standards: []string{}, AvailableModifiers: []string{}})
+	operations := []models.TestBatchOperation{
+		{Op: models.BatchOpUpdate, Key: "tensile  STRENGTH", Test: &models.Test{TestName: "Tensile Strength", UnitType: "weight", References: []string{}, Standards: []string{}, AvailableModifiers: []string{}}},
+		{Op: models.BatchOpUpdate, Key: "Elongation", Test: &models.Test{TestName: "Tensile Strength", UnitType: "linear"}},
+	}
+
+	recorder := editor.Post("/tests/batch", models.TestBatchRequest{Operations: operations})
+	testharness.ExpectStatus(t, recorder, http.StatusOK)
+	result := testharness.Decode[models.BatchResult](t, recorder)
+	if result.Results[0].Status != http.StatusOK || result.Results[1].Status != http.StatusBadRequest {
+		t.Fatalf("expected only the differently named key to be rejected, got %+v", result)
+	}
+	if updated := testharness.Decode[models.Test](t, editor.Get("/tests/Tensile%20Strength")); updated.UnitType != "weight" {
+		t.Fatalf("expected the batch update to apply, got %+v", updated)
+	}
+}
@@ -0,0 +1,53 @@
+package routers
+
+import (
+	"config/repositories"
+	"config/utilities"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterByID serves lookups by immutable id under their own prefix, so no
+// product code, test name or unit name can be shadowed by them.
+func RegisterByID(byIDGroup *gin.RouterGroup, productsRepo repositories.ProductsRepository, testsRepo repositories.TestsRepository, unitsRepo repositories.UnitsRepository, permissionsHelper *utilities.PermissionsHelper) {
+	byIDGroup.GET("/products/:id", func(c *gin.Context) {
+		permissionsResult := checkPermissions(c, "product-view", permissionsHelper)
+		if permissionsResult != http.StatusOK {
+			abortWithProblem(c, permissionsResult, "")
+			return
+		}
+		product, err := productsRepo.GetByID(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			abortWithError(c, err, "error retrieving product")
+			return
+		}
+		c.JSON(http.StatusOK, product)
+	})
+	byIDGroup.GET("/tests/:id", func(c *gin.Context) {
+		permissionsResult := checkPermissions(c, "test-view", permissionsHelper)
+		if permissionsResult != http.StatusOK {
+			abortWithProblem(c, permissionsResult, "")
+			return
+		}
+		test, err := testsRepo.GetByID(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			abortWithError(c, err, "error retrieving test")
+			return
+		}
+		c.JSON(http.StatusOK, test)
+	})
+	byIDGroup.GET("/units/:id", func(c *gin.Context) {
+		permissionsResult := checkPermissions(c, "unit-view", permissionsHelper)
+		if permissionsResult != http.StatusOK {
+			abortWithProblem(c, permissionsResult, "")
+			return
+		}
+		unit, err := unitsRepo.GetByID(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			abortWithError(c, err, "error retrieving unit")
+			return
+		}
+		c.JSON(http.StatusOK, unit)
+	})
+}
@@ -0,0 +1,102 @@
+package routers
+
+import (
+	"config/repositories"
+	"config/utilities"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+const changeStreamBatchSize = 100
+
+func RegisterChanges(changesGroup *gin.RouterGroup, changeLogRepo repositories.ChangeLogRepository, changeNotifier repositories.ChangeNotifier, shuttingDown <-chan struct{}, permissionsHelper *utilities.PermissionsHelper) {
+	changesGroup.GET("/stream", func(c *gin.Context) {
+		permissionsResult := checkPermissions(c, "change-view", permissionsHelper)
+		if permissionsResult != http.StatusOK {
+			abortWithProblem(c, permissionsResult, "")
+			return
+		}
+		lastEventID := c.GetHeader("Last-Event-ID")
+		if lastEventID == "" {
+			lastEventID = c.Query("lastEventId")
+		}
+		var cursor int64
+		if lastEventID != "" {
+			parsed, err := strconv.ParseInt(lastEventID, 10, 64)
+			if err != nil {
+				log.Ctx(c.Request.Context()).Warn().Msgf("Unable to parse Last-Event-ID value of '%v' as int", lastEventID)
+				abortWithProblem(c, http.StatusBadRequest, "Last-Event-ID must be an integer change id")
+				return
+			}
+			oldest, err := changeLogRepo.GetOldestChangeID(c.Request.Context())
+			if err != nil {
+				abortWithError(c, err, "error retrieving oldest change id")
+				return
+			}
+			if oldest > 0 && parsed < oldest-1 {
+				abortWithProblem(c, http.StatusGone, fmt.Sprintf("changes before %v are no longer kept; reload and stream without Last-Event-ID", oldest))
+				return
+			}
+			cursor = parsed
+		} else {
+			latest, err := changeLogRepo.GetLatestChangeID(c.Request.Context())
+			if err != nil {
+				abortWithError(c, err, "error retrieving latest change id")
+				return
+			}
+			cursor = latest
+		}
+
+		wake := make(chan struct{}, 1)
+		unsubscribe := changeNotifier.Subscribe(func(repositories.ChangeNotification) {
+			select {
+			case wake <- struct{}{}:
+			default:
+			}
+		})
+		defer unsubscribe()
+		poll := time.NewTicker(5 * time.Second)
+		defer poll.Stop()
+		heartbeat := time.NewTicker(15 * time.Second)
+		defer heartbeat.Stop()
+
+		c.Header("Content-Type", "text/event-stream")
+		c.Header("Cache-Control", "no-cache")
+		c.Header("Connection", "keep-alive")
+		c.Header("X-Accel-Buffering", "no")
+		c.Status(http.StatusOK)
+		c.Writer.Flush()
+		for {
+			changes, err := changeLogRepo.GetSince(c.Request.Context(), cursor, changeStreamBatchSize)
+			if err != nil {
+				log.Ctx(c.Request.Context()).Error().Err(err).Msg("error retrieving changes")
+				return
+			}
+			for _, change := range *changes {
+				c.Render(-1, sse.Event{Id: strconv.FormatInt(change.ChangeID, 10), Event: change.EntityType, Data: change})
+				cursor = change.ChangeID
+			}
+			c.Writer.Flush()
+			if len(*changes) == changeStreamBatchSize {
+				continue
+			}
+			select {
+			case <-c.Request.Context().Done():
+				return
+			case <-shuttingDown:
+				return
+			case <-wake:
+			case <-poll.C:
+			case <-heartbeat.C:
+				c.Writer.WriteString(": keepalive\n\n")
+				c.Writer.Flush()
+			}
+		}
+	})
+}
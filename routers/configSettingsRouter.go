@@ -0,0 +1,101 @@
+package routers
+
+import (
+	"config/models"
+	"config/repositories"
+	"config/utilities"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterConfigSettings(configSettingsGroup *gin.RouterGroup, repo repositories.ConfigSettingsRepository, permissionsHelper *utilities.PermissionsHelper) {
+	configSettingsGroup.GET("/", func(c *gin.Context) {
+		permissionsResult := checkPermissions(c, "config-view", permissionsHelper)
+		if permissionsResult != http.StatusOK {
+			abortWithProblem(c, permissionsResult, "")
+			return
+		}
+		settings, err := repo.GetMany(c.Request.Context())
+		if err != nil {
+			abortWithError(c, err, "error retrieving config settings")
+			return
+		}
+		c.JSON(http.StatusOK, settings)
+	})
+	configSettingsGroup.GET("/:name", func(c *gin.Context) {
+		permissionsResult := checkPermissions(c, "config-view", permissionsHelper)
+		if permissionsResult != http.StatusOK {
+			abortWithProblem(c, permissionsResult, "")
+			return
+		}
+		values, err := repo.GetOne(c.Request.Context(), c.Param("name"))
+		if err != nil {
+			abortWithError(c, err, "error retrieving config setting")
+			return
+		}
+		if values == nil {
+			abortWithProblem(c, http.StatusNotFound, fmt.Sprintf("config setting '%v' not found", c.Param("name")))
+			return
+		}
+		c.JSON(http.StatusOK, models.ConfigSetting{Name: c.Param("name"), SettingValues: *values})
+	})
+	configSettingsGroup.PUT("/:name", func(c *gin.Context) {
+		permissionsResult := checkPermissions(c, "config-edit", permissionsHelper)
+		if permissionsResult != http.StatusOK {
+			abortWithProblem(c, permissionsResult, "")
+			return
+		}
+		var setting models.ConfigSetting
+		if !bindJSON(c, &setting) {
+			return
+		}
+		if setting.Name == "" {
+			setting.Name = c.Param("name")
+		}
+		if setting.Name != c.Param("name") {
+			abortWithProblem(c, http.StatusBadRequest, "config setting name in request body does not match URL")
+			return
+		}
+		if setting.SettingValues == nil {
+			setting.SettingValues = []string{}
+		}
+		if err := repo.Upsert(c.Request.Context(), setting.Name, setting.SettingValues, currentUserID(c)); err != nil {
+			abortWithError(c, err, "error saving config setting")
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+	configSettingsGroup.PATCH("/:name", func(c *gin.Context) {
+		permissionsResult := checkPermissions(c, "config-edit", permissionsHelper)
+		if permissionsResult != http.StatusOK {
+			abortWithProblem(c, permissionsResult, "")
+			return
+		}
+		patch, ok := readPatch(c)
+		if !ok {
+			return
+		}
+		setting, err := repo.Modify(c.Request.Context(), c.Param("name"), func(setting *models.ConfigSetting) error {
+			return applyPatch(patch, setting)
+		}, currentUserID(c))
+		if err != nil {
+			abortWithError(c, err, "error patching config setting")
+			return
+		}
+		c.JSON(http.StatusOK, setting)
+	})
+	configSettingsGroup.DELETE("/:name", func(c *gin.Context) {
+		permissionsResult := checkPermissions(c, "config-delete", permissionsHelper)
+		if permissionsResult != http.StatusOK {
+			abortWithProblem(c, permissionsResult, "")
+			return
+		}
+		if err := repo.Delete(c.Request.Context(), c.Param("name"), currentUserID(c)); err != nil {
+			abortWithError(c, err, "error deleting config setting")
+			return
+		}
+		c.Status(http.StatusNoContent)
+	})
+}
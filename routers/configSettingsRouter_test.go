@@ -0,0 +1,32 @@
+package routers_test
+
+import (
+	"config/models"
+	"config/testharness"
+	"net/http"
+	"testing"
+)
+
+func TestConfigSettingsRoutes(t *testing.T) {
+	harness := testharness.New(t)
+	harness.Bootstrap()
+	admin := harness.AsUser("admin", "config-view", "config-edit", "config-delete")
+
+	recorder := admin.Get("/config-settings/")
+	testharness.ExpectStatus(t, recorder, http.StatusOK)
+	if settings := testharness.Decode[[]models.ConfigSetting](t, recorder); len(settings) == 0 {
+		t.Fatal("expected bootstrapped config settings")
+	}
+
+	testharness.ExpectStatus(t, admin.Put("/config-settings/Shifts", models.ConfigSetting{SettingValues: []string{"Day", "Night"}}), http.StatusOK)
+	recorder = admin.Get("/config-settings/Shifts")
+	testharness.ExpectStatus(t, recorder, http.StatusOK)
+	if setting := testharness.Decode[models.ConfigSetting](t, recorder); setting.Name != "Shifts" || len(setting.SettingValues) != 2 {
+		t.Fatalf("expected saved setting, got %+v", setting)
+	}
+	testharness.ExpectStatus(t, admin.Put("/config-settings/Shifts", models.ConfigSetting{Name: "Other"}), http.StatusBadRequest)
+
+	testharness.ExpectStatus(t, admin.Delete("/config-settings/Shifts"), http.StatusNoContent)
+	testharness.ExpectStatus(t, admin.Get("/config-settings/Shifts"), http.StatusNotFound)
+	testharness.ExpectStatus(t, admin.Delete("/config-settings/Shifts"), http.StatusNotFound)
+}
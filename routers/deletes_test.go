@@ -0,0 +1,26 @@
+package routers_test
+
+import (
+	"config/models"
+	"config/testharness"
+	"net/http"
+	"testing"
+)
+
+func TestProductAndTestDeletes(t *testing.T) {
+	harness := testharness.New(t)
+	editor := harness.AsUser("editor", "product-view", "product-create", "product-delete", "test-view", "test-create", "test-delete")
+	reader := harness.AsUser("reader", "product-view", "test-view")
+
+	testharness.ExpectStatus(t, editor.Post("/products/", models.Product{ProductCode: "P-100", Description: "Plain weave"}), http.StatusCreated)
+	testharness.ExpectStatus(t, reader.Delete("/products/P-100"), http.StatusForbidden)
+	testharness.ExpectStatus(t, editor.Delete("/products/P-100"), http.StatusNoContent)
+	testharness.ExpectStatus(t, editor.Get("/products/P-100"), http.StatusNotFound)
+	testharness.ExpectStatus(t, editor.Delete("/products/P-100"), http.StatusNotFound)
+
+	createTests(t, editor, models.Test{TestName: "Elongation", UnitType: "linear", References: []string{}, Standards: []string{}, AvailableModifiers: []string{}})
+	testharness.ExpectStatus(t, reader.Delete("/tests/Elongation"), http.StatusForbidden)
+	testharness.ExpectStatus(t, editor.Delete("/tests/Elongation"), http.StatusNoContent)
+	testharness.ExpectStatus(t, editor.Get("/tests/Elongation"), http.StatusNotFound)
+	testharness.ExpectStatus(t, editor.Delete("/tests/Elongation"), http.StatusNotFound)
+}
@@ -0,0 +1,144 @@
+package routers
+
+import (
+	"config/models"
+	"config/repositories"
+	"config/utilities"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	exportFormatNDJSON  = "ndjson"
+	exportListSeparator = "; "
+)
+
+var (
+	productExportColumns = []string{"id", "productCode", "description", "createdBy", "updatedBy"}
+	testExportColumns    = []string{"id", "testName", "unitType", "references", "standards", "availableModifiers", "createdBy", "updatedBy"}
+	unitExportColumns    = []string{"id", "fullName", "fullNamePlural", "abbreviation", "measurementSystem", "unitType", "createdBy", "updatedBy"}
+)
+
+// RegisterExports serves exports under their own prefix, so no product code,
+// test name or unit name can be shadowed by them.
+func RegisterExports(exportsGroup *gin.RouterGroup, productsRepo repositories.ProductsRepository, testsRepo repositories.TestsRepository, unitsRepo repositories.UnitsRepository, permissionsHelper *utilities.PermissionsHelper) {
+	exportsGroup.GET("/products", func(c *gin.Context) {
+		permissionsResult := checkPermissions(c, "product-search", permissionsHelper)
+		if permissionsResult != http.StatusOK {
+			abortWithProblem(c, permissionsResult, "")
+			return
+		}
+		writeExport(c, "products", productExportColumns, productExportRow, productsRepo.ForEach)
+	})
+	exportsGroup.GET("/tests", func(c *gin.Context) {
+		permissionsResult := checkPermissions(c, "test-search", permissionsHelper)
+		if permissionsResult != http.StatusOK {
+			abortWithProblem(c, permissionsResult, "")
+			return
+		}
+		criteria := parseTestCriteria(c)
+		writeExport(c, "tests", testExportColumns, testExportRow, func(ctx context.Context, fn func(test *models.Test) error) error {
+			return testsRepo.ForEach(ctx, &criteria, fn)
+		})
+	})
+	exportsGroup.GET("/units", func(c *gin.Context) {
+		permissionsResult := checkPermissions(c, "unit-search", permissionsHelper)
+		if permissionsResult != http.StatusOK {
+			abortWithProblem(c, permissionsResult, "")
+			return
+		}
+		writeExport(c, "units", unitExportColumns, unitExportRow, unitsRepo.ForEach)
+	})
+}
+
+func productExportRow(product *models.Product) []string {
+	return []string{product.ID, product.ProductCode, product.Description, product.CreatedBy, product.UpdatedBy}
+}
+
+func testExportRow(test *models.Test) []string {
+	return []string{
+		test.ID,
+		test.TestName,
+		test.UnitType,
+		strings.Join(test.References, exportListSeparator),
+		strings.Join(test.Standards, exportListSeparator),
+		strings.Join(test.AvailableModifiers, exportListSeparator),
+		test.CreatedBy,
+		test.UpdatedBy,
+	}
+}
+
+func unitExportRow(unit *models.Unit) []string {
+	return []string{unit.ID, unit.FullName, unit.FullNamePlural, unit.Abbreviation, unit.MeasurementSystem, unit.UnitType, unit.CreatedBy, unit.UpdatedBy}
+}
+
+// writeExport streams items to the response as they are read. Once the first
+// bytes are on the wire a failure can only cut the response short, so errors
+// after that point are logged rather than reported as a problem.
+func writeExport[T any](c *gin.Context, name string, columns []string, row func(item *T) []string, forEach func(ctx context.Context, fn func(item *T) error) error) {
+	format := c.DefaultQuery("format", utilities.TableFormatCSV)
+	var contentType string
+	switch format {
+	case utilities.TableFormatCSV:
+		contentType = "text/csv; charset=utf-8"
+	case utilities.TableFormatXLSX:
+		contentType = xlsxContentType
+	case exportFormatNDJSON:
+		contentType = "application/x-ndjson"
+	default:
+		log.Ctx(c.Request.Context()).Warn().Msgf("unsupported export format '%v'", format)
+		abortWithProblem(c, http.StatusBadRequest, fmt.Sprintf("format must be csv, ndjson or xlsx, not '%v'", format))
+		return
+	}
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%v.%v"`, name, format))
+	c.Status(http.StatusOK)
+
+	var write func(item *T) error
+	var finish func() error
+	var discard func()
+	if format == exportFormatNDJSON {
+		encoder := json.NewEncoder(c.Writer)
+		write = func(item *T) error { return encoder.Encode(item) }
+		finish = func() error { return nil }
+		discard = func() {}
+	} else {
+		tableWriter, err := utilities.NewTableWriter(c.Writer, format, columns)
+		if err != nil {
+			c.Writer.Header().Del("Content-Disposition")
+			abortWithError(c, err, "error exporting "+name)
+			return
+		}
+		write = func(item *T) error { return tableWriter.WriteRow(row(item)) }
+		finish = tableWriter.Close
+		discard = tableWriter.Discard
+	}
+
+	count := 0
+	err := forEach(c.Request.Context(), func(item *T) error {
+		count++
+		return write(item)
+	})
+	if err != nil {
+		discard()
+	} else {
+		err = finish()
+	}
+	if err != nil {
+		if !c.Writer.Written() {
+			c.Writer.Header().Del("Content-Disposition")
+			abortWithError(c, err, "error exporting "+name)
+			return
+		}
+		log.Ctx(c.Request.Context()).Error().Err(err).Msgf("export of %v cut short after %v rows", name, count)
+		c.Abort()
+		return
+	}
+	log.Ctx(c.Request.Context()).Info().Msgf("%v %v exported as %v", count, name, format)
+}
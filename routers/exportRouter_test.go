@@ -0,0 +1,147 @@
+package routers_test
+
+import (
+	"bytes"
+	"config/models"
+	"config/testharness"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func TestTestsExport(t *testing.T) {
+	harness := testharness.New(t)
+	harness.Bootstrap()
+	editor := harness.AsUser("editor", "test-create", "test-view", "test-search", "test-import")
+	createTests(t, editor,
+		models.Test{TestName: "Tensile Strength", UnitType: "pressure", References: []string{"ref a", "ref b"}, Standards: []string{"ASTM D5035"}, AvailableModifiers: []string{"warp", "fill"}},
+		models.Test{TestName: "Thickness", UnitType: "linear", References: []string{}, Standards: []string{}, AvailableModifiers: []string{}},
+		models.Test{TestName: "Tear Strength", UnitType: "pressure", References: []string{}, Standards: []string{}, AvailableModifiers: []string{}},
+	)
+
+	recorder := editor.Get("/exports/tests?unitType=pressure")
+	testharness.ExpectStatus(t, recorder, http.StatusOK)
+	tear := testharness.Decode[models.Test](t, editor.Get("/tests/Tear%20Strength"))
+	tensile := testharness.Decode[models.Test](t, editor.Get("/tests/Tensile%20Strength"))
+	expected := "id,testName,unitType,references,standards,availableModifiers,createdBy,updatedBy\n" +
+		tear.ID + ",Tear Strength,pressure,,,,editor,editor\n" +
+		tensile.ID + ",Tensile Strength,pressure,ref a; ref b,ASTM D5035,warp; fill,editor,editor\n"
+	if recorder.Body.String() != expected {
+		t.Fatalf("unexpected csv export:\n%v", recorder.Body.String())
+	}
+	if disposition := recorder.Header().Get("Content-Disposition"); disposition != `attachment; filename="tests.csv"` {
+		t.Fatalf("unexpected Content-Disposition %q", disposition)
+	}
+	csv := recorder.Body.String()
+
+	recorder = editor.Get("/exports/tests?format=ndjson&namePattern=thick")
+	testharness.ExpectStatus(t, recorder, http.StatusOK)
+	lines := strings.Split(strings.TrimSpace(recorder.Body.String()), "\n")
+	var test models.Test
+	if len(lines) != 1 || json.Unmarshal([]byte(lines[0]), &test) != nil || test.TestName != "Thickness" {
+		t.Fatalf("unexpected ndjson export:\n%v", recorder.Body.String())
+	}
+
+	recorder = editor.Get("/exports/tests?format=xlsx")
+	testharness.ExpectStatus(t, recorder, http.StatusOK)
+	workbook, err := excelize.OpenReader(bytes.NewReader(recorder.Body.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := workbook.GetRows(workbook.GetSheetName(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 4 || rows[2][0] != tensile.ID || rows[2][1] != "Tensile Strength" || rows[2][5] != "warp; fill" {
+		t.Fatalf("unexpected xlsx rows %v", rows)
+	}
+
+	testharness.ExpectStatus(t, editor.Get("/exports/tests?format=pdf"), http.StatusBadRequest)
+
+	recorder = editor.WithHeader("Content-Type", "text/csv").Post("/tests/import?writeMode=upsert", csv)
+	testharness.ExpectStatus(t, recorder, http.StatusOK)
+	if report := testharness.Decode[models.ImportReport](t, recorder); report.Updated != 2 {
+		t.Fatalf("expected an export to import back as updates, got %+v", report)
+	}
+}
+
+func TestProductsAndUnitsExport(t *testing.T) {
+	harness := testharness.New(t)
+	harness.Bootstrap()
+	caller := harness.AsUser("reader", "product-create", "product-view", "product-search", "unit-search")
+	testharness.ExpectStatus(t, caller.Post("/products/", models.Product{ProductCode: "P-100", Description: "Plain, weave"}), http.StatusCreated)
+
+	product := testharness.Decode[models.Product](t, caller.Get("/products/P-100"))
+
+	recorder := caller.Get("/exports/products")
+	testharness.ExpectStatus(t, recorder, http.StatusOK)
+	if recorder.Body.String() != "id,productCode,description,createdBy,updatedBy\n"+product.ID+",P-100,\"Plain, weave\",reader,reader\n" {
+		t.Fatalf("unexpected products export:\n%v", recorder.Body.String())
+	}
+
+	recorder = caller.Get("/exports/units?format=ndjson")
+	testharness.ExpectStatus(t, recorder, http.StatusOK)
+	if lines := strings.Count(recorder.Body.String(), "\n"); lines < 10 {
+		t.Fatalf("expected every bootstrapped unit, got %v lines", lines)
+	}
+}
+
+func TestKeysNamedLikeExportsStayReachable(t *testing.T) {
+	harness := testharness.New(t)
+	editor := harness.AsUser("editor", "product-create", "product-view", "test-create", "test-view", "unit-create", "unit-view")
+	testharness.ExpectStatus(t, editor.Post("/products/", models.Product{ProductCode: "export", Description: "Export grade"}), http.StatusCreated)
+	createTests(t, editor, models.Test{TestName: "export", UnitType: "pressure", References: []string{}, Standards: []string{}, AvailableModifiers: []string{}})
+	testharness.ExpectStatus(t, editor.Post("/units/", models.Unit{FullName: "export", FullNamePlural: "exports", Abbreviation: "ex", MeasurementSystem: "Imperial", UnitType: "Length"}), http.StatusCreated)
+
+	if product := testharness.Decode[models.Product](t, editor.Get("/products/export")); product.Description != "Export grade" {
+		t.Fatalf("expected the product named export, got %+v", product)
+	}
+	if test := testharness.Decode[models.Test](t, editor.Get("/tests/export")); test.UnitType != "pressure" {
+		t.Fatalf("expected the test named export, got %+v", test)
+	}
+	if unit := testharness.Decode[models.Unit](t, editor.Get("/units/export")); unit.Abbreviation != "ex" {
+		t.Fatalf("expected the unit named export, got %+v", unit)
+	}
+}
+
+func TestExportsDoNotWriteFormulas(t *testing.T) {
+	harness := testharness.New(t)
+	caller := harness.AsUser("editor", "product-create", "product-view", "product-search", "product-import")
+	testharness.ExpectStatus(t, caller.Post("/products/", models.Product{ProductCode: "=HYPERLINK(\"https://example.com\")", Description: "-40"}), http.StatusCreated)
+	testharness.ExpectStatus(t, caller.Post("/products/", models.Product{ProductCode: "@SUM(A1)", Description: "+cmd"}), http.StatusCreated)
+
+	recorder := caller.Get("/exports/products")
+	testharness.ExpectStatus(t, recorder, http.StatusOK)
+	csv := recorder.Body.String()
+	if !containsAll(csv, `"'=HYPERLINK(""https://example.com"")",-40,`, "'@SUM(A1),'+cmd,") {
+		t.Fatalf("expected formulas to be escaped, got:\n%v", csv)
+	}
+
+	recorder = caller.Get("/exports/products?format=xlsx")
+	testharness.ExpectStatus(t, recorder, http.StatusOK)
+	workbook, err := excelize.OpenReader(bytes.NewReader(recorder.Body.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sheet := workbook.GetSheetName(0)
+	for _, cell := range []string{"B2", "B3", "C3"} {
+		if formula, err := workbook.GetCellFormula(sheet, cell); err != nil || formula != "" {
+			t.Fatalf("expected %v to hold no formula, got %q (%v)", cell, formula, err)
+		}
+	}
+	if value, _ := workbook.GetCellValue(sheet, "B3"); value != "@SUM(A1)" {
+		t.Fatalf("expected the xlsx value unescaped, got %q", value)
+	}
+
+	recorder = caller.WithHeader("Content-Type", "text/csv").Post("/products/import?writeMode=upsert", csv)
+	testharness.ExpectStatus(t, recorder, http.StatusOK)
+	if report := testharness.Decode[models.ImportReport](t, recorder); report.Updated != 2 || report.Created != 0 {
+		t.Fatalf("expected the escaped export to import back as updates, got %+v", report)
+	}
+	if product := testharness.Decode[models.Product](t, caller.Get("/products/@SUM(A1)")); product.Description != "+cmd" {
+		t.Fatalf("expected the import to restore the original values, got %+v", product)
+	}
+}
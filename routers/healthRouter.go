@@ -0,0 +1,32 @@
+package routers
+
+import (
+	"config/utilities"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// RegisterHealth reports not ready as soon as shuttingDown is closed, so load
+// balancers stop routing new requests while in-flight ones drain.
+func RegisterHealth(r *gin.Engine, healthChecker *utilities.HealthChecker, shuttingDown <-chan struct{}) {
+	r.GET("/healthz", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	})
+	r.GET("/readyz", func(c *gin.Context) {
+		select {
+		case <-shuttingDown:
+			c.JSON(http.StatusServiceUnavailable, utilities.ReadinessReport{Status: "shutting down", Dependencies: []utilities.DependencyStatus{}})
+			return
+		default:
+		}
+		report := healthChecker.CheckReadiness(c.Request.Context())
+		if report.Status != "ready" {
+			log.Ctx(c.Request.Context()).Warn().Interface("dependencies", report.Dependencies).Msg("readiness check failed")
+			c.JSON(http.StatusServiceUnavailable, report)
+			return
+		}
+		c.JSON(http.StatusOK, report)
+	})
+}
@@ -0,0 +1,43 @@
+package routers_test
+
+import (
+	"config/testharness"
+	"config/utilities"
+	"net/http"
+	"testing"
+)
+
+func TestHealthRoutes(t *testing.T) {
+	harness := testharness.New(t)
+	anonymous := harness.Anonymous()
+
+	testharness.ExpectStatus(t, anonymous.Get("/healthz"), http.StatusOK)
+
+	recorder := anonymous.Get("/readyz")
+	testharness.ExpectStatus(t, recorder, http.StatusOK)
+	report := testharness.Decode[utilities.ReadinessReport](t, recorder)
+	if report.Status != "ready" || len(report.Dependencies) != 2 {
+		t.Fatalf("unexpected readiness report %+v", report)
+	}
+
+	harness.Auth.Close()
+	recorder = anonymous.Get("/readyz")
+	testharness.ExpectStatus(t, recorder, http.StatusOK)
+	report = testharness.Decode[utilities.ReadinessReport](t, recorder)
+	if report.Status != "ready" || report.Dependencies[1].Name != "auth" || report.Dependencies[1].Status != "down" {
+		t.Fatalf("expected a ready report with auth down, got %+v", report)
+	}
+}
+
+func TestReadinessFailsOnceShuttingDown(t *testing.T) {
+	harness := testharness.New(t)
+	anonymous := harness.Anonymous()
+
+	harness.BeginShutdown()
+	recorder := anonymous.Get("/readyz")
+	testharness.ExpectStatus(t, recorder, http.StatusServiceUnavailable)
+	if report := testharness.Decode[utilities.ReadinessReport](t, recorder); report.Status != "shutting down" {
+		t.Fatalf("expected a shutting down report, got %+v", report)
+	}
+	testharness.ExpectStatus(t, anonymous.Get("/healthz"), http.StatusOK)
+}
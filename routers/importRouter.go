@@ -0,0 +1,116 @@
+package routers
+
+import (
+	"config/models"
+	"config/utilities"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	maxImportBytes  = 32 << 20
+	xlsxContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+)
+
+type importFunc func(ctx context.Context, table *utilities.Table, options utilities.ImportOptions) (*models.ImportReport, error)
+
+func RegisterImports(r *gin.Engine, importer *utilities.Importer, permissionsHelper *utilities.PermissionsHelper) {
+	r.POST("/products/import", importHandler("product-import", importer.ImportProducts, permissionsHelper))
+	r.POST("/tests/import", importHandler("test-import", importer.ImportTests, permissionsHelper))
+}
+
+func importHandler(permission string, importRows importFunc, permissionsHelper *utilities.PermissionsHelper) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		permissionsResult := checkPermissions(c, permission, permissionsHelper)
+		if permissionsResult != http.StatusOK {
+			abortWithProblem(c, permissionsResult, "")
+			return
+		}
+		options := utilities.ImportOptions{
+			Mode:      c.DefaultQuery("mode", models.ImportModeAllOrNothing),
+			WriteMode: c.DefaultQuery("writeMode", models.ImportWriteModeInsert),
+			Columns:   c.QueryMap("columns"),
+			By:        currentUserID(c),
+		}
+		if dryRun := c.Query("dryRun"); dryRun != "" {
+			parsed, err := strconv.ParseBool(dryRun)
+			if err != nil {
+				abortWithProblem(c, http.StatusBadRequest, "Unable to parse dryRun value of '"+dryRun+"' as bool")
+				return
+			}
+			options.DryRun = parsed
+		}
+		if batchSize := c.Query("batchSize"); batchSize != "" {
+			parsed, err := strconv.Atoi(batchSize)
+			if err != nil {
+				abortWithProblem(c, http.StatusBadRequest, "Unable to parse batchSize value of '"+batchSize+"' as int")
+				return
+			}
+			options.BatchSize = parsed
+		}
+		table, err := readImportTable(c)
+		if err == nil {
+			var report *models.ImportReport
+			if report, err = importRows(c.Request.Context(), table, options); err == nil {
+				status := http.StatusOK
+				if !report.Committed && !report.DryRun {
+					status = http.StatusUnprocessableEntity
+				}
+				log.Ctx(c.Request.Context()).Info().Msgf("import of %v rows: %v created, %v updated, %v failed", report.TotalRows, report.Created, report.Updated, report.Failed)
+				c.JSON(status, report)
+				return
+			}
+			if report != nil {
+				log.Ctx(c.Request.Context()).Error().Err(err).Msgf("import stopped after %v created, %v updated (committed: %v)", report.Created, report.Updated, report.Committed)
+				c.AbortWithStatusJSON(http.StatusInternalServerError, report)
+				return
+			}
+		}
+		if errors.Is(err, utilities.ErrInvalidImportFile) {
+			log.Ctx(c.Request.Context()).Warn().Err(err).Msg("import file rejected")
+			abortWithProblem(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		abortWithError(c, err, "error importing rows")
+	}
+}
+
+func readImportTable(c *gin.Context) (*utilities.Table, error) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportBytes)
+	format := c.Query("format")
+	var body io.Reader = c.Request.Body
+	switch c.ContentType() {
+	case "multipart/form-data":
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", utilities.ErrInvalidImportFile, err)
+		}
+		file, err := fileHeader.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		body = file
+		if format == "" {
+			format = strings.TrimPrefix(strings.ToLower(filepath.Ext(fileHeader.Filename)), ".")
+		}
+	case "text/csv":
+		if format == "" {
+			format = utilities.TableFormatCSV
+		}
+	case xlsxContentType:
+		if format == "" {
+			format = utilities.TableFormatXLSX
+		}
+	}
+	return utilities.ReadTable(body, format, c.Query("sheet"))
+}
@@ -0,0 +1,113 @@
+package routers_test
+
+import (
+	"bytes"
+	"config/models"
+	"config/testharness"
+	"mime/multipart"
+	"net/http"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func TestProductsImport(t *testing.T) {
+	harness := testharness.New(t)
+	importer := harness.AsUser("importer", "product-import", "product-view").WithHeader("Content-Type", "text/csv")
+	csv := "Code,Description\nP-100,Plain weave\n\nP-200,Twill weave\n"
+
+	recorder := importer.Post("/products/import?dryRun=true&columns[productCode]=Code", csv)
+	testharness.ExpectStatus(t, recorder, http.StatusOK)
+	if report := testharness.Decode[models.ImportReport](t, recorder); report.Committed || report.TotalRows != 2 || report.Created != 2 {
+		t.Fatalf("unexpected dry run report %+v", report)
+	}
+	testharness.ExpectStatus(t, importer.Get("/products/P-100"), http.StatusNotFound)
+
+	testharness.ExpectStatus(t, importer.Post("/products/import?columns[productCode]=Code", csv), http.StatusOK)
+	recorder = importer.Post("/products/import?columns[productCode]=Code", "Code,Description\nP-100,Satin weave\nP-300,Basket weave\n")
+	testharness.ExpectStatus(t, recorder, http.StatusUnprocessableEntity)
+	if report := testharness.Decode[models.ImportReport](t, recorder); report.Committed || report.Failed != 1 || report.Errors[0].Key != "P-100" {
+		t.Fatalf("expected an insert-only import to reject the existing product, got %+v", report)
+	}
+	if product := testharness.Decode[models.Product](t, importer.Get("/products/P-100")); product.Description != "Plain weave" {
+		t.Fatalf("expected the existing product to be left alone, got %+v", product)
+	}
+	recorder = importer.Post("/products/import?writeMode=upsert&columns[productCode]=Code", "Code,Description\nP-100,Satin weave\nP-300,Basket weave\n")
+	testharness.ExpectStatus(t, recorder, http.StatusOK)
+	if report := testharness.Decode[models.ImportReport](t, recorder); !report.Committed || report.Created != 1 || report.Updated != 1 {
+		t.Fatalf("unexpected upsert report %+v", report)
+	}
+	if product := testharness.Decode[models.Product](t, importer.Get("/products/P-100")); product.Description != "Satin weave" || product.UpdatedBy != "importer" {
+		t.Fatalf("expected updated product, got %+v", product)
+	}
+
+	testharness.ExpectStatus(t, importer.Post("/products/import", csv), http.StatusBadRequest)
+	testharness.ExpectStatus(t, importer.Post("/products/import?mode=sometimes&columns[productCode]=Code", csv), http.StatusBadRequest)
+	testharness.ExpectStatus(t, importer.Post("/products/import?writeMode=replace&columns[productCode]=Code", csv), http.StatusBadRequest)
+}
+
+func TestTestsImportModes(t *testing.T) {
+	harness := testharness.New(t)
+	harness.Bootstrap()
+	importer := harness.AsUser("importer", "test-import", "test-view").WithHeader("Content-Type", "text/csv")
+	csv := "testName,unitType,availableModifiers,standards\n" +
+		"Thickness,linear,left; right,ASTM D1777\n" +
+		"Weight,furlongs,,\n" +
+		",linear,sideways,\n" +
+		"Thickness,linear,,\n"
+
+	recorder := importer.Post("/tests/import", csv)
+	testharness.ExpectStatus(t, recorder, http.StatusUnprocessableEntity)
+	report := testharness.Decode[models.ImportReport](t, recorder)
+	if report.Committed || report.Failed != 3 || report.Created != 0 {
+		t.Fatalf("unexpected all-or-nothing report %+v", report)
+	}
+	expected := map[int]string{3: "unknown unit type 'furlongs'", 4: "testName is required", 5: "duplicate of row 2"}
+	for _, rowError := range report.Errors {
+		if message, found := expected[rowError.Row]; found && message == rowError.Message {
+			delete(expected, rowError.Row)
+		}
+	}
+	if len(expected) > 0 {
+		t.Fatalf("missing row errors %v in %+v", expected, report.Errors)
+	}
+	testharness.ExpectStatus(t, importer.Get("/tests/Thickness"), http.StatusNotFound)
+
+	recorder = importer.Post("/tests/import?mode=best-effort&batchSize=1", csv)
+	testharness.ExpectStatus(t, recorder, http.StatusOK)
+	if report := testharness.Decode[models.ImportReport](t, recorder); !report.Committed || report.Created != 1 || report.Failed != 3 {
+		t.Fatalf("unexpected best-effort report %+v", report)
+	}
+	test := testharness.Decode[models.Test](t, importer.Get("/tests/Thickness"))
+	if len(test.AvailableModifiers) != 2 || test.AvailableModifiers[1] != "right" || test.Standards[0] != "ASTM D1777" {
+		t.Fatalf("unexpected imported test %+v", test)
+	}
+}
+
+func TestTestsImportFromXLSXUpload(t *testing.T) {
+	harness := testharness.New(t)
+	harness.Bootstrap()
+	workbook := excelize.NewFile()
+	for i, row := range [][]interface{}{{"Test Name", "Unit Type"}, {"Pressure Drop", "pressure"}} {
+		cell, _ := excelize.CoordinatesToCellName(1, i+1)
+		if err := workbook.SetSheetRow("Sheet1", cell, &row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", "tests.xlsx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := workbook.Write(part); err != nil {
+		t.Fatal(err)
+	}
+	form.Close()
+
+	importer := harness.AsUser("importer", "test-import", "test-view").WithHeader("Content-Type", form.FormDataContentType())
+	testharness.ExpectStatus(t, importer.Post("/tests/import", body.Bytes()), http.StatusOK)
+	if test := testharness.Decode[models.Test](t, importer.Get("/tests/Pressure%20Drop")); test.UnitType != "pressure" || test.References == nil {
+		t.Fatalf("unexpected imported test %+v", test)
+	}
+}
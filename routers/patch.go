@@ -0,0 +1,75 @@
+package routers
+
+import (
+	"bytes"
+	"config/repositories"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	mergePatchContentType = "application/merge-patch+json"
+	jsonPatchContentType  = "application/json-patch+json"
+	maxPatchBytes         = 1 << 20
+)
+
+type patcher func(document []byte) ([]byte, error)
+
+// readPatch parses the request body up front so a malformed patch is a 400
+// before any row is locked. Merge patches replace arrays wholesale; JSON
+// patches can add or remove single elements, e.g. /availableModifiers/-.
+func readPatch(c *gin.Context) (patcher, bool) {
+	contentType := c.ContentType()
+	if contentType != mergePatchContentType && contentType != jsonPatchContentType {
+		log.Ctx(c.Request.Context()).Warn().Msgf("unsupported patch content type '%v'", contentType)
+		c.Header("Accept-Patch", mergePatchContentType+", "+jsonPatchContentType)
+		abortWithProblem(c, http.StatusUnsupportedMediaType, fmt.Sprintf("patch must be %v or %v", mergePatchContentType, jsonPatchContentType))
+		return nil, false
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxPatchBytes))
+	if err != nil {
+		abortWithProblem(c, http.StatusBadRequest, "unable to read patch: "+err.Error())
+		return nil, false
+	}
+	if contentType == mergePatchContentType {
+		if !json.Valid(body) || !bytes.HasPrefix(bytes.TrimSpace(body), []byte("{")) {
+			abortWithProblem(c, http.StatusBadRequest, "merge patch must be a JSON object")
+			return nil, false
+		}
+		return func(document []byte) ([]byte, error) { return jsonpatch.MergePatch(document, body) }, true
+	}
+	patch, err := jsonpatch.DecodePatch(body)
+	if err != nil {
+		abortWithProblem(c, http.StatusBadRequest, "invalid JSON patch: "+err.Error())
+		return nil, false
+	}
+	return patch.Apply, true
+}
+
+// applyPatch patches the JSON form of item and decodes the result back into
+// it. Failed "test" operations, bad paths and fields that do not exist on the
+// resource are reported as validation failures.
+func applyPatch[T any](patch patcher, item *T) error {
+	document, err := json.Marshal(item)
+	if err != nil {
+		return err
+	}
+	patched, err := patch(document)
+	if err != nil {
+		return fmt.Errorf("%w: %v", repositories.ErrValidationFailed, err)
+	}
+	var updated T
+	decoder := json.NewDecoder(bytes.NewReader(patched))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&updated); err != nil {
+		return fmt.Errorf("%w: %v", repositories.ErrValidationFailed, err)
+	}
+	*item = updated
+	return nil
+}
@@ -0,0 +1,79 @@
+package routers_test
+
+import (
+	"config/models"
+	"config/testharness"
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestPatchTest(t *testing.T) {
+	harness := testharness.New(t)
+	editor := harness.AsUser("editor", "test-view", "test-create", "test-edit")
+	createTests(t, editor, models.Test{TestName: "Thickness", UnitType: "linear", References: []string{}, Standards: []string{"ASTM A480"}, AvailableModifiers: []string{"left", "right"}})
+	mergePatch := editor.WithHeader("Content-Type", "application/merge-patch+json")
+	jsonPatch := editor.WithHeader("Content-Type", "application/json-patch+json")
+	ctx := context.Background()
+
+	recorder := jsonPatch.Do(ctx, http.MethodPatch, "/tests/Thickness", `[{"op": "add", "path": "/availableModifiers/-", "value": "center"}]`)
+	testharness.ExpectStatus(t, recorder, http.StatusOK)
+	if patched := testharness.Decode[models.Test](t, recorder); !reflect.DeepEqual(patched.AvailableModifiers, []string{"left", "right", "center"}) {
+		t.Fatalf("expected appended modifier to keep existing ones, got %+v", patched)
+	}
+
+	recorder = mergePatch.Do(ctx, http.MethodPatch, "/tests/Thickness", `{"unitType": "area"}`)
+	testharness.ExpectStatus(t, recorder, http.StatusOK)
+	patched := testharness.Decode[models.Test](t, editor.Get("/tests/Thickness"))
+	if patched.UnitType != "area" || len(patched.AvailableModifiers) != 3 || patched.Standards[0] != "ASTM A480" {
+		t.Fatalf("expected merge patch to change only unitType, got %+v", patched)
+	}
+
+	testharness.ExpectStatus(t, jsonPatch.Do(ctx, http.MethodPatch, "/tests/Thickness", `[{"op": "test", "path": "/unitType", "value": "linear"}, {"op": "replace", "path": "/unitType", "value": "volume"}]`), http.StatusUnprocessableEntity)
+	testharness.ExpectStatus(t, mergePatch.Do(ctx, http.MethodPatch, "/tests/Thickness", `{"testName": "Gauge"}`), http.StatusUnprocessableEntity)
+	testharness.ExpectStatus(t, mergePatch.Do(ctx, http.MethodPatch, "/tests/Thickness", `{"colour": "red"}`), http.StatusUnprocessableEntity)
+	testharness.ExpectStatus(t, mergePatch.Do(ctx, http.MethodPatch, "/tests/Thickness", `["unitType"]`), http.StatusBadRequest)
+	testharness.ExpectStatus(t, mergePatch.Do(ctx, http.MethodPatch, "/tests/Missing", `{"unitType": "area"}`), http.StatusNotFound)
+
+	recorder = editor.Do(ctx, http.MethodPatch, "/tests/Thickness", `{"unitType": "area"}`)
+	testharness.ExpectStatus(t, recorder, http.StatusUnsupportedMediaType)
+	if accept := recorder.Header().Get("Accept-Patch"); accept == "" {
+		t.Fatal("expected Accept-Patch header on unsupported media type")
+	}
+	if unchanged := testharness.Decode[models.Test](t, editor.Get("/tests/Thickness")); unchanged.UnitType != "area" {
+		t.Fatalf("expected failed patches to leave the test alone, got %+v", unchanged)
+	}
+}
+
+func TestPatchConfigSetting(t *testing.T) {
+	harness := testharness.New(t)
+	harness.Bootstrap()
+	editor := harness.AsUser("editor", "config-view", "config-edit")
+
+	recorder := editor.WithHeader("Content-Type", "application/json-patch+json").Do(context.Background(), http.MethodPatch, "/config-settings/Modifiers", `[{"op": "add", "path": "/settingValues/-", "value": "edge"}]`)
+	testharness.ExpectStatus(t, recorder, http.StatusOK)
+	setting := testharness.Decode[models.ConfigSetting](t, editor.Get("/config-settings/Modifiers"))
+	if len(setting.SettingValues) != 13 || setting.SettingValues[0] != "top" || setting.SettingValues[12] != "edge" {
+		t.Fatalf("expected modifier to be appended, got %+v", setting.SettingValues)
+	}
+}
+
+func TestPatchRejectsAuthorshipChanges(t *testing.T) {
+	harness := testharness.New(t)
+	editor := harness.AsUser("editor", "product-view", "product-create", "product-edit", "test-view", "test-create", "test-edit", "unit-view", "unit-create", "unit-edit")
+	testharness.ExpectStatus(t, editor.Post("/products/", models.Product{ProductCode: "P-100", Description: "Plain weave"}), http.StatusCreated)
+	createTests(t, editor, models.Test{TestName: "Thickness", UnitType: "linear", References: []string{}, Standards: []string{}, AvailableModifiers: []string{}})
+	testharness.ExpectStatus(t, editor.Post("/units/", models.Unit{FullName: "inch", FullNamePlural: "inches", Abbreviation: "in", MeasurementSystem: "Imperial", UnitType: "Length"}), http.StatusCreated)
+	mergePatch := editor.WithHeader("Content-Type", "application/merge-patch+json")
+	ctx := context.Background()
+
+	for _, path := range []string{"/products/P-100", "/tests/Thickness", "/units/inch"} {
+		for _, patch := range []string{`{"createdBy": "someone else"}`, `{"updatedBy": "someone else"}`} {
+			testharness.ExpectStatus(t, mergePatch.Do(ctx, http.MethodPatch, path, patch), http.StatusUnprocessableEntity)
+		}
+	}
+	if product := testharness.Decode[models.Product](t, editor.Get("/products/P-100")); product.CreatedBy != "editor" {
+		t.Fatalf("expected the author to be kept, got %+v", product)
+	}
+}
@@ -10,20 +10,18 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
-func RegisterProducts(productsGroup *gin.RouterGroup, productsRepo *repositories.ProductsRepository, permissionsHelper *utilities.PermissionsHelper) {
+func RegisterProducts(productsGroup *gin.RouterGroup, productsRepo repositories.ProductsRepository, aliasesRepo repositories.AliasesRepository, permissionsHelper *utilities.PermissionsHelper) {
 	productsGroup.GET("/:productCode", func(c *gin.Context) {
 		permissionsResult := checkPermissions(c, "product-view", permissionsHelper)
 		if permissionsResult != http.StatusOK {
-			c.AbortWithStatus(permissionsResult)
+			abortWithProblem(c, permissionsResult, "")
 			return
 		}
-		product, err := productsRepo.GetOne(c.Param("productCode"))
+		product, err := productsRepo.GetOne(c.Request.Context(), c.Param("productCode"))
 		if err != nil {
-			c.AbortWithStatus(http.StatusInternalServerError)
-			return
-		}
-		if product == nil {
-			c.AbortWithStatus(http.StatusNotFound)
+			if !redirectIfRenamed(c, err, aliasesRepo, "products", "productCode") {
+				abortWithError(c, err, "error retrieving product")
+			}
 			return
 		}
 		c.JSON(http.StatusOK, product)
@@ -31,13 +29,12 @@ func RegisterProducts(productsGroup *gin.RouterGroup, productsRepo *repositories
 	productsGroup.GET("/", func(c *gin.Context) {
 		permissionsResult := checkPermissions(c, "product-search", permissionsHelper)
 		if permissionsResult != http.StatusOK {
-			c.AbortWithStatus(permissionsResult)
+			abortWithProblem(c, permissionsResult, "")
 			return
 		}
-		products, err := productsRepo.GetMany()
+		products, err := productsRepo.GetMany(c.Request.Context())
 		if err != nil {
-			log.Error().Err(err).Msg("error retrieving products")
-			c.AbortWithStatus(http.StatusInternalServerError)
+			abortWithError(c, err, "error retrieving products")
 			return
 		}
 		c.JSON(http.StatusOK, products)
@@ -45,18 +42,15 @@ func RegisterProducts(productsGroup *gin.RouterGroup, productsRepo *repositories
 	productsGroup.POST("/", func(c *gin.Context) {
 		permissionsResult := checkPermissions(c, "product-create", permissionsHelper)
 		if permissionsResult != http.StatusOK {
-			c.AbortWithStatus(permissionsResult)
+			abortWithProblem(c, permissionsResult, "")
 			return
 		}
 		var product models.Product
-		if err := c.BindJSON(&product); err != nil {
-			log.Warn().Msg("failed to bind request body to models.Product")
-			c.AbortWithStatus(http.StatusBadRequest)
+		if !bindJSON(c, &product) {
 			return
 		}
-		if err := productsRepo.Create(&product); err != nil {
-			log.Error().Err(err).Msg("Error creating product")
-			c.AbortWithStatus(http.StatusInternalServerError)
+		if err := productsRepo.Create(c.Request.Context(), &product, currentUserID(c)); err != nil {
+			abortWithError(c, err, "error creating product")
 			return
 		}
 		c.Status(http.StatusCreated)
@@ -64,25 +58,84 @@ func RegisterProducts(productsGroup *gin.RouterGroup, productsRepo *repositories
 	productsGroup.PUT("/:productCode", func(c *gin.Context) {
 		permissionsResult := checkPermissions(c, "product-edit", permissionsHelper)
 		if permissionsResult != http.StatusOK {
-			c.AbortWithStatus(permissionsResult)
+			abortWithProblem(c, permissionsResult, "")
 			return
 		}
 		var product models.Product
-		if err := c.BindJSON(&product); err != nil {
-			log.Warn().Msg("failed to bind request body to models.Product")
-			c.AbortWithStatus(http.StatusBadRequest)
+		if !bindJSON(c, &product) {
 			return
 		}
-		if product.ProductCode != c.Param("productCode") {
-			log.Warn().Msg("product code in request body does not match URL")
-			c.AbortWithStatus(http.StatusBadRequest)
+		if repositories.FoldKey(product.ProductCode) != repositories.FoldKey(c.Param("productCode")) {
+			// A PUT that followed a rename redirect still carries the old key.
+			if newKey, ok := resolveRenamed(c.Request.Context(), aliasesRepo, "products", product.ProductCode); ok && repositories.FoldKey(newKey) == repositories.FoldKey(c.Param("productCode")) {
+				product.ProductCode = newKey
+			}
+		}
+		if repositories.FoldKey(product.ProductCode) != repositories.FoldKey(c.Param("productCode")) {
+			log.Ctx(c.Request.Context()).Warn().Msg("product code in request body does not match URL")
+			abortWithProblem(c, http.StatusBadRequest, "product code in request body does not match URL")
 			return
 		}
-		if err := productsRepo.Update(&product); err != nil {
-			log.Error().Err(err).Msgf("Error updating product %s", c.Param("productCode"))
-			c.AbortWithStatus(http.StatusInternalServerError)
+		if err := productsRepo.Update(c.Request.Context(), &product, currentUserID(c)); err != nil {
+			if !redirectIfRenamed(c, err, aliasesRepo, "products", "productCode") {
+				abortWithError(c, err, "error updating product")
+			}
 			return
 		}
 		c.Status(http.StatusOK)
 	})
+	productsGroup.PATCH("/:productCode", func(c *gin.Context) {
+		permissionsResult := checkPermissions(c, "product-edit", permissionsHelper)
+		if permissionsResult != http.StatusOK {
+			abortWithProblem(c, permissionsResult, "")
+			return
+		}
+		patch, ok := readPatch(c)
+		if !ok {
+			return
+		}
+		product, err := productsRepo.Modify(c.Request.Context(), c.Param("productCode"), func(product *models.Product) error {
+			return applyPatch(patch, product)
+		}, currentUserID(c))
+		if err != nil {
+			if !redirectIfRenamed(c, err, aliasesRepo, "products", "productCode") {
+				abortWithError(c, err, "error patching product")
+			}
+			return
+		}
+		c.JSON(http.StatusOK, product)
+	})
+	productsGroup.POST("/:productCode/rename", func(c *gin.Context) {
+		permissionsResult := checkPermissions(c, "product-rename", permissionsHelper)
+		if permissionsResult != http.StatusOK {
+			abortWithProblem(c, permissionsResult, "")
+			return
+		}
+		var rename models.ProductRename
+		if !bindJSON(c, &rename) {
+			return
+		}
+		product, err := productsRepo.Rename(c.Request.Context(), c.Param("productCode"), rename.NewProductCode, currentUserID(c))
+		if err != nil {
+			if !redirectIfRenamed(c, err, aliasesRepo, "products", "productCode") {
+				abortWithError(c, err, "error renaming product")
+			}
+			return
+		}
+		c.JSON(http.StatusOK, product)
+	})
+	productsGroup.DELETE("/:productCode", func(c *gin.Context) {
+		permissionsResult := checkPermissions(c, "product-delete", permissionsHelper)
+		if permissionsResult != http.StatusOK {
+			abortWithProblem(c, permissionsResult, "")
+			return
+		}
+		if err := productsRepo.Delete(c.Request.Context(), c.Param("productCode"), currentUserID(c)); err != nil {
+			if !redirectIfRenamed(c, err, aliasesRepo, "products", "productCode") {
+				abortWithError(c, err, "error deleting product")
+			}
+			return
+		}
+		c.Status(http.StatusNoContent)
+	})
 }
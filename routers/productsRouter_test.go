@@ -0,0 +1,87 @@
+package routers_test
+
+import (
+	"config/models"
+	"config/routers"
+	"config/testharness"
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestProductsRoutes(t *testing.T) {
+	harness := testharness.New(t)
+	editor := harness.AsUser("editor", "product-view", "product-search", "product-create", "product-edit")
+
+	recorder := editor.Post("/products/", models.Product{ProductCode: "P-100", Description: "Plain weave"})
+	testharness.ExpectStatus(t, recorder, http.StatusCreated)
+
+	recorder = editor.Get("/products/P-100")
+	testharness.ExpectStatus(t, recorder, http.StatusOK)
+	product := testharness.Decode[models.Product](t, recorder)
+	if product.Description != "Plain weave" || product.CreatedBy != "editor" {
+		t.Fatalf("unexpected product %+v", product)
+	}
+
+	recorder = editor.Put("/products/P-100", models.Product{ProductCode: "P-100", Description: "Twill weave"})
+	testharness.ExpectStatus(t, recorder, http.StatusOK)
+
+	recorder = editor.Get("/products/")
+	testharness.ExpectStatus(t, recorder, http.StatusOK)
+	products := testharness.Decode[[]models.Product](t, recorder)
+	if len(products) != 1 || products[0].Description != "Twill weave" || products[0].UpdatedBy != "editor" {
+		t.Fatalf("unexpected products %+v", products)
+	}
+}
+
+func TestProductsRoutesRejectMismatchedCode(t *testing.T) {
+	harness := testharness.New(t)
+	editor := harness.AsUser("editor", "product-edit")
+
+	recorder := editor.Put("/products/P-100", models.Product{ProductCode: "P-200", Description: "Twill weave"})
+	testharness.ExpectStatus(t, recorder, http.StatusBadRequest)
+
+	recorder = editor.Put("/products/P-100", "{not json")
+	testharness.ExpectStatus(t, recorder, http.StatusBadRequest)
+}
+
+func TestProductsRoutesReportDomainErrors(t *testing.T) {
+	harness := testharness.New(t)
+	editor := harness.AsUser("editor", "product-view", "product-create", "product-edit")
+
+	recorder := editor.Get("/products/P-404")
+	testharness.ExpectStatus(t, recorder, http.StatusNotFound)
+	if contentType := recorder.Header().Get("Content-Type"); contentType != "application/problem+json" {
+		t.Fatalf("expected a problem response, got %q", contentType)
+	}
+	problem := testharness.Decode[routers.Problem](t, recorder)
+	if problem.Status != http.StatusNotFound || problem.Instance != "/products/P-404" || problem.Detail != "product 'P-404' not found" {
+		t.Fatalf("unexpected problem %+v", problem)
+	}
+
+	testharness.ExpectStatus(t, editor.Put("/products/P-404", models.Product{ProductCode: "P-404"}), http.StatusNotFound)
+
+	testharness.ExpectStatus(t, editor.Post("/products/", models.Product{ProductCode: "P-100"}), http.StatusCreated)
+	testharness.ExpectStatus(t, editor.Post("/products/", models.Product{ProductCode: "P-100"}), http.StatusConflict)
+}
+
+func TestProductsKeepTheirIDAcrossRenames(t *testing.T) {
+	harness := testharness.New(t)
+	editor := harness.AsUser("editor", "product-view", "product-create", "product-edit", "product-rename")
+	testharness.ExpectStatus(t, editor.Post("/products/", models.Product{ProductCode: "P-100", Description: "Plain weave"}), http.StatusCreated)
+	product := testharness.Decode[models.Product](t, editor.Get("/products/P-100"))
+	if product.ID == "" {
+		t.Fatal("expected the product to have an id")
+	}
+
+	testharness.ExpectStatus(t, editor.Put("/products/P-100", models.Product{ID: "ignored", ProductCode: "P-100", Description: "Twill weave"}), http.StatusOK)
+	testharness.ExpectStatus(t, editor.Post("/products/P-100/rename", models.ProductRename{NewProductCode: "P-101"}), http.StatusOK)
+	found := testharness.Decode[models.Product](t, editor.Get("/by-id/products/"+product.ID))
+	if found.ID != product.ID || found.ProductCode != "P-101" || found.Description != "Twill weave" {
+		t.Fatalf("expected lookup by id to find the renamed product, got %+v", found)
+	}
+
+	recorder := editor.WithHeader("Content-Type", "application/merge-patch+json").Do(context.Background(), http.MethodPatch, "/products/P-101", `{"id": "00000000-0000-0000-0000-000000000000"}`)
+	testharness.ExpectStatus(t, recorder, http.StatusUnprocessableEntity)
+	testharness.ExpectStatus(t, editor.Get("/by-id/products/not-a-uuid"), http.StatusNotFound)
+}
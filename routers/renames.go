@@ -0,0 +1,49 @@
+package routers
+
+import (
+	"config/repositories"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// redirectIfRenamed turns a not-found lookup of a key that has since been
+// renamed into a 308 to the same route under the new key. 308 keeps the
+// method and body, so every keyed route can use it, not only GETs.
+func redirectIfRenamed(c *gin.Context, err error, aliasesRepo repositories.AliasesRepository, entityType string, param string) bool {
+	if !errors.Is(err, repositories.ErrNotFound) {
+		return false
+	}
+	newKey, ok := resolveRenamed(c.Request.Context(), aliasesRepo, entityType, c.Param(param))
+	if !ok {
+		return false
+	}
+	location := strings.Replace(c.FullPath(), ":"+param, url.PathEscape(newKey), 1)
+	if c.Request.URL.RawQuery != "" {
+		location += "?" + c.Request.URL.RawQuery
+	}
+	c.Header("Location", location)
+	c.Header("Deprecation", "true")
+	c.Header("Link", fmt.Sprintf("<%v>; rel=\"successor-version\"", location))
+	abortWithProblem(c, http.StatusPermanentRedirect, fmt.Sprintf("'%v' has been renamed to '%v'", c.Param(param), newKey))
+	return true
+}
+
+// resolveRenamed returns the current key for a key that has been renamed
+// away, and false when the key was never renamed or cannot be resolved.
+func resolveRenamed(ctx context.Context, aliasesRepo repositories.AliasesRepository, entityType string, key string) (string, bool) {
+	newKey, err := aliasesRepo.Resolve(ctx, entityType, key)
+	if err != nil {
+		if !errors.Is(err, repositories.ErrNotFound) {
+			log.Ctx(ctx).Warn().Err(err).Msg("unable to resolve alias")
+		}
+		return "", false
+	}
+	return newKey, true
+}
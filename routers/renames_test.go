@@ -0,0 +1,76 @@
+package routers_test
+
+import (
+	"config/models"
+	"config/testharness"
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestRenameTest(t *testing.T) {
+	harness := testharness.New(t)
+	editor := harness.AsUser("editor", "test-view", "test-create", "test-edit", "test-delete", "test-rename")
+	createTests(t, editor,
+		models.Test{TestName: "Tensil Strength", UnitType: "pressure", References: []string{}, Standards: []string{}, AvailableModifiers: []string{}},
+		models.Test{TestName: "Thickness", UnitType: "linear", References: []string{}, Standards: []string{}, AvailableModifiers: []string{}},
+	)
+
+	testharness.ExpectStatus(t, harness.AsUser("viewer", "test-edit").Post("/tests/Thickness/rename", models.TestRename{NewTestName: "Gauge"}), http.StatusForbidden)
+	testharness.ExpectStatus(t, editor.Post("/tests/Tensil%20Strength/rename", models.TestRename{NewTestName: "Thickness"}), http.StatusConflict)
+	testharness.ExpectStatus(t, editor.Post("/tests/Missing/rename", models.TestRename{NewTestName: "Found"}), http.StatusNotFound)
+
+	recorder := editor.Post("/tests/Tensil%20Strength/rename", models.TestRename{NewTestName: "Tensile"})
+	testharness.ExpectStatus(t, recorder, http.StatusOK)
+	if renamed := testharness.Decode[models.Test](t, recorder); renamed.TestName != "Tensile" || renamed.UnitType != "pressure" {
+		t.Fatalf("unexpected renamed test %+v", renamed)
+	}
+	testharness.ExpectStatus(t, editor.Post("/tests/Tensile/rename", models.TestRename{NewTestName: "Tensile Strength"}), http.StatusOK)
+
+	recorder = editor.Get("/tests/Tensil%20Strength")
+	testharness.ExpectStatus(t, recorder, http.StatusPermanentRedirect)
+	if location := recorder.Header().Get("Location"); location != "/tests/Tensile%20Strength" || recorder.Header().Get("Deprecation") != "true" {
+		t.Fatalf("expected a deprecated redirect straight to the latest name, got %q", location)
+	}
+	recorder = editor.WithHeader("Content-Type", "application/merge-patch+json").Do(context.Background(), http.MethodPatch, "/tests/Tensile", `{"unitType": "area"}`)
+	testharness.ExpectStatus(t, recorder, http.StatusPermanentRedirect)
+	testharness.ExpectStatus(t, editor.Get("/tests/Tensile%20Strength"), http.StatusOK)
+
+	testharness.ExpectStatus(t, editor.Delete("/tests/Tensile%20Strength"), http.StatusNoContent)
+	testharness.ExpectStatus(t, editor.Get("/tests/Tensil%20Strength"), http.StatusNotFound)
+}
+
+func TestEveryKeyedRouteFollowsRenames(t *testing.T) {
+	harness := testharness.New(t)
+	editor := harness.AsUser("editor", "product-view", "product-create", "product-edit", "product-delete", "product-rename")
+	testharness.ExpectStatus(t, editor.Post("/products/", models.Product{ProductCode: "P-100", Description: "Plain weave"}), http.StatusCreated)
+	testharness.ExpectStatus(t, editor.Post("/products/P-100/rename", models.ProductRename{NewProductCode: "P-101"}), http.StatusOK)
+
+	stale := models.Product{ProductCode: "P-100", Description: "Twill weave"}
+	recorder := editor.Put("/products/P-100", stale)
+	testharness.ExpectStatus(t, recorder, http.StatusPermanentRedirect)
+	if location := recorder.Header().Get("Location"); location != "/products/P-101" {
+		t.Fatalf("expected the update to redirect to the new code, got %q", location)
+	}
+	testharness.ExpectStatus(t, editor.Put("/products/P-101", stale), http.StatusOK)
+	if product := testharness.Decode[models.Product](t, editor.Get("/products/P-101")); product.ProductCode != "P-101" || product.Description != "Twill weave" {
+		t.Fatalf("expected the followed redirect to update the renamed product, got %+v", product)
+	}
+	testharness.ExpectStatus(t, editor.Post("/products/P-100/rename", models.ProductRename{NewProductCode: "P-102"}), http.StatusPermanentRedirect)
+	testharness.ExpectStatus(t, editor.Delete("/products/P-100"), http.StatusPermanentRedirect)
+
+	operations := []models.ProductBatchOperation{
+		{Op: models.BatchOpUpdate, Product: &models.Product{ProductCode: "P-100", Description: "Satin weave"}},
+	}
+	recorder = editor.Post("/products/batch", models.ProductBatchRequest{Operations: operations})
+	testharness.ExpectStatus(t, recorder, http.StatusOK)
+	if result := testharness.Decode[models.BatchResult](t, recorder); result.Succeeded != 1 || result.Results[0].Key != "P-101" {
+		t.Fatalf("expected the batch update to apply under the new code, got %+v", result)
+	}
+	if product := testharness.Decode[models.Product](t, editor.Get("/products/P-101")); product.Description != "Satin weave" {
+		t.Fatalf("expected the batch update to stick, got %+v", product)
+	}
+	operations = []models.ProductBatchOperation{{Op: models.BatchOpDelete, Key: "P-100"}}
+	testharness.ExpectStatus(t, editor.Post("/products/batch", models.ProductBatchRequest{Operations: operations}), http.StatusOK)
+	testharness.ExpectStatus(t, editor.Get("/products/P-101"), http.StatusNotFound)
+}
@@ -1,7 +1,10 @@
 package routers
 
 import (
+	"config/models"
+	"config/repositories"
 	"config/utilities"
+	"errors"
 	"net/http"
 	"regexp"
 
@@ -9,26 +12,110 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
+const currentUserKey = "currentUser"
+
+const problemContentType = "application/problem+json"
+
+type Problem struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail,omitempty"`
+	Instance  string `json:"instance,omitempty"`
+	RequestID string `json:"requestId,omitempty"`
+}
+
 func checkPermissions(c *gin.Context, permission string, permissionsHelper *utilities.PermissionsHelper) int {
 	authHeader := c.Request.Header.Get("Authorization")
-	log.Info().Msgf("Auth header is %s", authHeader)
 	bearerPattern := regexp.MustCompile("(?i)^bearer (.*)$")
 	tokens := bearerPattern.FindStringSubmatch(authHeader)
 	if len(tokens) != 2 {
-		log.Warn().Msg("Unauthenticated attempt to retrieve config data")
+		log.Ctx(c.Request.Context()).Warn().Msg("Unauthenticated attempt to retrieve config data")
 		return http.StatusUnauthorized
 	}
-	isAllowed, err := permissionsHelper.IsAuthorized(tokens[1], permission)
+	isAllowed, err := permissionsHelper.IsAuthorized(c.Request.Context(), tokens[1], permission)
 	if err != nil {
-		if err.Error() == "authentication failure" {
+		if errors.Is(err, utilities.ErrAuthenticationFailure) {
 			return http.StatusUnauthorized
 		}
-		log.Error().Err(err).Msg("Error checking permissions")
+		log.Ctx(c.Request.Context()).Error().Err(err).Msg("Error checking permissions")
 		return http.StatusInternalServerError
 	}
 	if !isAllowed {
-		log.Warn().Msgf("Failed permission check")
+		log.Ctx(c.Request.Context()).Warn().Msgf("Failed permission check")
 		return http.StatusForbidden
 	}
+	if _, exists := c.Get(currentUserKey); !exists {
+		user, err := permissionsHelper.GetUser(c.Request.Context(), tokens[1])
+		if err != nil {
+			if errors.Is(err, utilities.ErrAuthenticationFailure) {
+				return http.StatusUnauthorized
+			}
+			log.Ctx(c.Request.Context()).Error().Err(err).Msg("Error resolving current user")
+			return http.StatusInternalServerError
+		}
+		c.Set(currentUserKey, user)
+	}
 	return http.StatusOK
 }
+
+func currentUser(c *gin.Context) *models.User {
+	value, exists := c.Get(currentUserKey)
+	if !exists {
+		return nil
+	}
+	user, _ := value.(*models.User)
+	return user
+}
+
+func currentUserID(c *gin.Context) string {
+	user := currentUser(c)
+	if user == nil {
+		return ""
+	}
+	return user.UserID
+}
+
+func abortWithProblem(c *gin.Context, status int, detail string) {
+	c.Header("Content-Type", problemContentType)
+	c.AbortWithStatusJSON(status, Problem{
+		Type:      "about:blank",
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    detail,
+		Instance:  c.Request.URL.Path,
+		RequestID: utilities.RequestIDFromContext(c.Request.Context()),
+	})
+}
+
+func statusForError(err error) int {
+	switch {
+	case errors.Is(err, repositories.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, repositories.ErrAlreadyExists), errors.Is(err, repositories.ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, repositories.ErrValidationFailed):
+		return http.StatusUnprocessableEntity
+	}
+	return http.StatusInternalServerError
+}
+
+func abortWithError(c *gin.Context, err error, message string) {
+	status := statusForError(err)
+	if status == http.StatusInternalServerError {
+		log.Ctx(c.Request.Context()).Error().Err(err).Msg(message)
+		abortWithProblem(c, status, message)
+		return
+	}
+	log.Ctx(c.Request.Context()).Warn().Err(err).Msg(message)
+	abortWithProblem(c, status, err.Error())
+}
+
+func bindJSON(c *gin.Context, target interface{}) bool {
+	if err := c.ShouldBindJSON(target); err != nil {
+		log.Ctx(c.Request.Context()).Warn().Err(err).Msg("request body could not be bound")
+		abortWithProblem(c, http.StatusBadRequest, "request body is not valid JSON for this resource: "+err.Error())
+		return false
+	}
+	return true
+}
@@ -0,0 +1,31 @@
+package routers
+
+import (
+	"config/repositories"
+	"config/utilities"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Dependencies struct {
+	Repositories      *repositories.Repositories
+	ChangeNotifier    repositories.ChangeNotifier
+	HealthChecker     *utilities.HealthChecker
+	PermissionsHelper *utilities.PermissionsHelper
+	ShuttingDown      <-chan struct{}
+}
+
+func RegisterAll(r *gin.Engine, deps Dependencies) {
+	RegisterHealth(r, deps.HealthChecker, deps.ShuttingDown)
+	RegisterDocs(r)
+	RegisterProducts(r.Group("/products"), deps.Repositories.Products, deps.Repositories.Aliases, deps.PermissionsHelper)
+	RegisterTests(r.Group("/tests"), deps.Repositories.Tests, deps.Repositories.Aliases, deps.PermissionsHelper)
+	RegisterImports(r, utilities.NewImporter(deps.Repositories.Transactor, deps.Repositories.Products, deps.Repositories.Tests, deps.Repositories.ConfigSettings), deps.PermissionsHelper)
+	RegisterBatches(r, deps.Repositories.Transactor, deps.Repositories.Products, deps.Repositories.Tests, deps.Repositories.Aliases, deps.PermissionsHelper)
+	RegisterUnits(r.Group("/units"), deps.Repositories.Units, deps.PermissionsHelper)
+	RegisterByID(r.Group("/by-id"), deps.Repositories.Products, deps.Repositories.Tests, deps.Repositories.Units, deps.PermissionsHelper)
+	RegisterExports(r.Group("/exports"), deps.Repositories.Products, deps.Repositories.Tests, deps.Repositories.Units, deps.PermissionsHelper)
+	RegisterConfigSettings(r.Group("/config-settings"), deps.Repositories.ConfigSettings, deps.PermissionsHelper)
+	RegisterWebhooks(r.Group("/webhooks"), deps.Repositories.Webhooks, deps.PermissionsHelper)
+	RegisterChanges(r.Group("/changes"), deps.Repositories.ChangeLog, deps.ChangeNotifier, deps.ShuttingDown, deps.PermissionsHelper)
+}
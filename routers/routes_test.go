@@ -0,0 +1,132 @@
+package routers_test
+
+import (
+	"config/testharness"
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+var protectedRoutes = []struct {
+	method     string
+	path       string
+	permission string
+}{
+	{http.MethodGet, "/products/P-100", "product-view"},
+	{http.MethodGet, "/products/", "product-search"},
+	{http.MethodGet, "/exports/products", "product-search"},
+	{http.MethodGet, "/by-id/products/8a0b8e43-64a6-4b8e-9d1e-1f6a2f0c1d2e", "product-view"},
+	{http.MethodPost, "/products/", "product-create"},
+	{http.MethodPut, "/products/P-100", "product-edit"},
+	{http.MethodPatch, "/products/P-100", "product-edit"},
+	{http.MethodPost, "/products/P-100/rename", "product-rename"},
+	{http.MethodDelete, "/products/P-100", "product-delete"},
+	{http.MethodPost, "/products/import", "product-import"},
+	{http.MethodPost, "/products/batch", "product-create"},
+	{http.MethodGet, "/tests/Elongation", "test-view"},
+	{http.MethodGet, "/tests/?pageSize=10", "test-search"},
+	{http.MethodGet, "/exports/tests", "test-search"},
+	{http.MethodGet, "/by-id/tests/8a0b8e43-64a6-4b8e-9d1e-1f6a2f0c1d2e", "test-view"},
+	{http.MethodPost, "/tests/", "test-create"},
+	{http.MethodPut, "/tests/Elongation", "test-edit"},
+	{http.MethodPatch, "/tests/Elongation", "test-edit"},
+	{http.MethodPost, "/tests/Elongation/rename", "test-rename"},
+	{http.MethodDelete, "/tests/Elongation", "test-delete"},
+	{http.MethodPost, "/tests/import", "test-import"},
+	{http.MethodPost, "/tests/batch", "test-create"},
+	{http.MethodGet, "/units/", "unit-search"},
+	{http.MethodGet, "/exports/units", "unit-search"},
+	{http.MethodGet, "/by-id/units/8a0b8e43-64a6-4b8e-9d1e-1f6a2f0c1d2e", "unit-view"},
+	{http.MethodGet, "/units/Inch", "unit-view"},
+	{http.MethodPost, "/units/", "unit-create"},
+	{http.MethodPut, "/units/Inch", "unit-edit"},
+	{http.MethodPatch, "/units/Inch", "unit-edit"},
+	{http.MethodDelete, "/units/Inch", "unit-delete"},
+	{http.MethodGet, "/config-settings/", "config-view"},
+	{http.MethodGet, "/config-settings/Modifiers", "config-view"},
+	{http.MethodPut, "/config-settings/Modifiers", "config-edit"},
+	{http.MethodPatch, "/config-settings/Modifiers", "config-edit"},
+	{http.MethodDelete, "/config-settings/Modifiers", "config-delete"},
+	{http.MethodGet, "/webhooks/", "webhook-view"},
+	{http.MethodGet, "/webhooks/1", "webhook-view"},
+	{http.MethodPost, "/webhooks/", "webhook-create"},
+	{http.MethodPut, "/webhooks/1", "webhook-edit"},
+	{http.MethodDelete, "/webhooks/1", "webhook-delete"},
+	{http.MethodGet, "/webhooks/1/deliveries", "webhook-view"},
+	{http.MethodPost, "/webhooks/1/replay", "webhook-replay"},
+	{http.MethodGet, "/changes/stream", "change-view"},
+}
+
+var publicRoutes = map[string]bool{
+	"GET /healthz":      true,
+	"GET /readyz":       true,
+	"GET /openapi.json": true,
+	"GET /docs":         true,
+}
+
+func TestRoutesRequirePermissions(t *testing.T) {
+	harness := testharness.New(t)
+	anonymous := harness.Anonymous()
+	unknown := anonymous.WithHeader("Authorization", "Bearer not-a-real-token")
+	unprivileged := harness.AsUser("nobody")
+	for _, route := range protectedRoutes {
+		t.Run(route.method+" "+route.path, func(t *testing.T) {
+			testharness.ExpectStatus(t, anonymous.Do(context.Background(), route.method, route.path, nil), http.StatusUnauthorized)
+			testharness.ExpectStatus(t, unknown.Do(context.Background(), route.method, route.path, nil), http.StatusUnauthorized)
+			testharness.ExpectStatus(t, unprivileged.Do(context.Background(), route.method, route.path, nil), http.StatusForbidden)
+			granted := harness.AsUser("granted", route.permission)
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+			if code := granted.Do(ctx, route.method, route.path, "{}").Code; code == http.StatusUnauthorized || code == http.StatusForbidden {
+				t.Fatalf("expected %v to be allowed with %v, got %v", route.path, route.permission, code)
+			}
+		})
+	}
+}
+
+func TestRoutesAreAllCovered(t *testing.T) {
+	harness := testharness.New(t)
+	covered := make(map[string]bool)
+	for _, route := range protectedRoutes {
+		covered[route.method+" "+strings.SplitN(route.path, "?", 2)[0]] = true
+	}
+	for _, info := range harness.Engine.Routes() {
+		if publicRoutes[info.Method+" "+info.Path] {
+			continue
+		}
+		found := false
+		for key := range covered {
+			if routeMatches(info.Method+" "+info.Path, key) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("route %v %v has no permission test", info.Method, info.Path)
+		}
+	}
+}
+
+func routeMatches(pattern string, request string) bool {
+	patternParts := strings.Split(pattern, "/")
+	requestParts := strings.Split(request, "/")
+	if len(patternParts) != len(requestParts) {
+		return false
+	}
+	for i := range patternParts {
+		if !strings.HasPrefix(patternParts[i], ":") && patternParts[i] != requestParts[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func containsAll(value string, parts ...string) bool {
+	for _, part := range parts {
+		if !strings.Contains(value, part) {
+			return false
+		}
+	}
+	return true
+}
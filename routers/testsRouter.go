@@ -6,28 +6,24 @@ import (
 	"config/utilities"
 	"net/http"
 	"strconv"
-	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog/log"
 )
 
-func RegisterTests(testsGroup *gin.RouterGroup, testsRepo *repositories.TestsRepository, permissionsHelper *utilities.PermissionsHelper) {
+func RegisterTests(testsGroup *gin.RouterGroup, testsRepo repositories.TestsRepository, aliasesRepo repositories.AliasesRepository, permissionsHelper *utilities.PermissionsHelper) {
 	testsGroup.GET("/:testName", func(c *gin.Context) {
 		permissionsResult := checkPermissions(c, "test-view", permissionsHelper)
 		if permissionsResult != http.StatusOK {
-			log.Warn().Msg("failed permission request for test-view")
-			c.AbortWithStatus(permissionsResult)
+			log.Ctx(c.Request.Context()).Warn().Msg("failed permission request for test-view")
+			abortWithProblem(c, permissionsResult, "")
 			return
 		}
-		test, err := testsRepo.GetOne(c.Param("testName"))
+		test, err := testsRepo.GetOne(c.Request.Context(), c.Param("testName"))
 		if err != nil {
-			log.Error().Err(err).Msg("failed retrieving test")
-			c.AbortWithStatus(http.StatusInternalServerError)
-			return
-		}
-		if test == nil {
-			c.AbortWithStatus(http.StatusNotFound)
+			if !redirectIfRenamed(c, err, aliasesRepo, "tests", "testName") {
+				abortWithError(c, err, "error retrieving test")
+			}
 			return
 		}
 		c.JSON(http.StatusOK, test)
@@ -35,59 +31,47 @@ func RegisterTests(testsGroup *gin.RouterGroup, testsRepo *repositories.TestsRep
 	testsGroup.GET("/", func(c *gin.Context) {
 		permissionsResult := checkPermissions(c, "test-search", permissionsHelper)
 		if permissionsResult != http.StatusOK {
-			c.AbortWithStatus(permissionsResult)
+			abortWithProblem(c, permissionsResult, "")
 			return
 		}
 		pageSizeString := c.Query("pageSize")
 		lastKeyString := c.Query("lastKey")
-		namePattern := c.Query("namePattern")
-		unitTypeValues := c.QueryArray("unitType")
-		var criteria models.TestCriteria
-		if len(namePattern) > 0 {
-			criteria.NamePattern = &namePattern
-		}
-		if len(unitTypeValues) > 0 {
-			criteria.UnitTypeValues = &unitTypeValues
-		}
+		criteria := parseTestCriteria(c)
 		pageSize, err := strconv.Atoi(pageSizeString)
 		if err != nil {
-			log.Error().Err(err).Msgf("Unable to parse pageSize value of '%v' as int", pageSizeString)
-			c.AbortWithStatus(http.StatusBadRequest)
+			log.Ctx(c.Request.Context()).Warn().Err(err).Msgf("Unable to parse pageSize value of '%v' as int", pageSizeString)
+			abortWithProblem(c, http.StatusBadRequest, "pageSize must be an integer")
 			return
 		}
 		var lastKey *string
 		if lastKeyString != "" {
 			lastKey = &lastKeyString
 		}
-		tests, err := testsRepo.GetMany(pageSize, lastKey, &criteria)
+		tests, err := testsRepo.GetMany(c.Request.Context(), pageSize, lastKey, &criteria)
 		if err != nil {
-			log.Error().Err(err).Msg("error retrieving tests")
-			c.AbortWithStatus(http.StatusInternalServerError)
+			abortWithError(c, err, "error retrieving tests")
 			return
 		}
 		if tests == nil {
-			log.Error().Msg("tests repo returned nil result but no error")
-			c.AbortWithStatus(http.StatusInternalServerError)
+			log.Ctx(c.Request.Context()).Error().Msg("tests repo returned nil result but no error")
+			abortWithProblem(c, http.StatusInternalServerError, "error retrieving tests")
 			return
 		}
-		log.Info().Msgf("%v tests found", len(*tests))
+		log.Ctx(c.Request.Context()).Info().Msgf("%v tests found", len(*tests))
 		c.JSON(http.StatusOK, *tests)
 	})
 	testsGroup.POST("/", func(c *gin.Context) {
 		permissionsResult := checkPermissions(c, "test-create", permissionsHelper)
 		if permissionsResult != http.StatusOK {
-			c.AbortWithStatus(permissionsResult)
+			abortWithProblem(c, permissionsResult, "")
 			return
 		}
 		var test models.Test
-		if err := c.BindJSON(&test); err != nil {
-			log.Error().Err(err).Msg("request body could not be bound")
-			c.AbortWithStatus(http.StatusBadRequest)
+		if !bindJSON(c, &test) {
 			return
 		}
-		if err := testsRepo.Create(&test); err != nil {
-			log.Error().Err(err).Msg("error creating test")
-			c.AbortWithStatus(http.StatusInternalServerError)
+		if err := testsRepo.Create(c.Request.Context(), &test, currentUserID(c)); err != nil {
+			abortWithError(c, err, "error creating test")
 			return
 		}
 		c.Status(http.StatusCreated)
@@ -95,25 +79,97 @@ func RegisterTests(testsGroup *gin.RouterGroup, testsRepo *repositories.TestsRep
 	testsGroup.PUT("/:testName", func(c *gin.Context) {
 		permissionsResult := checkPermissions(c, "test-edit", permissionsHelper)
 		if permissionsResult != http.StatusOK {
-			c.AbortWithStatus(permissionsResult)
+			abortWithProblem(c, permissionsResult, "")
 			return
 		}
 		var test models.Test
-		if err := c.BindJSON(&test); err != nil {
-			log.Warn().Msg("request body could not be bound")
-			c.AbortWithStatus(http.StatusBadRequest)
+		if !bindJSON(c, &test) {
 			return
 		}
-		if !strings.EqualFold(test.TestName, c.Param("testName")) {
-			log.Warn().Msg("test name in request body does not match request name in URL")
-			c.AbortWithStatus(http.StatusBadRequest)
+		if repositories.FoldKey(test.TestName) != repositories.FoldKey(c.Param("testName")) {
+			// A PUT that followed a rename redirect still carries the old key.
+			if newKey, ok := resolveRenamed(c.Request.Context(), aliasesRepo, "tests", test.TestName); ok && repositories.FoldKey(newKey) == repositories.FoldKey(c.Param("testName")) {
+				test.TestName = newKey
+			}
+		}
+		if repositories.FoldKey(test.TestName) != repositories.FoldKey(c.Param("testName")) {
+			log.Ctx(c.Request.Context()).Warn().Msg("test name in request body does not match request name in URL")
+			abortWithProblem(c, http.StatusBadRequest, "test name in request body does not match URL")
 			return
 		}
-		if err := testsRepo.Update(&test); err != nil {
-			log.Error().Err(err).Msgf("error updating test '%s'", c.Param("testName"))
-			c.AbortWithStatus(http.StatusInternalServerError)
+		if err := testsRepo.Update(c.Request.Context(), &test, currentUserID(c)); err != nil {
+			if !redirectIfRenamed(c, err, aliasesRepo, "tests", "testName") {
+				abortWithError(c, err, "error updating test")
+			}
 			return
 		}
 		c.Status(http.StatusOK)
 	})
+	testsGroup.PATCH("/:testName", func(c *gin.Context) {
+		permissionsResult := checkPermissions(c, "test-edit", permissionsHelper)
+		if permissionsResult != http.StatusOK {
+			abortWithProblem(c, permissionsResult, "")
+			return
+		}
+		patch, ok := readPatch(c)
+		if !ok {
+			return
+		}
+		test, err := testsRepo.Modify(c.Request.Context(), c.Param("testName"), func(test *models.Test) error {
+			return applyPatch(patch, test)
+		}, currentUserID(c))
+		if err != nil {
+			if !redirectIfRenamed(c, err, aliasesRepo, "tests", "testName") {
+				abortWithError(c, err, "error patching test")
+			}
+			return
+		}
+		c.JSON(http.StatusOK, test)
+	})
+	testsGroup.POST("/:testName/rename", func(c *gin.Context) {
+		permissionsResult := checkPermissions(c, "test-rename", permissionsHelper)
+		if permissionsResult != http.StatusOK {
+			abortWithProblem(c, permissionsResult, "")
+			return
+		}
+		var rename models.TestRename
+		if !bindJSON(c, &rename) {
+			return
+		}
+		test, err := testsRepo.Rename(c.Request.Context(), c.Param("testName"), rename.NewTestName, currentUserID(c))
+		if err != nil {
+			if !redirectIfRenamed(c, err, aliasesRepo, "tests", "testName") {
+				abortWithError(c, err, "error renaming test")
+			}
+			return
+		}
+		c.JSON(http.StatusOK, test)
+	})
+	testsGroup.DELETE("/:testName", func(c *gin.Context) {
+		permissionsResult := checkPermissions(c, "test-delete", permissionsHelper)
+		if permissionsResult != http.StatusOK {
+			abortWithProblem(c, permissionsResult, "")
+			return
+		}
+		if err := testsRepo.Delete(c.Request.Context(), c.Param("testName"), currentUserID(c)); err != nil {
+			if !redirectIfRenamed(c, err, aliasesRepo, "tests", "testName") {
+				abortWithError(c, err, "error deleting test")
+			}
+			return
+		}
+		c.Status(http.StatusNoContent)
+	})
+}
+
+func parseTestCriteria(c *gin.Context) models.TestCriteria {
+	namePattern := c.Query("namePattern")
+	unitTypeValues := c.QueryArray("unitType")
+	var criteria models.TestCriteria
+	if len(namePattern) > 0 {
+		criteria.NamePattern = &namePattern
+	}
+	if len(unitTypeValues) > 0 {
+		criteria.UnitTypeValues = &unitTypeValues
+	}
+	return criteria
 }
@@ -0,0 +1,139 @@
+package routers_test
+
+import (
+	"config/models"
+	"config/testharness"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func createTests(t *testing.T, caller *testharness.Caller, tests ...models.Test) {
+	t.Helper()
+	for _, test := range tests {
+		testharness.ExpectStatus(t, caller.Post("/tests/", test), http.StatusCreated)
+	}
+}
+
+func TestTestsRoutes(t *testing.T) {
+	harness := testharness.New(t)
+	editor := harness.AsUser("editor", "test-view", "test-create", "test-edit")
+
+	createTests(t, editor, models.Test{TestName: "Tensile Strength", UnitType: "pressure", References: []string{"ref"}, Standards: []string{}, AvailableModifiers: []string{"warp"}})
+
+	recorder := editor.Get("/tests/Tensile%20Strength")
+	testharness.ExpectStatus(t, recorder, http.StatusOK)
+	test := testharness.Decode[models.Test](t, recorder)
+	if test.UnitType != "pressure" || test.CreatedBy != "editor" {
+		t.Fatalf("unexpected test %+v", test)
+	}
+
+	test.AvailableModifiers = append(test.AvailableModifiers, "fill")
+	testharness.ExpectStatus(t, editor.Put("/tests/Tensile%20Strength", test), http.StatusOK)
+	test = testharness.Decode[models.Test](t, editor.Get("/tests/Tensile%20Strength"))
+	if len(test.AvailableModifiers) != 2 {
+		t.Fatalf("expected updated modifiers, got %+v", test.AvailableModifiers)
+	}
+
+	testharness.ExpectStatus(t, editor.Put("/tests/Elongation", test), http.StatusBadRequest)
+	testharness.ExpectStatus(t, editor.Get("/tests/Elongation"), http.StatusNotFound)
+	testharness.ExpectStatus(t, editor.Put("/tests/Elongation", models.Test{TestName: "Elongation"}), http.StatusNotFound)
+	testharness.ExpectStatus(t, editor.Post("/tests/", test), http.StatusConflict)
+	testharness.ExpectStatus(t, editor.Post("/tests/", models.Test{TestName: "Elongation", UnitType: "linear"}), http.StatusUnprocessableEntity)
+}
+
+func TestTestsSearch(t *testing.T) {
+	harness := testharness.New(t)
+	editor := harness.AsUser("editor", "test-create", "test-search")
+	createTests(t, editor,
+		models.Test{TestName: "Breaking Strength", UnitType: "weight", References: []string{}, Standards: []string{}, AvailableModifiers: []string{}},
+		models.Test{TestName: "Elongation", UnitType: "linear", References: []string{}, Standards: []string{}, AvailableModifiers: []string{}},
+		models.Test{TestName: "Tear Strength", UnitType: "weight", References: []string{}, Standards: []string{}, AvailableModifiers: []string{}},
+		models.Test{TestName: "Tensile Strength", UnitType: "pressure", References: []string{}, Standards: []string{}, AvailableModifiers: []string{}},
+	)
+
+	search := func(query string) []string {
+		t.Helper()
+		recorder := editor.Get("/tests/?" + query)
+		testharness.ExpectStatus(t, recorder, http.StatusOK)
+		var names []string
+		for _, test := range testharness.Decode[[]models.Test](t, recorder) {
+			names = append(names, test.TestName)
+		}
+		return names
+	}
+	expectNames := func(actual []string, expected ...string) {
+		t.Helper()
+		if len(actual) != len(expected) {
+			t.Fatalf("expected %v, got %v", expected, actual)
+		}
+		for i := range expected {
+			if actual[i] != expected[i] {
+				t.Fatalf("expected %v, got %v", expected, actual)
+			}
+		}
+	}
+
+	expectNames(search("pageSize=2"), "Breaking Strength", "Elongation")
+	expectNames(search("pageSize=2&lastKey=Elongation"), "Tear Strength", "Tensile Strength")
+	expectNames(search("pageSize=10&namePattern=STRENGTH"), "Breaking Strength", "Tear Strength", "Tensile Strength")
+	expectNames(search("pageSize=10&namePattern=te_r"), "Tear Strength")
+	expectNames(search("pageSize=10&unitType=weight&unitType=linear"), "Breaking Strength", "Elongation", "Tear Strength")
+
+	testharness.ExpectStatus(t, editor.Get("/tests/?pageSize=lots"), http.StatusBadRequest)
+	testharness.ExpectStatus(t, editor.Get("/tests/?pageSize=-1"), http.StatusUnprocessableEntity)
+}
+
+func TestTestsPageCaseInsensitively(t *testing.T) {
+	harness := testharness.New(t)
+	editor := harness.AsUser("editor", "test-create", "test-search")
+	createTests(t, editor,
+		models.Test{TestName: "Tear Strength", UnitType: "weight", References: []string{}, Standards: []string{}, AvailableModifiers: []string{}},
+		models.Test{TestName: "abrasion", UnitType: "cycles", References: []string{}, Standards: []string{}, AvailableModifiers: []string{}},
+		models.Test{TestName: "Bursting Strength", UnitType: "pressure", References: []string{}, Standards: []string{}, AvailableModifiers: []string{}},
+	)
+
+	var names []string
+	lastKey := ""
+	for {
+		recorder := editor.Get("/tests/?pageSize=1" + lastKey)
+		testharness.ExpectStatus(t, recorder, http.StatusOK)
+		page := testharness.Decode[[]models.Test](t, recorder)
+		if len(page) == 0 {
+			break
+		}
+		names = append(names, page[0].TestName)
+		lastKey = "&lastKey=" + url.QueryEscape(page[0].TestName)
+	}
+	if strings.Join(names, ", ") != "abrasion, Bursting Strength, Tear Strength" {
+		t.Fatalf("expected case-insensitive paging order, got %v", names)
+	}
+}
+
+func TestTestNamesIgnoreCaseAndSpacing(t *testing.T) {
+	harness := testharness.New(t)
+	editor := harness.AsUser("editor", "test-view", "test-create", "test-edit", "test-rename")
+
+	createTests(t, editor, models.Test{TestName: "  Tensile   Strength ", UnitType: "pressure", References: []string{}, Standards: []string{}, AvailableModifiers: []string{}})
+	duplicate := models.Test{TestName: "tensile strength", UnitType: "pressure", References: []string{}, Standards: []string{}, AvailableModifiers: []string{}}
+	testharness.ExpectStatus(t, editor.Post("/tests/", duplicate), http.StatusConflict)
+
+	test := testharness.Decode[models.Test](t, editor.Get("/tests/tensile%20%20STRENGTH"))
+	if test.TestName != "Tensile Strength" {
+		t.Fatalf("expected the stored, normalized name, got %q", test.TestName)
+	}
+
+	duplicate.AvailableModifiers = []string{"warp"}
+	testharness.ExpectStatus(t, editor.Put("/tests/TENSILE%20STRENGTH", duplicate), http.StatusOK)
+	test = testharness.Decode[models.Test](t, editor.Get("/tests/Tensile%20Strength"))
+	if test.TestName != "Tensile Strength" || len(test.AvailableModifiers) != 1 {
+		t.Fatalf("expected the update to apply to the stored test, got %+v", test)
+	}
+
+	recorder := editor.Post("/tests/tensile%20strength/rename", models.TestRename{NewTestName: "Tensile strength"})
+	testharness.ExpectStatus(t, recorder, http.StatusOK)
+	if renamed := testharness.Decode[models.Test](t, recorder); renamed.TestName != "Tensile strength" || renamed.ID != test.ID {
+		t.Fatalf("expected a change of case to rename in place, got %+v", renamed)
+	}
+}
@@ -1,6 +1,7 @@
 package routers
 
 import (
+	"config/models"
 	"config/repositories"
 	"config/utilities"
 	"net/http"
@@ -9,17 +10,99 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
-func RegisterUnits(unitsGroup *gin.RouterGroup, repo *repositories.UnitsRepository, permissionsHelper *utilities.PermissionsHelper) {
+func RegisterUnits(unitsGroup *gin.RouterGroup, repo repositories.UnitsRepository, permissionsHelper *utilities.PermissionsHelper) {
 	unitsGroup.GET("/", func(c *gin.Context) {
 		permissionsResult := checkPermissions(c, "unit-search", permissionsHelper)
 		if permissionsResult != http.StatusOK {
-			c.AbortWithStatus(permissionsResult)
+			abortWithProblem(c, permissionsResult, "")
 			return
 		}
-		units, err := repo.GetMany()
+		units, err := repo.GetMany(c.Request.Context())
 		if err != nil {
-			log.Error().Err(err).Msg("error retrieving units")
+			abortWithError(c, err, "error retrieving units")
+			return
 		}
 		c.JSON(http.StatusOK, units)
 	})
+	unitsGroup.GET("/:fullName", func(c *gin.Context) {
+		permissionsResult := checkPermissions(c, "unit-view", permissionsHelper)
+		if permissionsResult != http.StatusOK {
+			abortWithProblem(c, permissionsResult, "")
+			return
+		}
+		unit, err := repo.GetOne(c.Request.Context(), c.Param("fullName"))
+		if err != nil {
+			abortWithError(c, err, "error retrieving unit")
+			return
+		}
+		c.JSON(http.StatusOK, unit)
+	})
+	unitsGroup.POST("/", func(c *gin.Context) {
+		permissionsResult := checkPermissions(c, "unit-create", permissionsHelper)
+		if permissionsResult != http.StatusOK {
+			abortWithProblem(c, permissionsResult, "")
+			return
+		}
+		var unit models.Unit
+		if !bindJSON(c, &unit) {
+			return
+		}
+		if err := repo.Create(c.Request.Context(), &unit, currentUserID(c)); err != nil {
+			abortWithError(c, err, "error creating unit")
+			return
+		}
+		c.Status(http.StatusCreated)
+	})
+	unitsGroup.PUT("/:fullName", func(c *gin.Context) {
+		permissionsResult := checkPermissions(c, "unit-edit", permissionsHelper)
+		if permissionsResult != http.StatusOK {
+			abortWithProblem(c, permissionsResult, "")
+			return
+		}
+		var unit models.Unit
+		if !bindJSON(c, &unit) {
+			return
+		}
+		if unit.FullName != c.Param("fullName") {
+			log.Ctx(c.Request.Context()).Warn().Msg("unit name in request body does not match URL")
+			abortWithProblem(c, http.StatusBadRequest, "unit name in request body does not match URL")
+			return
+		}
+		if err := repo.Update(c.Request.Context(), &unit, currentUserID(c)); err != nil {
+			abortWithError(c, err, "error updating unit")
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+	unitsGroup.PATCH("/:fullName", func(c *gin.Context) {
+		permissionsResult := checkPermissions(c, "unit-edit", permissionsHelper)
+		if permissionsResult != http.StatusOK {
+			abortWithProblem(c, permissionsResult, "")
+			return
+		}
+		patch, ok := readPatch(c)
+		if !ok {
+			return
+		}
+		unit, err := repo.Modify(c.Request.Context(), c.Param("fullName"), func(unit *models.Unit) error {
+			return applyPatch(patch, unit)
+		}, currentUserID(c))
+		if err != nil {
+			abortWithError(c, err, "error patching unit")
+			return
+		}
+		c.JSON(http.StatusOK, unit)
+	})
+	unitsGroup.DELETE("/:fullName", func(c *gin.Context) {
+		permissionsResult := checkPermissions(c, "unit-delete", permissionsHelper)
+		if permissionsResult != http.StatusOK {
+			abortWithProblem(c, permissionsResult, "")
+			return
+		}
+		if err := repo.Delete(c.Request.Context(), c.Param("fullName"), currentUserID(c)); err != nil {
+			abortWithError(c, err, "error deleting unit")
+			return
+		}
+		c.Status(http.StatusNoContent)
+	})
 }
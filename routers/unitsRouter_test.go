@@ -0,0 +1,40 @@
+package routers_test
+
+import (
+	"config/models"
+	"config/testharness"
+	"net/http"
+	"testing"
+)
+
+func TestUnitsRoutes(t *testing.T) {
+	harness := testharness.New(t)
+	harness.Bootstrap()
+	reader := harness.AsUser("reader", "unit-search")
+
+	recorder := reader.Get("/units/")
+	testharness.ExpectStatus(t, recorder, http.StatusOK)
+	units := testharness.Decode[[]models.Unit](t, recorder)
+	if len(units) == 0 || units[0].FullName != "inch" || units[0].CreatedBy != "auto" {
+		t.Fatalf("expected bootstrapped units, got %+v", units)
+	}
+}
+
+func TestUnitsRoutesCreateUpdateDelete(t *testing.T) {
+	harness := testharness.New(t)
+	editor := harness.AsUser("editor", "unit-view", "unit-create", "unit-edit", "unit-delete")
+	unit := models.Unit{FullName: "furlong", FullNamePlural: "furlongs", Abbreviation: "fur", MeasurementSystem: "Imperial", UnitType: "Length"}
+
+	testharness.ExpectStatus(t, editor.Post("/units/", unit), http.StatusCreated)
+	testharness.ExpectStatus(t, editor.Post("/units/", unit), http.StatusConflict)
+	unit.Abbreviation = "fg"
+	testharness.ExpectStatus(t, editor.Put("/units/furlong", unit), http.StatusOK)
+	recorder := editor.Get("/units/furlong")
+	testharness.ExpectStatus(t, recorder, http.StatusOK)
+	if got := testharness.Decode[models.Unit](t, recorder); got.Abbreviation != "fg" || got.UpdatedBy != "editor" {
+		t.Fatalf("expected updated unit, got %+v", got)
+	}
+	testharness.ExpectStatus(t, editor.Delete("/units/furlong"), http.StatusNoContent)
+	testharness.ExpectStatus(t, editor.Get("/units/furlong"), http.StatusNotFound)
+	testharness.ExpectStatus(t, editor.Delete("/units/furlong"), http.StatusNotFound)
+}
@@ -0,0 +1,212 @@
+package routers
+
+import (
+	"config/models"
+	"config/repositories"
+	"config/utilities"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+func RegisterWebhooks(webhooksGroup *gin.RouterGroup, webhooksRepo repositories.WebhooksRepository, permissionsHelper *utilities.PermissionsHelper) {
+	webhooksGroup.GET("/", func(c *gin.Context) {
+		permissionsResult := checkPermissions(c, "webhook-view", permissionsHelper)
+		if permissionsResult != http.StatusOK {
+			abortWithProblem(c, permissionsResult, "")
+			return
+		}
+		subscriptions, err := webhooksRepo.GetSubscriptions(c.Request.Context())
+		if err != nil {
+			abortWithError(c, err, "error retrieving webhook subscriptions")
+			return
+		}
+		c.JSON(http.StatusOK, subscriptions)
+	})
+	webhooksGroup.GET("/:subscriptionId", func(c *gin.Context) {
+		permissionsResult := checkPermissions(c, "webhook-view", permissionsHelper)
+		if permissionsResult != http.StatusOK {
+			abortWithProblem(c, permissionsResult, "")
+			return
+		}
+		subscriptionID, ok := parseSubscriptionID(c)
+		if !ok {
+			return
+		}
+		subscription, err := webhooksRepo.GetSubscription(c.Request.Context(), subscriptionID)
+		if err != nil {
+			abortWithError(c, err, "error retrieving webhook subscription")
+			return
+		}
+		c.JSON(http.StatusOK, subscription)
+	})
+	webhooksGroup.POST("/", func(c *gin.Context) {
+		permissionsResult := checkPermissions(c, "webhook-create", permissionsHelper)
+		if permissionsResult != http.StatusOK {
+			abortWithProblem(c, permissionsResult, "")
+			return
+		}
+		var subscription models.WebhookSubscription
+		if !bindJSON(c, &subscription) {
+			return
+		}
+		if err := utilities.ValidateWebhookURL(subscription.URL); err != nil {
+			log.Ctx(c.Request.Context()).Warn().Err(err).Msg("rejected webhook subscription URL")
+			abortWithProblem(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		if subscription.Secret == "" {
+			secret, err := generateWebhookSecret()
+			if err != nil {
+				abortWithError(c, err, "error generating webhook secret")
+				return
+			}
+			subscription.Secret = secret
+		}
+		if subscription.EventTypes == nil {
+			subscription.EventTypes = []string{}
+		}
+		subscription.IsActive = true
+		if err := webhooksRepo.CreateSubscription(c.Request.Context(), &subscription, currentUserID(c)); err != nil {
+			abortWithError(c, err, "error creating webhook subscription")
+			return
+		}
+		c.JSON(http.StatusCreated, subscription)
+	})
+	webhooksGroup.PUT("/:subscriptionId", func(c *gin.Context) {
+		permissionsResult := checkPermissions(c, "webhook-edit", permissionsHelper)
+		if permissionsResult != http.StatusOK {
+			abortWithProblem(c, permissionsResult, "")
+			return
+		}
+		subscriptionID, ok := parseSubscriptionID(c)
+		if !ok {
+			return
+		}
+		var subscription models.WebhookSubscription
+		if !bindJSON(c, &subscription) {
+			return
+		}
+		if subscription.SubscriptionID != subscriptionID {
+			log.Ctx(c.Request.Context()).Warn().Msg("subscription id in request body does not match URL")
+			abortWithProblem(c, http.StatusBadRequest, "subscription id in request body does not match URL")
+			return
+		}
+		if err := utilities.ValidateWebhookURL(subscription.URL); err != nil {
+			log.Ctx(c.Request.Context()).Warn().Err(err).Msg("rejected webhook subscription URL")
+			abortWithProblem(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		if subscription.EventTypes == nil {
+			subscription.EventTypes = []string{}
+		}
+		if err := webhooksRepo.UpdateSubscription(c.Request.Context(), &subscription, currentUserID(c)); err != nil {
+			abortWithError(c, err, fmt.Sprintf("error updating webhook subscription %v", subscriptionID))
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+	webhooksGroup.DELETE("/:subscriptionId", func(c *gin.Context) {
+		permissionsResult := checkPermissions(c, "webhook-delete", permissionsHelper)
+		if permissionsResult != http.StatusOK {
+			abortWithProblem(c, permissionsResult, "")
+			return
+		}
+		subscriptionID, ok := parseSubscriptionID(c)
+		if !ok {
+			return
+		}
+		deleted, err := webhooksRepo.DeleteSubscription(c.Request.Context(), subscriptionID)
+		if err != nil {
+			abortWithError(c, err, fmt.Sprintf("error deleting webhook subscription %v", subscriptionID))
+			return
+		}
+		if !deleted {
+			abortWithProblem(c, http.StatusNotFound, fmt.Sprintf("webhook subscription '%v' not found", subscriptionID))
+			return
+		}
+		c.Status(http.StatusNoContent)
+	})
+	webhooksGroup.GET("/:subscriptionId/deliveries", func(c *gin.Context) {
+		permissionsResult := checkPermissions(c, "webhook-view", permissionsHelper)
+		if permissionsResult != http.StatusOK {
+			abortWithProblem(c, permissionsResult, "")
+			return
+		}
+		subscriptionID, ok := parseSubscriptionID(c)
+		if !ok {
+			return
+		}
+		var status *string
+		if statusString := c.Query("status"); statusString != "" {
+			status = &statusString
+		}
+		limit := 100
+		if limitString := c.Query("limit"); limitString != "" {
+			parsed, err := strconv.Atoi(limitString)
+			if err != nil {
+				log.Ctx(c.Request.Context()).Warn().Msgf("Unable to parse limit value of '%v' as int", limitString)
+				abortWithProblem(c, http.StatusBadRequest, fmt.Sprintf("Unable to parse limit value of '%v' as int", limitString))
+				return
+			}
+			limit = parsed
+		}
+		deliveries, err := webhooksRepo.GetDeliveries(c.Request.Context(), subscriptionID, status, limit)
+		if err != nil {
+			abortWithError(c, err, "error retrieving webhook deliveries")
+			return
+		}
+		c.JSON(http.StatusOK, deliveries)
+	})
+	webhooksGroup.POST("/:subscriptionId/replay", func(c *gin.Context) {
+		permissionsResult := checkPermissions(c, "webhook-replay", permissionsHelper)
+		if permissionsResult != http.StatusOK {
+			abortWithProblem(c, permissionsResult, "")
+			return
+		}
+		subscriptionID, ok := parseSubscriptionID(c)
+		if !ok {
+			return
+		}
+		var deliveryID *int64
+		if deliveryIDString := c.Query("deliveryId"); deliveryIDString != "" {
+			parsed, err := strconv.ParseInt(deliveryIDString, 10, 64)
+			if err != nil {
+				log.Ctx(c.Request.Context()).Warn().Msgf("Unable to parse deliveryId value of '%v' as int", deliveryIDString)
+				abortWithProblem(c, http.StatusBadRequest, fmt.Sprintf("Unable to parse deliveryId value of '%v' as int", deliveryIDString))
+				return
+			}
+			deliveryID = &parsed
+		}
+		status := c.DefaultQuery("status", models.WebhookDeliveryStatusDead)
+		replayed, err := webhooksRepo.Replay(c.Request.Context(), subscriptionID, deliveryID, &status)
+		if err != nil {
+			abortWithError(c, err, fmt.Sprintf("error replaying webhook deliveries for subscription %v", subscriptionID))
+			return
+		}
+		c.JSON(http.StatusAccepted, gin.H{"replayed": replayed})
+	})
+}
+
+func parseSubscriptionID(c *gin.Context) (int64, bool) {
+	subscriptionID, err := strconv.ParseInt(c.Param("subscriptionId"), 10, 64)
+	if err != nil {
+		log.Ctx(c.Request.Context()).Warn().Msgf("Unable to parse subscriptionId value of '%v' as int", c.Param("subscriptionId"))
+		abortWithProblem(c, http.StatusBadRequest, fmt.Sprintf("Unable to parse subscriptionId value of '%v' as int", c.Param("subscriptionId")))
+		return 0, false
+	}
+	return subscriptionID, true
+}
+
+func generateWebhookSecret() (string, error) {
+	secretBytes := make([]byte, 32)
+	if _, err := rand.Read(secretBytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(secretBytes), nil
+}
@@ -0,0 +1,107 @@
+package routers_test
+
+import (
+	"config/models"
+	"config/testharness"
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestWebhooksRoutes(t *testing.T) {
+	harness := testharness.New(t)
+	admin := harness.AsUser("admin", "webhook-view", "webhook-create", "webhook-edit", "webhook-delete", "webhook-replay", "product-create")
+
+	for _, url := range []string{"ftp://example.com", "http://erp.example.com/hooks", "https://localhost/hooks", "https://127.0.0.1/hooks", "https://10.0.0.5/hooks", "https://169.254.169.254/latest", "https://[::1]/hooks"} {
+		testharness.ExpectStatus(t, admin.Post("/webhooks/", models.WebhookSubscription{URL: url}), http.StatusBadRequest)
+	}
+
+	recorder := admin.Post("/webhooks/", models.WebhookSubscription{URL: "https://erp.example.com/hooks", EventTypes: []string{"products.created"}})
+	testharness.ExpectStatus(t, recorder, http.StatusCreated)
+	created := testharness.Decode[models.WebhookSubscription](t, recorder)
+	if created.SubscriptionID == 0 || created.Secret == "" || !created.IsActive {
+		t.Fatalf("unexpected subscription %+v", created)
+	}
+	path := fmt.Sprintf("/webhooks/%v", created.SubscriptionID)
+
+	recorder = admin.Get(path)
+	testharness.ExpectStatus(t, recorder, http.StatusOK)
+	if fetched := testharness.Decode[models.WebhookSubscription](t, recorder); fetched.Secret != "" {
+		t.Fatalf("secret should not be returned after creation, got %+v", fetched)
+	}
+	recorder = admin.Get("/webhooks/")
+	testharness.ExpectStatus(t, recorder, http.StatusOK)
+	if subscriptions := testharness.Decode[[]models.WebhookSubscription](t, recorder); len(subscriptions) != 1 {
+		t.Fatalf("expected one subscription, got %+v", subscriptions)
+	}
+
+	created.URL = "https://erp.example.com/hooks/v2"
+	testharness.ExpectStatus(t, admin.Put(path, created), http.StatusOK)
+	testharness.ExpectStatus(t, admin.Put("/webhooks/999", created), http.StatusBadRequest)
+
+	testharness.ExpectStatus(t, admin.Post("/products/", models.Product{ProductCode: "P-100", Description: "Plain weave"}), http.StatusCreated)
+	recorder = admin.Get(path + "/deliveries?status=pending")
+	testharness.ExpectStatus(t, recorder, http.StatusOK)
+	deliveries := testharness.Decode[[]models.WebhookDelivery](t, recorder)
+	if len(deliveries) != 1 || deliveries[0].EventType != "products.created" {
+		t.Fatalf("expected one pending delivery, got %+v", deliveries)
+	}
+
+	if err := harness.Repositories.Webhooks.MarkFailed(context.Background(), deliveries[0].DeliveryID, deliveries[0].NextAttemptAt, "gone", nil); err != nil {
+		t.Fatal(err)
+	}
+	recorder = admin.Post(path+"/replay", nil)
+	testharness.ExpectStatus(t, recorder, http.StatusAccepted)
+	if result := testharness.Decode[map[string]int](t, recorder); result["replayed"] != 1 {
+		t.Fatalf("expected one replayed delivery, got %+v", result)
+	}
+	testharness.ExpectStatus(t, admin.Get(path+"/deliveries?limit=many"), http.StatusBadRequest)
+
+	testharness.ExpectStatus(t, admin.Delete(path), http.StatusNoContent)
+	testharness.ExpectStatus(t, admin.Delete(path), http.StatusNotFound)
+	testharness.ExpectStatus(t, admin.Get(path), http.StatusNotFound)
+	testharness.ExpectStatus(t, admin.Get("/webhooks/abc"), http.StatusBadRequest)
+}
+
+func TestChangesStream(t *testing.T) {
+	harness := testharness.New(t)
+	editor := harness.AsUser("editor", "change-view", "product-create")
+	testharness.ExpectStatus(t, editor.Post("/products/", models.Product{ProductCode: "P-100", Description: "Plain weave"}), http.StatusCreated)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	recorder := editor.WithHeader("Last-Event-ID", "0").Do(ctx, http.MethodGet, "/changes/stream", nil)
+	testharness.ExpectStatus(t, recorder, http.StatusOK)
+	if contentType := recorder.Header().Get("Content-Type"); contentType != "text/event-stream" {
+		t.Fatalf("unexpected content type %q", contentType)
+	}
+	body := recorder.Body.String()
+	if !containsAll(body, "id:1", "event:products", `"entityKey":"P-100"`) {
+		t.Fatalf("expected the product change in the stream, got %q", body)
+	}
+
+	testharness.ExpectStatus(t, editor.Get("/changes/stream?lastEventId=latest"), http.StatusBadRequest)
+}
+
+func TestChangesStreamCannotResumeFromPrunedChanges(t *testing.T) {
+	harness := testharness.New(t)
+	editor := harness.AsUser("editor", "change-view", "product-create")
+	testharness.ExpectStatus(t, editor.Post("/products/", models.Product{ProductCode: "P-100"}), http.StatusCreated)
+	cutoff := time.Now()
+	testharness.ExpectStatus(t, editor.Post("/products/", models.Product{ProductCode: "P-200"}), http.StatusCreated)
+	if pruned, err := harness.Repositories.ChangeLog.Prune(context.Background(), cutoff); err != nil || pruned != 1 {
+		t.Fatalf("expected the first change to be pruned, got %v (%v)", pruned, err)
+	}
+
+	testharness.ExpectStatus(t, editor.WithHeader("Last-Event-ID", "0").Get("/changes/stream"), http.StatusGone)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	recorder := editor.WithHeader("Last-Event-ID", "1").Do(ctx, http.MethodGet, "/changes/stream", nil)
+	testharness.ExpectStatus(t, recorder, http.StatusOK)
+	if body := recorder.Body.String(); !containsAll(body, "id:2", `"entityKey":"P-200"`) {
+		t.Fatalf("expected to resume from the oldest kept change, got %q", body)
+	}
+}
@@ -0,0 +1,370 @@
+package settings
+
+import (
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/rs/zerolog"
+	"gopkg.in/yaml.v3"
+)
+
+type DatabaseSettings struct {
+	Host        string `yaml:"host"`
+	Port        int    `yaml:"port"`
+	User        string `yaml:"user"`
+	Password    string `yaml:"password"`
+	Name        string `yaml:"name"`
+	SSLMode     string `yaml:"sslMode"`
+	SSLRootCert string `yaml:"sslRootCert"`
+	SSLCert     string `yaml:"sslCert"`
+	SSLKey      string `yaml:"sslKey"`
+	TimeZone    string `yaml:"timeZone"`
+
+	StatementTimeout time.Duration `yaml:"statementTimeout"`
+}
+
+type CacheSettings struct {
+	Provider       string `yaml:"provider"`
+	MemoryCapacity int    `yaml:"memoryCapacity"`
+}
+
+type RedisSettings struct {
+	Address  string `yaml:"address"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	DB       int    `yaml:"db"`
+}
+
+type AuthSettings struct {
+	Endpoint string `yaml:"endpoint"`
+}
+
+type TracingSettings struct {
+	Exporter string `yaml:"exporter"`
+	File     string `yaml:"file"`
+}
+
+type ReadinessSettings struct {
+	CheckAuth            bool          `yaml:"checkAuth"`
+	CriticalDependencies []string      `yaml:"criticalDependencies"`
+	Timeout              time.Duration `yaml:"timeout"`
+}
+
+type RetentionSettings struct {
+	Webhooks  time.Duration `yaml:"webhooks"`
+	ChangeLog time.Duration `yaml:"changeLog"`
+}
+
+type Settings struct {
+	Port            int               `yaml:"port"`
+	LogLevel        string            `yaml:"logLevel"`
+	ShutdownTimeout time.Duration     `yaml:"shutdownTimeout"`
+	Storage         string            `yaml:"storage"`
+	Database        DatabaseSettings  `yaml:"database"`
+	Cache           CacheSettings     `yaml:"cache"`
+	Redis           RedisSettings     `yaml:"redis"`
+	Auth            AuthSettings      `yaml:"auth"`
+	Tracing         TracingSettings   `yaml:"tracing"`
+	Readiness       ReadinessSettings `yaml:"readiness"`
+	Retention       RetentionSettings `yaml:"retention"`
+}
+
+type source struct {
+	env   string
+	flag  string
+	usage string
+	apply func(settings *Settings, value string) error
+}
+
+var storageProviders = []string{"postgres", "memory"}
+var sslModes = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
+var cacheProviders = []string{"redis", "memory"}
+var traceExporters = []string{"none", "stdout", "file", "otlp"}
+var readinessDependencies = []string{"postgres", "cache", "auth"}
+
+func Defaults() *Settings {
+	return &Settings{
+		Port:            3020,
+		LogLevel:        "info",
+		ShutdownTimeout: 30 * time.Second,
+		Storage:         "postgres",
+		Database: DatabaseSettings{
+			Port:     5432,
+			SSLMode:  "prefer",
+			TimeZone: "America/New_York",
+
+			StatementTimeout: 30 * time.Second,
+		},
+		Cache: CacheSettings{
+			Provider:       "redis",
+			MemoryCapacity: 10000,
+		},
+		Tracing: TracingSettings{
+			Exporter: "none",
+		},
+		Readiness: ReadinessSettings{
+			CriticalDependencies: []string{"postgres", "cache"},
+			Timeout:              2 * time.Second,
+		},
+		Retention: RetentionSettings{
+			Webhooks:  30 * 24 * time.Hour,
+			ChangeLog: 90 * 24 * time.Hour,
+		},
+	}
+}
+
+func sources() []source {
+	return []source{
+		{"PORT", "port", "HTTP listen port", func(s *Settings, v string) error { return parseInt(v, &s.Port) }},
+		{"LOG_LEVEL", "log-level", "log level (trace, debug, info, warn, error)", func(s *Settings, v string) error { s.LogLevel = v; return nil }},
+		{"SHUTDOWN_TIMEOUT", "shutdown-timeout", "time allowed to drain requests on shutdown", func(s *Settings, v string) error { return parseDuration(v, &s.ShutdownTimeout) }},
+		{"STORAGE", "storage", "storage backend (" + strings.Join(storageProviders, ", ") + ")", func(s *Settings, v string) error { s.Storage = v; return nil }},
+		{"PGHOST", "db-host", "Postgres host", func(s *Settings, v string) error { s.Database.Host = v; return nil }},
+		{"PGPORT", "db-port", "Postgres port", func(s *Settings, v string) error { return parseInt(v, &s.Database.Port) }},
+		{"PGUSER", "db-user", "Postgres user", func(s *Settings, v string) error { s.Database.User = v; return nil }},
+		{"PGPASSWORD", "db-password", "Postgres password", func(s *Settings, v string) error { s.Database.Password = v; return nil }},
+		{"PGDATABASE", "db-name", "Postgres database name", func(s *Settings, v string) error { s.Database.Name = v; return nil }},
+		{"PGSSLMODE", "db-sslmode", "Postgres TLS mode (" + strings.Join(sslModes, ", ") + ")", func(s *Settings, v string) error { s.Database.SSLMode = v; return nil }},
+		{"PGSSLROOTCERT", "db-sslrootcert", "Postgres TLS root certificate file", func(s *Settings, v string) error { s.Database.SSLRootCert = v; return nil }},
+		{"PGSSLCERT", "db-sslcert", "Postgres TLS client certificate file", func(s *Settings, v string) error { s.Database.SSLCert = v; return nil }},
+		{"PGSSLKEY", "db-sslkey", "Postgres TLS client key file", func(s *Settings, v string) error { s.Database.SSLKey = v; return nil }},
+		{"PGTZ", "db-timezone", "Postgres session time zone", func(s *Settings, v string) error { s.Database.TimeZone = v; return nil }},
+		{"DB_STATEMENT_TIMEOUT", "db-statement-timeout", "maximum duration of a single database operation", func(s *Settings, v string) error { return parseDuration(v, &s.Database.StatementTimeout) }},
+		{"CACHE_PROVIDER", "cache-provider", "cache provider (" + strings.Join(cacheProviders, ", ") + ")", func(s *Settings, v string) error { s.Cache.Provider = v; return nil }},
+		{"CACHE_MEMORY_CAPACITY", "cache-memory-capacity", "maximum entries in the in-memory cache", func(s *Settings, v string) error { return parseInt(v, &s.Cache.MemoryCapacity) }},
+		{"REDIS_ADDRESS", "redis-address", "Redis host:port", func(s *Settings, v string) error { s.Redis.Address = v; return nil }},
+		{"REDIS_USERNAME", "redis-username", "Redis ACL username", func(s *Settings, v string) error { s.Redis.Username = v; return nil }},
+		{"REDIS_PASSWORD", "redis-password", "Redis password", func(s *Settings, v string) error { s.Redis.Password = v; return nil }},
+		{"REDIS_DB", "redis-db", "Redis database number", func(s *Settings, v string) error { return parseInt(v, &s.Redis.DB) }},
+		{"AUTH_SERVICE_ENDPOINT", "auth-endpoint", "base URL of the auth service", func(s *Settings, v string) error { s.Auth.Endpoint = v; return nil }},
+		{"TRACE_EXPORTER", "trace-exporter", "trace exporter (" + strings.Join(traceExporters, ", ") + ")", func(s *Settings, v string) error { s.Tracing.Exporter = v; return nil }},
+		{"TRACE_FILE", "trace-file", "file written by the file trace exporter", func(s *Settings, v string) error { s.Tracing.File = v; return nil }},
+		{"READINESS_CHECK_AUTH", "readiness-check-auth", "include the auth service in readiness checks", func(s *Settings, v string) error { return parseBool(v, &s.Readiness.CheckAuth) }},
+		{"READINESS_CRITICAL_DEPENDENCIES", "readiness-critical", "comma-separated dependencies that must be up to be ready", func(s *Settings, v string) error {
+			s.Readiness.CriticalDependencies = splitList(v)
+			return nil
+		}},
+		{"READINESS_TIMEOUT", "readiness-timeout", "timeout for readiness checks", func(s *Settings, v string) error { return parseDuration(v, &s.Readiness.Timeout) }},
+		{"CHANGE_LOG_RETENTION", "change-log-retention", "how long the change log keeps changes for streams to resume from", func(s *Settings, v string) error { return parseDuration(v, &s.Retention.ChangeLog) }},
+		{"WEBHOOK_RETENTION", "webhook-retention", "how long delivered and dead webhook deliveries are kept", func(s *Settings, v string) error { return parseDuration(v, &s.Retention.Webhooks) }},
+	}
+}
+
+func Load(args []string) (*Settings, error) {
+	return load(args, (*Settings).validate)
+}
+
+func LoadDatabase(args []string) (*Settings, error) {
+	return load(args, (*Settings).validateDatabase)
+}
+
+func load(args []string, validate func(settings *Settings) []string) (*Settings, error) {
+	flags := flag.NewFlagSet("qme-config", flag.ContinueOnError)
+	configFile := flags.String("config", os.Getenv("CONFIG_FILE"), "optional YAML settings file")
+	flagValues := make(map[string]*string)
+	for _, src := range sources() {
+		flagValues[src.flag] = flags.String(src.flag, "", src.usage+" (env "+src.env+")")
+	}
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+
+	settings := Defaults()
+	if *configFile != "" {
+		fileBytes, err := os.ReadFile(*configFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read settings file: %w", err)
+		}
+		if err := yaml.Unmarshal(fileBytes, settings); err != nil {
+			return nil, fmt.Errorf("unable to parse settings file %s: %w", *configFile, err)
+		}
+	}
+
+	var problems []string
+	for _, src := range sources() {
+		if value, found := os.LookupEnv(src.env); found && value != "" {
+			if err := src.apply(settings, value); err != nil {
+				problems = append(problems, fmt.Sprintf("%s: %v", src.env, err))
+			}
+		}
+	}
+	setFlags := make(map[string]bool)
+	flags.Visit(func(f *flag.Flag) { setFlags[f.Name] = true })
+	for _, src := range sources() {
+		if setFlags[src.flag] {
+			if err := src.apply(settings, *flagValues[src.flag]); err != nil {
+				problems = append(problems, fmt.Sprintf("--%s: %v", src.flag, err))
+			}
+		}
+	}
+	problems = append(problems, validate(settings)...)
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("invalid settings:\n  %s", strings.Join(problems, "\n  "))
+	}
+	return settings, nil
+}
+
+func (settings *Settings) validate() []string {
+	var problems []string
+	if settings.Port < 1 || settings.Port > 65535 {
+		problems = append(problems, fmt.Sprintf("port %v is out of range (1-65535)", settings.Port))
+	}
+	if _, err := zerolog.ParseLevel(settings.LogLevel); err != nil || settings.LogLevel == "" {
+		problems = append(problems, fmt.Sprintf("log level '%s' is not recognized", settings.LogLevel))
+	}
+	if settings.ShutdownTimeout <= 0 {
+		problems = append(problems, "shutdown timeout must be positive")
+	}
+	if !contains(storageProviders, settings.Storage) {
+		problems = append(problems, fmt.Sprintf("storage '%s' must be one of %s", settings.Storage, strings.Join(storageProviders, ", ")))
+	}
+	if settings.Storage == "postgres" {
+		problems = append(problems, settings.validateDatabase()...)
+	}
+	if !contains(cacheProviders, settings.Cache.Provider) {
+		problems = append(problems, fmt.Sprintf("cache provider '%s' must be one of %s", settings.Cache.Provider, strings.Join(cacheProviders, ", ")))
+	}
+	if settings.Cache.Provider == "memory" && settings.Cache.MemoryCapacity < 1 {
+		problems = append(problems, "in-memory cache capacity must be at least 1")
+	}
+	if settings.Cache.Provider == "redis" && settings.Redis.Address == "" {
+		problems = append(problems, "redis address is required when the cache provider is redis (REDIS_ADDRESS)")
+	}
+	if settings.Redis.DB < 0 {
+		problems = append(problems, "redis db must not be negative")
+	}
+	if parsed, err := url.Parse(settings.Auth.Endpoint); settings.Auth.Endpoint == "" || err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		problems = append(problems, fmt.Sprintf("auth endpoint '%s' must be an absolute URL (AUTH_SERVICE_ENDPOINT)", settings.Auth.Endpoint))
+	}
+	if !contains(traceExporters, settings.Tracing.Exporter) {
+		problems = append(problems, fmt.Sprintf("trace exporter '%s' must be one of %s", settings.Tracing.Exporter, strings.Join(traceExporters, ", ")))
+	}
+	if settings.Tracing.Exporter == "file" && settings.Tracing.File == "" {
+		problems = append(problems, "trace file is required when the trace exporter is file (TRACE_FILE)")
+	}
+	for _, dependency := range settings.Readiness.CriticalDependencies {
+		if !contains(readinessDependencies, dependency) {
+			problems = append(problems, fmt.Sprintf("readiness dependency '%s' must be one of %s", dependency, strings.Join(readinessDependencies, ", ")))
+		}
+	}
+	if settings.Readiness.Timeout <= 0 {
+		problems = append(problems, "readiness timeout must be positive")
+	}
+	if settings.Retention.ChangeLog <= 0 {
+		problems = append(problems, "change log retention must be positive")
+	}
+	if settings.Retention.Webhooks <= 0 {
+		problems = append(problems, "webhook retention must be positive")
+	}
+	return problems
+}
+
+func (settings *Settings) validateDatabase() []string {
+	var problems []string
+	if settings.Database.Host == "" {
+		problems = append(problems, "database host is required (PGHOST)")
+	}
+	if settings.Database.User == "" {
+		problems = append(problems, "database user is required (PGUSER)")
+	}
+	if settings.Database.Name == "" {
+		problems = append(problems, "database name is required (PGDATABASE)")
+	}
+	if settings.Database.Port < 1 || settings.Database.Port > 65535 {
+		problems = append(problems, fmt.Sprintf("database port %v is out of range (1-65535)", settings.Database.Port))
+	}
+	if !contains(sslModes, settings.Database.SSLMode) {
+		problems = append(problems, fmt.Sprintf("database sslmode '%s' must be one of %s", settings.Database.SSLMode, strings.Join(sslModes, ", ")))
+	}
+	if (settings.Database.SSLCert == "") != (settings.Database.SSLKey == "") {
+		problems = append(problems, "database TLS client certificate and key must be provided together")
+	}
+	if _, err := time.LoadLocation(settings.Database.TimeZone); err != nil {
+		problems = append(problems, fmt.Sprintf("database time zone '%s' is not recognized", settings.Database.TimeZone))
+	}
+	if settings.Database.StatementTimeout <= 0 {
+		problems = append(problems, "database statement timeout must be positive")
+	}
+	return problems
+}
+
+func (settings *Settings) IsCritical(dependency string) bool {
+	return contains(settings.Readiness.CriticalDependencies, dependency)
+}
+
+func (database *DatabaseSettings) ConnectionString() string {
+	parameters := []struct{ key, value string }{
+		{"host", database.Host},
+		{"port", strconv.Itoa(database.Port)},
+		{"user", database.User},
+		{"password", database.Password},
+		{"dbname", database.Name},
+		{"sslmode", database.SSLMode},
+		{"sslrootcert", database.SSLRootCert},
+		{"sslcert", database.SSLCert},
+		{"sslkey", database.SSLKey},
+		{"TimeZone", database.TimeZone},
+		{"statement_timeout", strconv.FormatInt(database.StatementTimeout.Milliseconds(), 10)},
+	}
+	var parts []string
+	for _, parameter := range parameters {
+		if parameter.value == "" {
+			continue
+		}
+		escaped := strings.ReplaceAll(strings.ReplaceAll(parameter.value, `\`, `\\`), `'`, `\'`)
+		parts = append(parts, fmt.Sprintf("%s='%s'", parameter.key, escaped))
+	}
+	return strings.Join(parts, " ")
+}
+
+func parseInt(value string, target *int) error {
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("'%s' is not an integer", value)
+	}
+	*target = parsed
+	return nil
+}
+
+func parseBool(value string, target *bool) error {
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return fmt.Errorf("'%s' is not a boolean", value)
+	}
+	*target = parsed
+	return nil
+}
+
+func parseDuration(value string, target *time.Duration) error {
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return fmt.Errorf("'%s' is not a duration", value)
+	}
+	*target = parsed
+	return nil
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if trimmed := strings.TrimSpace(item); trimmed != "" {
+			items = append(items, trimmed)
+		}
+	}
+	return items
+}
+
+func contains(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
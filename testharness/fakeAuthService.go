@@ -0,0 +1,103 @@
+package testharness
+
+import (
+	"config/models"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+type fakeAuthUser struct {
+	user        models.User
+	permissions map[string]bool
+}
+
+type FakeAuthService struct {
+	Server    *httptest.Server
+	mutex     sync.Mutex
+	users     map[string]fakeAuthUser
+	nextToken int
+	calls     map[string]int
+}
+
+var bearerPattern = regexp.MustCompile("(?i)^bearer (.*)$")
+
+func NewFakeAuthService() *FakeAuthService {
+	fake := &FakeAuthService{users: make(map[string]fakeAuthUser), calls: make(map[string]int)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/secure/authz-checks/", fake.handleAuthzCheck)
+	mux.HandleFunc("/secure/current-user", fake.handleCurrentUser)
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	fake.Server = httptest.NewServer(mux)
+	return fake
+}
+
+func (fake *FakeAuthService) URL() string {
+	return fake.Server.URL
+}
+
+func (fake *FakeAuthService) Close() {
+	fake.Server.Close()
+}
+
+func (fake *FakeAuthService) AddUser(user models.User, permissions ...string) string {
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
+	fake.nextToken++
+	token := fmt.Sprintf("token-%v-%v", fake.nextToken, user.UserID)
+	granted := make(map[string]bool)
+	for _, permission := range permissions {
+		granted[permission] = true
+	}
+	user.IsActive = true
+	fake.users[token] = fakeAuthUser{user: user, permissions: granted}
+	return token
+}
+
+func (fake *FakeAuthService) Calls(path string) int {
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
+	return fake.calls[path]
+}
+
+func (fake *FakeAuthService) authenticate(w http.ResponseWriter, r *http.Request) (fakeAuthUser, bool) {
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
+	fake.calls[r.URL.Path]++
+	tokens := bearerPattern.FindStringSubmatch(r.Header.Get("Authorization"))
+	if len(tokens) != 2 {
+		w.WriteHeader(http.StatusUnauthorized)
+		return fakeAuthUser{}, false
+	}
+	user, ok := fake.users[tokens[1]]
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		return fakeAuthUser{}, false
+	}
+	return user, true
+}
+
+func (fake *FakeAuthService) handleAuthzCheck(w http.ResponseWriter, r *http.Request) {
+	user, ok := fake.authenticate(w, r)
+	if !ok {
+		return
+	}
+	permission := strings.TrimPrefix(r.URL.Path, "/secure/authz-checks/")
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(user.permissions[permission])
+}
+
+func (fake *FakeAuthService) handleCurrentUser(w http.ResponseWriter, r *http.Request) {
+	user, ok := fake.authenticate(w, r)
+	if !ok {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(user.user)
+}
@@ -0,0 +1,170 @@
+package testharness
+
+import (
+	"bytes"
+	"config/caching"
+	"config/models"
+	"config/repositories"
+	"config/routers"
+	"config/utilities"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Harness struct {
+	t            testing.TB
+	Engine       *gin.Engine
+	Store        *repositories.MemoryStore
+	Repositories *repositories.Repositories
+	Cache        caching.CacheService
+	Auth         *FakeAuthService
+	shuttingDown chan struct{}
+	shutdown     sync.Once
+}
+
+type Caller struct {
+	harness *Harness
+	token   string
+	headers http.Header
+}
+
+func New(t testing.TB) *Harness {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	auth := NewFakeAuthService()
+	t.Cleanup(auth.Close)
+	shuttingDown := make(chan struct{})
+
+	store := repositories.NewMemoryStore()
+	repos := repositories.NewMemoryRepositories(store)
+	cacheService := caching.NewMemoryCacheService(1000)
+	authClient := utilities.NewAuthClient(auth.URL())
+	healthChecker := utilities.NewHealthChecker(time.Second)
+	healthChecker.AddCheck(utilities.HealthCheck{Name: "cache", Critical: true, Check: cacheService.Ping})
+	healthChecker.AddCheck(utilities.HealthCheck{Name: "auth", Critical: false, Check: authClient.Ping})
+
+	engine := gin.New()
+	engine.Use(gin.Recovery())
+	engine.Use(routers.RequestID())
+	routers.RegisterAll(engine, routers.Dependencies{
+		Repositories:      repos,
+		ChangeNotifier:    store,
+		HealthChecker:     healthChecker,
+		PermissionsHelper: utilities.NewPermissionHelper(authClient, cacheService),
+		ShuttingDown:      shuttingDown,
+	})
+
+	harness := &Harness{t: t, Engine: engine, Store: store, Repositories: repos, Cache: cacheService, Auth: auth, shuttingDown: shuttingDown}
+	t.Cleanup(harness.BeginShutdown)
+	return harness
+}
+
+// BeginShutdown signals the routes that the server has started draining, as
+// the lifecycle does on SIGTERM.
+func (harness *Harness) BeginShutdown() {
+	harness.shutdown.Do(func() { close(harness.shuttingDown) })
+}
+
+func (harness *Harness) Bootstrap() {
+	harness.t.Helper()
+	if err := utilities.BootstrapConfig(context.Background(), harness.Repositories.Transactor, harness.Repositories.ConfigSettings, harness.Repositories.Units); err != nil {
+		harness.t.Fatalf("bootstrapping config: %v", err)
+	}
+}
+
+func (harness *Harness) NewServer() *httptest.Server {
+	server := httptest.NewServer(harness.Engine)
+	harness.t.Cleanup(server.Close)
+	return server
+}
+
+func (harness *Harness) AsUser(userID string, permissions ...string) *Caller {
+	token := harness.Auth.AddUser(models.User{UserID: userID, GivenNames: []string{userID}}, permissions...)
+	return &Caller{harness: harness, token: token, headers: http.Header{}}
+}
+
+func (harness *Harness) Anonymous() *Caller {
+	return &Caller{harness: harness, headers: http.Header{}}
+}
+
+func (caller *Caller) Token() string {
+	return caller.token
+}
+
+func (caller *Caller) WithHeader(name string, value string) *Caller {
+	headers := caller.headers.Clone()
+	headers.Set(name, value)
+	return &Caller{harness: caller.harness, token: caller.token, headers: headers}
+}
+
+func (caller *Caller) Get(path string) *httptest.ResponseRecorder {
+	return caller.Do(context.Background(), http.MethodGet, path, nil)
+}
+
+func (caller *Caller) Post(path string, body interface{}) *httptest.ResponseRecorder {
+	return caller.Do(context.Background(), http.MethodPost, path, body)
+}
+
+func (caller *Caller) Put(path string, body interface{}) *httptest.ResponseRecorder {
+	return caller.Do(context.Background(), http.MethodPut, path, body)
+}
+
+func (caller *Caller) Delete(path string) *httptest.ResponseRecorder {
+	return caller.Do(context.Background(), http.MethodDelete, path, nil)
+}
+
+func (caller *Caller) Do(ctx context.Context, method string, path string, body interface{}) *httptest.ResponseRecorder {
+	caller.harness.t.Helper()
+	var reader io.Reader
+	switch value := body.(type) {
+	case nil:
+	case []byte:
+		reader = bytes.NewReader(value)
+	case string:
+		reader = bytes.NewReader([]byte(value))
+	default:
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			caller.harness.t.Fatalf("encoding request body: %v", err)
+		}
+		reader = bytes.NewReader(encoded)
+	}
+	req := httptest.NewRequest(method, path, reader).WithContext(ctx)
+	for name, values := range caller.headers {
+		req.Header[name] = values
+	}
+	if body != nil && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if caller.token != "" {
+		req.Header.Set("Authorization", "Bearer "+caller.token)
+	}
+	recorder := httptest.NewRecorder()
+	caller.harness.Engine.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func Decode[T any](t testing.TB, recorder *httptest.ResponseRecorder) T {
+	t.Helper()
+	var value T
+	if err := json.Unmarshal(recorder.Body.Bytes(), &value); err != nil {
+		t.Fatalf("decoding response body %q: %v", recorder.Body.String(), err)
+	}
+	return value
+}
+
+func ExpectStatus(t testing.TB, recorder *httptest.ResponseRecorder, expected int) {
+	t.Helper()
+	if recorder.Code != expected {
+		t.Fatalf("expected status %v, got %v: %s", expected, recorder.Code, recorder.Body.String())
+	}
+}
@@ -1,13 +1,22 @@
 package utilities
 
 import (
+	"config/metrics"
+	"config/models"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
+var ErrAuthenticationFailure = errors.New("authentication failure")
+
 type AuthClient struct {
 	authServiceEndpoint string
 }
@@ -16,12 +25,72 @@ func NewAuthClient(authServiceEndpoint string) *AuthClient {
 	return &AuthClient{authServiceEndpoint}
 }
 
-func (ac *AuthClient) IsAuthorized(subjectToken string, permission string) (bool, error) {
-	req, err := http.NewRequest(http.MethodGet, ac.authServiceEndpoint+"/secure/authz-checks/"+permission, nil)
+func (ac *AuthClient) IsAuthorized(ctx context.Context, subjectToken string, permission string) (bool, error) {
+	start := time.Now()
+	respBytes, err := ac.get(ctx, subjectToken, "/secure/authz-checks/"+permission)
 	if err != nil {
+		metrics.ObserveAuthCall("authz-check", start, err)
 		return false, err
 	}
-	client := &http.Client{}
+	var result *bool
+	json.Unmarshal(respBytes, &result)
+	if result == nil {
+		err = errors.New("unable to marshal response to bool")
+		metrics.ObserveAuthCall("authz-check", start, err)
+		return false, err
+	}
+	metrics.ObserveAuthCall("authz-check", start, nil)
+	return *result, nil
+}
+
+// GetCurrentUser resolves the caller through the auth service's current-user
+// endpoint: GET /secure/current-user with the caller's bearer token answers
+// 200 with the user as JSON (at least userId), or 401 when the token is not
+// valid. The fake auth service in testharness implements the same contract.
+func (ac *AuthClient) GetCurrentUser(ctx context.Context, subjectToken string) (*models.User, error) {
+	start := time.Now()
+	respBytes, err := ac.get(ctx, subjectToken, "/secure/current-user")
+	if err != nil {
+		metrics.ObserveAuthCall("current-user", start, err)
+		return nil, err
+	}
+	var user models.User
+	if err := json.Unmarshal(respBytes, &user); err != nil {
+		err = fmt.Errorf("unable to unmarshal current user: %w", err)
+		metrics.ObserveAuthCall("current-user", start, err)
+		return nil, err
+	}
+	if user.UserID == "" {
+		err = errors.New("current user response has no userId")
+		metrics.ObserveAuthCall("current-user", start, err)
+		return nil, err
+	}
+	metrics.ObserveAuthCall("current-user", start, nil)
+	return &user, nil
+}
+
+func (ac *AuthClient) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ac.authServiceEndpoint, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 500 {
+		return fmt.Errorf("auth service responded with status %v", resp.StatusCode)
+	}
+	return nil
+}
+
+func (ac *AuthClient) get(ctx context.Context, subjectToken string, path string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ac.authServiceEndpoint+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	client := &http.Client{Transport: otelhttp.NewTransport(http.DefaultTransport), Timeout: 10 * time.Second}
 	//Not sure if Go still requires this
 	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
 		for key, val := range via[0].Header {
@@ -32,19 +101,21 @@ func (ac *AuthClient) IsAuthorized(subjectToken string, permission string) (bool
 	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", subjectToken))
 	req.Header.Add("Content-Type", "application/json")
 	req.Header.Add("Accept", "application/json")
+	if requestID := RequestIDFromContext(ctx); requestID != "" {
+		req.Header.Add("X-Request-ID", requestID)
+	}
 	resp, err := client.Do(req)
 	if err != nil {
-		return false, err
+		log.Ctx(ctx).Error().Err(err).Msgf("auth service call to %s failed", path)
+		return nil, err
 	}
 	defer resp.Body.Close()
-	respBytes, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return false, err
+	log.Ctx(ctx).Debug().Msgf("auth service call to %s returned %v", path, resp.StatusCode)
+	if resp.StatusCode == http.StatusUnauthorized {
+		return nil, ErrAuthenticationFailure
 	}
-	var result *bool
-	json.Unmarshal(respBytes, &result)
-	if result == nil {
-		return false, errors.New("unable to marshal response to bool")
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("auth service call to %s responded with status %v", path, resp.StatusCode)
 	}
-	return *result, nil
+	return io.ReadAll(resp.Body)
 }
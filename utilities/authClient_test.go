@@ -0,0 +1,102 @@
+package utilities_test
+
+import (
+	"config/caching"
+	"config/models"
+	"config/testharness"
+	"config/utilities"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGetCurrentUserContract(t *testing.T) {
+	ctx := context.Background()
+	fake := testharness.NewFakeAuthService()
+	defer fake.Close()
+	token := fake.AddUser(models.User{UserID: "u-1", GivenNames: []string{"Ada"}, Roles: []string{"editor"}})
+
+	user, err := utilities.NewAuthClient(fake.URL()).GetCurrentUser(ctx, token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.UserID != "u-1" || user.DisplayName() != "Ada" || len(user.Roles) != 1 {
+		t.Fatalf("unexpected user %+v", user)
+	}
+	if fake.Calls("/secure/current-user") != 1 {
+		t.Fatalf("expected one call to /secure/current-user")
+	}
+	if _, err := utilities.NewAuthClient(fake.URL()).GetCurrentUser(ctx, "unknown"); err != utilities.ErrAuthenticationFailure {
+		t.Fatalf("expected an authentication failure, got %v", err)
+	}
+}
+
+func TestGetCurrentUserReportsBadResponses(t *testing.T) {
+	body, status := "", http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/secure/current-user" || r.Header.Get("Authorization") != "Bearer secret" {
+			t.Errorf("unexpected request %v %v", r.URL.Path, r.Header.Get("Authorization"))
+		}
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+	authClient := utilities.NewAuthClient(server.URL)
+
+	body = `{"userId": 42}`
+	if _, err := authClient.GetCurrentUser(context.Background(), "secret"); err == nil || !strings.Contains(err.Error(), "cannot unmarshal number") {
+		t.Fatalf("expected the unmarshal error, got %v", err)
+	}
+	body, status = "not found", http.StatusNotFound
+	if _, err := authClient.GetCurrentUser(context.Background(), "secret"); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Fatalf("expected the status in the error, got %v", err)
+	}
+}
+
+func TestGetUserDoesNotCacheTokens(t *testing.T) {
+	ctx := context.Background()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(models.User{UserID: "u-1", AuthToken: "session-token"})
+	}))
+	defer server.Close()
+	cacheService := caching.NewMemoryCacheService(10)
+	helper := utilities.NewPermissionHelper(utilities.NewAuthClient(server.URL), cacheService)
+
+	user, err := helper.GetUser(ctx, "bearer-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.AuthToken != "" {
+		t.Fatalf("expected the auth token to be dropped, got %q", user.AuthToken)
+	}
+	if found, _, _ := cacheService.Get(ctx, "USER|bearer-secret"); found {
+		t.Fatal("expected the cache key not to contain the bearer token")
+	}
+}
+
+func TestIsAuthorizedDoesNotCacheTokens(t *testing.T) {
+	ctx := context.Background()
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		json.NewEncoder(w).Encode(true)
+	}))
+	defer server.Close()
+	cacheService := caching.NewMemoryCacheService(10)
+	helper := utilities.NewPermissionHelper(utilities.NewAuthClient(server.URL), cacheService)
+
+	for i := 0; i < 2; i++ {
+		if authorized, err := helper.IsAuthorized(ctx, "bearer-secret", "product-view"); err != nil || !authorized {
+			t.Fatalf("expected the permission to be granted, got %v (%v)", authorized, err)
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("expected the second check to be served from the cache, got %v calls", calls)
+	}
+	if found, _, _ := cacheService.Get(ctx, "PERMISSIONS|bearer-secret"); found {
+		t.Fatal("expected the cache key not to contain the bearer token")
+	}
+}
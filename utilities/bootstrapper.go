@@ -8,15 +8,15 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
-func BootstrapConfig(config *repositories.ConfigSettingsRepository, units *repositories.UnitsRepository) error {
-	bootstrapped, err := config.GetOneFlag("IsBootstrapped")
+func BootstrapConfig(ctx context.Context, transactor repositories.Transactor, config repositories.ConfigSettingsRepository, units repositories.UnitsRepository) error {
+	bootstrapped, err := config.GetOneFlag(ctx, "IsBootstrapped")
 	if err != nil {
 		return err
 	}
 	if bootstrapped {
 		return nil
 	}
-	log.Warn().Msg("Bootstrapping config info")
+	log.Ctx(ctx).Warn().Msg("Bootstrapping config info")
 	defaultUnits := []models.Unit{
 		{FullName: "inch", FullNamePlural: "inches", Abbreviation: "in", MeasurementSystem: "US", UnitType: "linear"},
 		{FullName: "foot", FullNamePlural: "feet", Abbreviation: "ft", MeasurementSystem: "US", UnitType: "linear"},
@@ -75,36 +75,19 @@ func BootstrapConfig(config *repositories.ConfigSettingsRepository, units *repos
 		{FullName: "day", FullNamePlural: "days", Abbreviation: "day", MeasurementSystem: "none", UnitType: "time"},
 	}
 
-	tx, err := config.GetUnderlyingConnection().Begin(context.Background())
-	if err != nil {
-		tx.Rollback(context.Background())
-		return err
-	}
-
-	if err := config.Upsert("IsBootstrapped", []string{"true"}, tx); err != nil {
-		tx.Rollback(context.Background())
-		return err
-	}
-	if err := config.Upsert("Modifiers", []string{"top", "bottom", "left", "right", "middle", "upper", "lower", "center", "inside", "outside", "warp", "fill"}, tx); err != nil {
-		tx.Rollback(context.Background())
-		return err
-	}
-	if err := config.Upsert("MeasurementSystems", []string{"metric", "US", "none"}, tx); err != nil {
-		tx.Rollback(context.Background())
-		return err
-	}
-	if err := config.Upsert("UnitTypes", []string{"linear", "area", "volume", "weight", "mass", "velocity", "acceleration", "pressure", "time"}, tx); err != nil {
-		tx.Rollback(context.Background())
-		return err
-	}
-	if err := units.InsertMany(&defaultUnits, "auto", tx); err != nil {
-		tx.Rollback(context.Background())
-		return err
-	}
-
-	if err = tx.Commit(context.Background()); err != nil {
-		return err
-	}
-
-	return nil
+	return transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := config.Upsert(ctx, "IsBootstrapped", []string{"true"}, "auto"); err != nil {
+			return err
+		}
+		if err := config.Upsert(ctx, "Modifiers", []string{"top", "bottom", "left", "right", "middle", "upper", "lower", "center", "inside", "outside", "warp", "fill"}, "auto"); err != nil {
+			return err
+		}
+		if err := config.Upsert(ctx, "MeasurementSystems", []string{"metric", "US", "none"}, "auto"); err != nil {
+			return err
+		}
+		if err := config.Upsert(ctx, "UnitTypes", []string{"linear", "area", "volume", "weight", "mass", "velocity", "acceleration", "pressure", "time"}, "auto"); err != nil {
+			return err
+		}
+		return units.InsertMany(ctx, &defaultUnits, "auto")
+	})
 }
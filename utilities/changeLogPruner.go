@@ -0,0 +1,46 @@
+package utilities
+
+import (
+	"config/repositories"
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const changeLogPruneInterval = time.Hour
+
+// ChangeLogPruner drops changes older than the retention, which also bounds
+// how far back a change stream can ask to resume.
+type ChangeLogPruner struct {
+	changeLogRepo repositories.ChangeLogRepository
+	retention     time.Duration
+}
+
+func NewChangeLogPruner(changeLogRepo repositories.ChangeLogRepository, retention time.Duration) *ChangeLogPruner {
+	return &ChangeLogPruner{changeLogRepo: changeLogRepo, retention: retention}
+}
+
+func (pruner *ChangeLogPruner) Run(ctx context.Context) error {
+	ticker := time.NewTicker(changeLogPruneInterval)
+	defer ticker.Stop()
+	for {
+		pruner.prune(ctx)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (pruner *ChangeLogPruner) prune(ctx context.Context) {
+	pruned, err := pruner.changeLogRepo.Prune(ctx, time.Now().Add(-pruner.retention))
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("error pruning the change log")
+		return
+	}
+	if pruned > 0 {
+		log.Ctx(ctx).Info().Msgf("pruned %v changes older than %v", pruned, pruner.retention)
+	}
+}
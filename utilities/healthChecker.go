@@ -0,0 +1,72 @@
+package utilities
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type HealthCheck struct {
+	Name     string
+	Critical bool
+	Check    func(ctx context.Context) error
+}
+
+type DependencyStatus struct {
+	Name      string  `json:"name"`
+	Status    string  `json:"status"`
+	Critical  bool    `json:"critical"`
+	LatencyMs float64 `json:"latencyMs"`
+	Error     string  `json:"error,omitempty"`
+}
+
+type ReadinessReport struct {
+	Status       string             `json:"status"`
+	Dependencies []DependencyStatus `json:"dependencies"`
+}
+
+type HealthChecker struct {
+	checks  []HealthCheck
+	timeout time.Duration
+}
+
+func NewHealthChecker(timeout time.Duration) *HealthChecker {
+	return &HealthChecker{timeout: timeout}
+}
+
+func (hc *HealthChecker) AddCheck(check HealthCheck) {
+	hc.checks = append(hc.checks, check)
+}
+
+func (hc *HealthChecker) CheckReadiness(ctx context.Context) ReadinessReport {
+	ctx, cancel := context.WithTimeout(ctx, hc.timeout)
+	defer cancel()
+	dependencies := make([]DependencyStatus, len(hc.checks))
+	var wg sync.WaitGroup
+	for i, check := range hc.checks {
+		wg.Add(1)
+		go func(i int, check HealthCheck) {
+			defer wg.Done()
+			start := time.Now()
+			err := check.Check(ctx)
+			dependencies[i] = DependencyStatus{
+				Name:      check.Name,
+				Status:    "up",
+				Critical:  check.Critical,
+				LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+			}
+			if err != nil {
+				dependencies[i].Status = "down"
+				dependencies[i].Error = err.Error()
+			}
+		}(i, check)
+	}
+	wg.Wait()
+	report := ReadinessReport{Status: "ready", Dependencies: dependencies}
+	for _, dependency := range dependencies {
+		if dependency.Critical && dependency.Status != "up" {
+			report.Status = "not ready"
+		}
+	}
+	return report
+}
@@ -0,0 +1,336 @@
+package utilities
+
+import (
+	"config/models"
+	"config/repositories"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	defaultImportBatchSize = 500
+	maxImportBatchSize     = 5000
+)
+
+var (
+	errImportDryRun   = errors.New("import dry run")
+	errImportRejected = errors.New("import rejected")
+)
+
+type ImportOptions struct {
+	Mode      string
+	WriteMode string
+	DryRun    bool
+	BatchSize int
+	Columns   map[string]string
+	By        string
+}
+
+type Importer struct {
+	transactor repositories.Transactor
+	products   repositories.ProductsRepository
+	tests      repositories.TestsRepository
+	config     repositories.ConfigSettingsRepository
+}
+
+type importColumn[T any] struct {
+	field    string
+	required bool
+	set      func(item *T, value string)
+}
+
+type importSpec[T any] struct {
+	columns  []importColumn[T]
+	key      func(item *T) string
+	validate func(item *T) []models.ImportRowError
+	create   func(ctx context.Context, item *T, by string) error
+	update   func(ctx context.Context, item *T, by string) error
+}
+
+type importRow[T any] struct {
+	number int
+	item   T
+}
+
+func NewImporter(transactor repositories.Transactor, products repositories.ProductsRepository, tests repositories.TestsRepository, config repositories.ConfigSettingsRepository) *Importer {
+	return &Importer{transactor: transactor, products: products, tests: tests, config: config}
+}
+
+func (importer *Importer) ImportProducts(ctx context.Context, table *Table, options ImportOptions) (*models.ImportReport, error) {
+	return runImport(ctx, importer.transactor, importSpec[models.Product]{
+		columns: []importColumn[models.Product]{
+			{field: "productCode", required: true, set: func(product *models.Product, value string) { product.ProductCode = value }},
+			{field: "description", required: true, set: func(product *models.Product, value string) { product.Description = value }},
+		},
+		key:    func(product *models.Product) string { return product.ProductCode },
+		create: importer.products.Create,
+		update: importer.products.Update,
+	}, table, options)
+}
+
+func (importer *Importer) ImportTests(ctx context.Context, table *Table, options ImportOptions) (*models.ImportReport, error) {
+	unitTypes, err := importer.config.GetOne(ctx, "UnitTypes")
+	if err != nil {
+		return nil, err
+	}
+	modifiers, err := importer.config.GetOne(ctx, "Modifiers")
+	if err != nil {
+		return nil, err
+	}
+	return runImport(ctx, importer.transactor, importSpec[models.Test]{
+		columns: []importColumn[models.Test]{
+			{field: "testName", required: true, set: func(test *models.Test, value string) { test.TestName = value }},
+			{field: "unitType", required: true, set: func(test *models.Test, value string) { test.UnitType = value }},
+			{field: "references", set: func(test *models.Test, value string) { test.References = splitImportList(value) }},
+			{field: "standards", set: func(test *models.Test, value string) { test.Standards = splitImportList(value) }},
+			{field: "availableModifiers", set: func(test *models.Test, value string) { test.AvailableModifiers = splitImportList(value) }},
+		},
+		key: func(test *models.Test) string { return test.TestName },
+		validate: func(test *models.Test) []models.ImportRowError {
+			var rowErrors []models.ImportRowError
+			if test.UnitType != "" && !containsValue(unitTypes, test.UnitType) {
+				rowErrors = append(rowErrors, models.ImportRowError{Column: "unitType", Message: fmt.Sprintf("unknown unit type '%v'", test.UnitType)})
+			}
+			for _, modifier := range test.AvailableModifiers {
+				if !containsValue(modifiers, modifier) {
+					rowErrors = append(rowErrors, models.ImportRowError{Column: "availableModifiers", Message: fmt.Sprintf("unknown modifier '%v'", modifier)})
+				}
+			}
+			return rowErrors
+		},
+		create: importer.tests.Create,
+		update: importer.tests.Update,
+	}, table, options)
+}
+
+func runImport[T any](ctx context.Context, transactor repositories.Transactor, spec importSpec[T], table *Table, options ImportOptions) (*models.ImportReport, error) {
+	if options.Mode == "" {
+		options.Mode = models.ImportModeAllOrNothing
+	}
+	if options.Mode != models.ImportModeAllOrNothing && options.Mode != models.ImportModeBestEffort {
+		return nil, fmt.Errorf("%w: unknown mode '%v'", ErrInvalidImportFile, options.Mode)
+	}
+	if options.WriteMode == "" {
+		options.WriteMode = models.ImportWriteModeInsert
+	}
+	if options.WriteMode != models.ImportWriteModeInsert && options.WriteMode != models.ImportWriteModeUpsert {
+		return nil, fmt.Errorf("%w: unknown write mode '%v'", ErrInvalidImportFile, options.WriteMode)
+	}
+	if options.BatchSize <= 0 {
+		options.BatchSize = defaultImportBatchSize
+	}
+	if options.BatchSize > maxImportBatchSize {
+		options.BatchSize = maxImportBatchSize
+	}
+	indexes, err := resolveImportColumns(spec.columns, table.Header, options.Columns)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.ImportReport{Mode: options.Mode, WriteMode: options.WriteMode, DryRun: options.DryRun, Errors: []models.ImportRowError{}}
+	var rows []importRow[T]
+	seen := make(map[string]int)
+	for i, record := range table.Rows {
+		if isBlankRecord(record) {
+			continue
+		}
+		report.TotalRows++
+		row := importRow[T]{number: table.line(i)}
+		var rowErrors []models.ImportRowError
+		for c, column := range spec.columns {
+			value := ""
+			if indexes[c] >= 0 && indexes[c] < len(record) {
+				value = strings.TrimSpace(record[indexes[c]])
+			}
+			if column.required && value == "" {
+				rowErrors = append(rowErrors, models.ImportRowError{Column: column.field, Message: column.field + " is required"})
+			}
+			column.set(&row.item, value)
+		}
+		if spec.validate != nil {
+			rowErrors = append(rowErrors, spec.validate(&row.item)...)
+		}
+		key := spec.key(&row.item)
+		if first, duplicate := seen[repositories.FoldKey(key)]; duplicate && key != "" {
+			rowErrors = append(rowErrors, models.ImportRowError{Message: fmt.Sprintf("duplicate of row %v", first)})
+		} else {
+			seen[repositories.FoldKey(key)] = row.number
+		}
+		if len(rowErrors) > 0 {
+			for _, rowError := range rowErrors {
+				rowError.Row, rowError.Key = row.number, key
+				report.Errors = append(report.Errors, rowError)
+			}
+			report.Failed++
+			continue
+		}
+		rows = append(rows, row)
+	}
+	if report.Failed > 0 && options.Mode == models.ImportModeAllOrNothing {
+		return report, nil
+	}
+
+	// Counts only reach the report once their batch has committed, so a report
+	// returned alongside an error still describes exactly what was written.
+	batchesCommitted := 0
+	failedAt := 0
+	write := func(ctx context.Context) error {
+		for start := 0; start < len(rows); start += options.BatchSize {
+			end := start + options.BatchSize
+			if end > len(rows) {
+				end = len(rows)
+			}
+			var created, updated int
+			var rowErrors []models.ImportRowError
+			err := transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+				for i := range rows[start:end] {
+					row := &rows[start+i]
+					wasCreated, err := writeImportRow(ctx, transactor, spec, &row.item, options)
+					var domainError *repositories.DomainError
+					if errors.As(err, &domainError) {
+						rowErrors = append(rowErrors, models.ImportRowError{Row: row.number, Key: spec.key(&row.item), Message: err.Error()})
+						if options.Mode == models.ImportModeAllOrNothing {
+							return errImportRejected
+						}
+						continue
+					}
+					if err != nil {
+						failedAt = row.number
+						return err
+					}
+					if wasCreated {
+						created++
+					} else {
+						updated++
+					}
+				}
+				return nil
+			})
+			report.Errors = append(report.Errors, rowErrors...)
+			report.Failed += len(rowErrors)
+			if err != nil {
+				// A failed commit is not any one row's fault, so it is
+				// reported against the first row of the batch.
+				if failedAt == 0 {
+					failedAt = rows[start].number
+				}
+				return err
+			}
+			report.Created += created
+			report.Updated += updated
+			batchesCommitted++
+		}
+		if options.DryRun {
+			return errImportDryRun
+		}
+		return nil
+	}
+	atomic := options.Mode == models.ImportModeAllOrNothing || options.DryRun
+	if atomic {
+		err = transactor.WithinTransaction(ctx, write)
+	} else {
+		err = write(ctx)
+	}
+	switch {
+	case errors.Is(err, errImportDryRun):
+		return report, nil
+	case errors.Is(err, errImportRejected):
+		report.Created, report.Updated = 0, 0
+		return report, nil
+	case err != nil:
+		if atomic {
+			report.Created, report.Updated = 0, 0
+			if failedAt == 0 && len(rows) > 0 {
+				failedAt = rows[0].number
+			}
+		} else {
+			report.Committed = batchesCommitted > 0
+		}
+		report.Errors = append(report.Errors, models.ImportRowError{Row: failedAt, Message: "import stopped by an unexpected error; this row and the rest of its batch were not written, nor were any later rows"})
+		return report, err
+	}
+	report.Committed = true
+	return report, nil
+}
+
+// writeImportRow runs each write in its own savepoint so a failed row does not
+// abort the surrounding batch. Rows that already exist are reported as
+// conflicts unless the import was asked to upsert, when they are updated.
+func writeImportRow[T any](ctx context.Context, transactor repositories.Transactor, spec importSpec[T], item *T, options ImportOptions) (bool, error) {
+	err := transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		return spec.create(ctx, item, options.By)
+	})
+	if errors.Is(err, repositories.ErrAlreadyExists) && options.WriteMode == models.ImportWriteModeUpsert {
+		return false, transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+			return spec.update(ctx, item, options.By)
+		})
+	}
+	return err == nil, err
+}
+
+func resolveImportColumns[T any](columns []importColumn[T], header []string, mapping map[string]string) ([]int, error) {
+	for field := range mapping {
+		known := false
+		for _, column := range columns {
+			known = known || column.field == field
+		}
+		if !known {
+			return nil, fmt.Errorf("%w: unknown column mapping for '%v'", ErrInvalidImportFile, field)
+		}
+	}
+	indexes := make([]int, len(columns))
+	for c, column := range columns {
+		indexes[c] = -1
+		name, mapped := mapping[column.field]
+		if !mapped {
+			name = column.field
+		}
+		for h, heading := range header {
+			if normalizeHeading(heading) == normalizeHeading(name) {
+				indexes[c] = h
+				break
+			}
+		}
+		if indexes[c] < 0 && (mapped || column.required) {
+			return nil, fmt.Errorf("%w: no column '%v' for %v", ErrInvalidImportFile, name, column.field)
+		}
+	}
+	return indexes, nil
+}
+
+func normalizeHeading(heading string) string {
+	return strings.ToLower(strings.NewReplacer(" ", "", "_", "", "-", "").Replace(strings.TrimSpace(heading)))
+}
+
+func splitImportList(value string) []string {
+	values := []string{}
+	for _, part := range strings.Split(value, ";") {
+		if part = strings.TrimSpace(part); part != "" {
+			values = append(values, part)
+		}
+	}
+	return values
+}
+
+func isBlankRecord(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func containsValue(values *[]string, value string) bool {
+	if values == nil {
+		return false
+	}
+	for _, candidate := range *values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
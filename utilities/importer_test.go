@@ -0,0 +1,107 @@
+package utilities
+
+import (
+	"config/models"
+	"config/repositories"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type batchKey struct{}
+
+// commitFailingTransactor fails the commit of every outermost transaction
+// after its work succeeded, as a dropped connection would.
+type commitFailingTransactor struct {
+	repositories.Transactor
+}
+
+func (transactor commitFailingTransactor) WithinTransaction(ctx context.Context, work func(ctx context.Context) error) error {
+	if ctx.Value(batchKey{}) != nil {
+		return transactor.Transactor.WithinTransaction(ctx, work)
+	}
+	return transactor.Transactor.WithinTransaction(context.WithValue(ctx, batchKey{}, true), func(ctx context.Context) error {
+		if err := work(ctx); err != nil {
+			return err
+		}
+		return errors.New("commit failed")
+	})
+}
+
+func TestBestEffortImportReportsCommittedBatchesOnFailure(t *testing.T) {
+	store := repositories.NewMemoryStore()
+	products := repositories.NewMemoryProductsRepository(store)
+	spec := importSpec[models.Product]{
+		columns: []importColumn[models.Product]{
+			{field: "productCode", required: true, set: func(product *models.Product, value string) { product.ProductCode = value }},
+		},
+		key: func(product *models.Product) string { return product.ProductCode },
+		create: func(ctx context.Context, product *models.Product, by string) error {
+			if product.ProductCode == "P-300" {
+				return errors.New("connection reset")
+			}
+			return products.Create(ctx, product, by)
+		},
+		update: products.Update,
+	}
+	table := &Table{Header: []string{"productCode"}, Rows: [][]string{{"P-100"}, {"P-200"}, {"P-300"}, {"P-400"}}}
+
+	report, err := runImport(context.Background(), store, spec, table, ImportOptions{Mode: models.ImportModeBestEffort, BatchSize: 2, By: "importer"})
+	if err == nil || report == nil {
+		t.Fatalf("expected the error together with a report, got %+v, %v", report, err)
+	}
+	if !report.Committed || report.Created != 2 || len(report.Errors) != 1 || report.Errors[0].Row != 4 {
+		t.Fatalf("expected the first batch to be reported as committed, got %+v", report)
+	}
+	if _, err := products.GetOne(context.Background(), "P-200"); err != nil {
+		t.Fatalf("expected the committed batch to be saved, got %v", err)
+	}
+	if _, err := products.GetOne(context.Background(), "P-400"); !errors.Is(err, repositories.ErrNotFound) {
+		t.Fatalf("expected rows after the failure not to be written, got %v", err)
+	}
+}
+
+func TestImportReportsTheBatchWhoseCommitFailed(t *testing.T) {
+	store := repositories.NewMemoryStore()
+	products := repositories.NewMemoryProductsRepository(store)
+	spec := importSpec[models.Product]{
+		columns: []importColumn[models.Product]{
+			{field: "productCode", required: true, set: func(product *models.Product, value string) { product.ProductCode = value }},
+		},
+		key:    func(product *models.Product) string { return product.ProductCode },
+		create: products.Create,
+		update: products.Update,
+	}
+	table := &Table{Header: []string{"productCode"}, Rows: [][]string{{"P-100"}, {"P-200"}, {"P-300"}}}
+
+	for _, mode := range []string{models.ImportModeBestEffort, models.ImportModeAllOrNothing} {
+		report, err := runImport(context.Background(), commitFailingTransactor{store}, spec, table, ImportOptions{Mode: mode, BatchSize: 2, By: "importer"})
+		if err == nil || report == nil || report.Committed {
+			t.Fatalf("%v: expected the commit error with an uncommitted report, got %+v, %v", mode, report, err)
+		}
+		if len(report.Errors) != 1 || report.Errors[0].Row != 2 {
+			t.Fatalf("%v: expected the failure reported at the first row of the batch, got %+v", mode, report.Errors)
+		}
+	}
+}
+
+func TestImportReportsFileLines(t *testing.T) {
+	csv := "productCode,description\n" +
+		"P-100,\"Plain\nweave\"\n" +
+		"\n" +
+		"P-200,\n"
+	table, err := ReadTable(strings.NewReader(csv), TableFormatCSV, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := repositories.NewMemoryStore()
+	importer := NewImporter(store, repositories.NewMemoryProductsRepository(store), nil, nil)
+	report, err := importer.ImportProducts(context.Background(), table, ImportOptions{By: "importer"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Errors) != 1 || report.Errors[0].Key != "P-200" || report.Errors[0].Row != 5 {
+		t.Fatalf("expected the missing description reported on line 5, got %+v", report.Errors)
+	}
+}
@@ -0,0 +1,115 @@
+package utilities
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+type lifecycleWorker struct {
+	name string
+	run  func(ctx context.Context) error
+}
+
+type lifecycleCloser struct {
+	name  string
+	close func(ctx context.Context) error
+}
+
+type Lifecycle struct {
+	shutdownTimeout time.Duration
+	workers         []lifecycleWorker
+	closers         []lifecycleCloser
+	shuttingDown    chan struct{}
+}
+
+func NewLifecycle(shutdownTimeout time.Duration) *Lifecycle {
+	return &Lifecycle{shutdownTimeout: shutdownTimeout, shuttingDown: make(chan struct{})}
+}
+
+func (lc *Lifecycle) AddWorker(name string, run func(ctx context.Context) error) {
+	lc.workers = append(lc.workers, lifecycleWorker{name, run})
+}
+
+func (lc *Lifecycle) AddCloser(name string, close func(ctx context.Context) error) {
+	lc.closers = append(lc.closers, lifecycleCloser{name, close})
+}
+
+func (lc *Lifecycle) ShuttingDown() <-chan struct{} {
+	return lc.shuttingDown
+}
+
+func (lc *Lifecycle) Run(server *http.Server) error {
+	signalCtx, stopSignals := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stopSignals()
+
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	var workersDone sync.WaitGroup
+	for _, worker := range lc.workers {
+		workersDone.Add(1)
+		go func(worker lifecycleWorker) {
+			defer workersDone.Done()
+			log.Info().Msgf("starting %s", worker.name)
+			if err := worker.run(workersCtx); err != nil {
+				log.Error().Err(err).Msgf("%s stopped with error", worker.name)
+				return
+			}
+			log.Info().Msgf("%s stopped", worker.name)
+		}(worker)
+	}
+
+	serverErr := make(chan error, 1)
+	go func() {
+		log.Info().Msgf("listening on %s", server.Addr)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+		close(serverErr)
+	}()
+
+	var runErr error
+	select {
+	case <-signalCtx.Done():
+		log.Info().Msg("shutdown signal received, draining requests")
+	case err := <-serverErr:
+		runErr = err
+		log.Error().Err(err).Msg("server stopped unexpectedly, shutting down")
+	}
+	close(lc.shuttingDown)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), lc.shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Error().Err(err).Msg("server did not drain before the shutdown deadline")
+		server.Close()
+	}
+
+	stopWorkers()
+	workersStopped := make(chan struct{})
+	go func() {
+		workersDone.Wait()
+		close(workersStopped)
+	}()
+	select {
+	case <-workersStopped:
+	case <-shutdownCtx.Done():
+		log.Error().Msg("background workers did not stop before the shutdown deadline")
+	}
+
+	for i := len(lc.closers) - 1; i >= 0; i-- {
+		closer := lc.closers[i]
+		if err := closer.close(shutdownCtx); err != nil {
+			log.Error().Err(err).Msgf("error closing %s", closer.name)
+			continue
+		}
+		log.Info().Msgf("closed %s", closer.name)
+	}
+	return runErr
+}
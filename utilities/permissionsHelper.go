@@ -1,24 +1,42 @@
 package utilities
 
-import "strings"
+import (
+	"config/caching"
+	"config/metrics"
+	"config/models"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const authCacheTTL = 15 * time.Minute
 
 type PermissionsHelper struct {
 	authClient   *AuthClient
-	cacheService *CacheService
+	cacheService caching.CacheService
 }
 
-func NewPermissionHelper(authClient *AuthClient, cacheService *CacheService) *PermissionsHelper {
+func NewPermissionHelper(authClient *AuthClient, cacheService caching.CacheService) *PermissionsHelper {
 	return &PermissionsHelper{
 		authClient,
 		cacheService,
 	}
 }
-func (ph *PermissionsHelper) IsAuthorized(bearerToken string, permission string) (bool, error) {
-	foundInCache, valuesPipeJoined, err := ph.cacheService.Get("PERMISSIONS|" + bearerToken)
+
+// IsAuthorized caches granted permissions under the same token hash as GetUser.
+func (ph *PermissionsHelper) IsAuthorized(ctx context.Context, bearerToken string, permission string) (bool, error) {
+	key := "PERMISSIONS|" + hashToken(bearerToken)
+	foundInCache, valuesPipeJoined, err := ph.cacheService.Get(ctx, key)
 	if foundInCache {
 		values := strings.Split(valuesPipeJoined, "|")
 		for _, value := range values {
 			if value == permission {
+				metrics.CacheHit("permissions")
 				return true, nil
 			}
 		}
@@ -26,7 +44,8 @@ func (ph *PermissionsHelper) IsAuthorized(bearerToken string, permission string)
 	if err != nil {
 		return false, err
 	}
-	isAuthorized, err := ph.authClient.IsAuthorized(bearerToken, permission)
+	metrics.CacheMiss("permissions")
+	isAuthorized, err := ph.authClient.IsAuthorized(ctx, bearerToken, permission)
 	if err != nil {
 		return false, err
 	}
@@ -36,7 +55,45 @@ func (ph *PermissionsHelper) IsAuthorized(bearerToken string, permission string)
 		} else {
 			valuesPipeJoined = permission
 		}
-		ph.cacheService.Set("PERMISSIONS|"+bearerToken, valuesPipeJoined)
+		if err := ph.cacheService.Set(ctx, key, valuesPipeJoined, authCacheTTL); err != nil {
+			log.Ctx(ctx).Warn().Err(err).Msg("unable to cache permissions")
+		}
 	}
 	return isAuthorized, nil
 }
+
+// GetUser caches the resolved user under a hash of the bearer token, and never
+// with the user's own auth token, so the cache holds no usable credentials.
+func (ph *PermissionsHelper) GetUser(ctx context.Context, bearerToken string) (*models.User, error) {
+	key := "USER|" + hashToken(bearerToken)
+	foundInCache, userJSON, err := ph.cacheService.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if foundInCache {
+		var user models.User
+		if err := json.Unmarshal([]byte(userJSON), &user); err == nil {
+			metrics.CacheHit("users")
+			return &user, nil
+		}
+	}
+	metrics.CacheMiss("users")
+	user, err := ph.authClient.GetCurrentUser(ctx, bearerToken)
+	if err != nil {
+		return nil, err
+	}
+	user.AuthToken = ""
+	userBytes, err := json.Marshal(user)
+	if err != nil {
+		return nil, err
+	}
+	if err := ph.cacheService.Set(ctx, key, string(userBytes), authCacheTTL); err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("unable to cache current user")
+	}
+	return user, nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
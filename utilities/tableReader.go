@@ -0,0 +1,91 @@
+package utilities
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	TableFormatCSV  = "csv"
+	TableFormatXLSX = "xlsx"
+)
+
+var ErrInvalidImportFile = errors.New("invalid import file")
+
+// Table holds the rows under the header together with the line each row
+// starts on, which is what import reports point at.
+type Table struct {
+	Header []string
+	Rows   [][]string
+	Lines  []int
+}
+
+func ReadTable(reader io.Reader, format string, sheet string) (*Table, error) {
+	var records [][]string
+	var lines []int
+	switch format {
+	case TableFormatCSV:
+		csvReader := csv.NewReader(reader)
+		csvReader.FieldsPerRecord = -1
+		csvReader.TrimLeadingSpace = true
+		// Records are read one at a time because blank lines and quoted line
+		// breaks mean a record's index is not its line in the file.
+		for {
+			record, err := csvReader.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidImportFile, err)
+			}
+			// Undo the formula escaping of our own exports so they import back
+			// as the values they were exported from.
+			for i := range record {
+				record[i] = unescapeFormula(record[i])
+			}
+			line, _ := csvReader.FieldPos(0)
+			records = append(records, record)
+			lines = append(lines, line)
+		}
+	case TableFormatXLSX:
+		workbook, err := excelize.OpenReader(reader)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidImportFile, err)
+		}
+		defer workbook.Close()
+		if sheet == "" {
+			sheet = workbook.GetSheetName(0)
+		}
+		if records, err = workbook.GetRows(sheet); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidImportFile, err)
+		}
+		lines = make([]int, len(records))
+		for i := range lines {
+			lines[i] = i + 1
+		}
+	default:
+		return nil, fmt.Errorf("%w: unsupported format '%v'", ErrInvalidImportFile, format)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%w: missing header row", ErrInvalidImportFile)
+	}
+	header := records[0]
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+	return &Table{Header: header, Rows: records[1:], Lines: lines[1:]}, nil
+}
+
+// line returns the line row i of the table starts on, assuming one line per
+// row after the header when the table was not read from a file.
+func (table *Table) line(i int) int {
+	if i < len(table.Lines) {
+		return table.Lines[i]
+	}
+	return i + 2
+}
@@ -0,0 +1,121 @@
+package utilities
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+const exportSheetName = "Sheet1"
+
+// formulaPrefixes are the leading characters spreadsheets treat as the start
+// of a formula when they open a CSV file.
+const formulaPrefixes = "=+-@\t\r"
+
+type TableWriter interface {
+	WriteRow(values []string) error
+	Close() error
+	Discard()
+}
+
+type csvTableWriter struct {
+	writer *csv.Writer
+}
+
+// xlsxTableWriter buffers rows in excelize's stream writer, which spills to a
+// temporary file rather than memory, and only writes the workbook on Close.
+type xlsxTableWriter struct {
+	out      io.Writer
+	workbook *excelize.File
+	stream   *excelize.StreamWriter
+	row      int
+}
+
+func NewTableWriter(out io.Writer, format string, header []string) (TableWriter, error) {
+	var writer TableWriter
+	switch format {
+	case TableFormatCSV:
+		writer = &csvTableWriter{writer: csv.NewWriter(out)}
+	case TableFormatXLSX:
+		workbook := excelize.NewFile()
+		stream, err := workbook.NewStreamWriter(exportSheetName)
+		if err != nil {
+			workbook.Close()
+			return nil, err
+		}
+		writer = &xlsxTableWriter{out: out, workbook: workbook, stream: stream}
+	default:
+		return nil, fmt.Errorf("unsupported format '%v'", format)
+	}
+	if err := writer.WriteRow(header); err != nil {
+		return nil, err
+	}
+	return writer, nil
+}
+
+// WriteRow quotes any value a spreadsheet would evaluate as a formula, so an
+// exported key such as =HYPERLINK(...) opens as the text it was stored as.
+func (writer *csvTableWriter) WriteRow(values []string) error {
+	escaped := make([]string, len(values))
+	for i, value := range values {
+		escaped[i] = escapeFormula(value)
+	}
+	return writer.writer.Write(escaped)
+}
+
+func (writer *csvTableWriter) Close() error {
+	writer.writer.Flush()
+	return writer.writer.Error()
+}
+
+func (writer *csvTableWriter) Discard() {}
+
+// WriteRow writes every value as an inline string cell, which spreadsheets
+// never evaluate, so unlike CSV the values need no escaping.
+func (writer *xlsxTableWriter) WriteRow(values []string) error {
+	writer.row++
+	cell, err := excelize.CoordinatesToCellName(1, writer.row)
+	if err != nil {
+		return err
+	}
+	cells := make([]interface{}, len(values))
+	for i, value := range values {
+		cells[i] = value
+	}
+	return writer.stream.SetRow(cell, cells)
+}
+
+func (writer *xlsxTableWriter) Close() error {
+	defer writer.workbook.Close()
+	if err := writer.stream.Flush(); err != nil {
+		return err
+	}
+	_, err := writer.workbook.WriteTo(writer.out)
+	return err
+}
+
+func (writer *xlsxTableWriter) Discard() {
+	writer.workbook.Close()
+}
+
+// escapeFormula leaves signed numbers alone since they cannot run anything.
+func escapeFormula(value string) string {
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return value
+	}
+	if value != "" && strings.ContainsRune(formulaPrefixes, rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+func unescapeFormula(value string) string {
+	if len(value) > 1 && value[0] == '\'' && strings.ContainsRune(formulaPrefixes, rune(value[1])) {
+		return value[1:]
+	}
+	return value
+}
@@ -0,0 +1,151 @@
+package utilities
+
+import (
+	"bytes"
+	"config/models"
+	"config/repositories"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	webhookBatchSize   = 20
+	webhookLease       = time.Minute
+	webhookMaxAttempts = 8
+	webhookBaseBackoff = 10 * time.Second
+	webhookMaxBackoff  = time.Hour
+
+	webhookPruneInterval = time.Hour
+)
+
+type WebhookDispatcher struct {
+	webhooksRepo repositories.WebhooksRepository
+	httpClient   *http.Client
+	pollInterval time.Duration
+	retention    time.Duration
+}
+
+// NewWebhookDispatcher returns a dispatcher that also prunes delivered and
+// dead deliveries once they are older than retention.
+func NewWebhookDispatcher(webhooksRepo repositories.WebhooksRepository, retention time.Duration) *WebhookDispatcher {
+	return &WebhookDispatcher{
+		webhooksRepo: webhooksRepo,
+		httpClient:   newWebhookHTTPClient(),
+		pollInterval: 2 * time.Second,
+		retention:    retention,
+	}
+}
+
+func SignWebhookPayload(secret string, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (wd *WebhookDispatcher) Run(ctx context.Context) error {
+	ticker := time.NewTicker(wd.pollInterval)
+	defer ticker.Stop()
+	pruneTicker := time.NewTicker(webhookPruneInterval)
+	defer pruneTicker.Stop()
+	wd.prune(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-pruneTicker.C:
+			wd.prune(ctx)
+			continue
+		case <-ticker.C:
+		}
+		deliveries, err := wd.webhooksRepo.ClaimDueDeliveries(ctx, webhookBatchSize, webhookLease)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("error claiming webhook deliveries")
+			continue
+		}
+		for _, delivery := range *deliveries {
+			wd.dispatch(ctx, &delivery)
+		}
+	}
+}
+
+func (wd *WebhookDispatcher) prune(ctx context.Context) {
+	pruned, err := wd.webhooksRepo.PruneDeliveries(ctx, time.Now().Add(-wd.retention))
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("error pruning webhook deliveries")
+		return
+	}
+	if pruned > 0 {
+		log.Ctx(ctx).Info().Msgf("pruned %v webhook deliveries older than %v", pruned, wd.retention)
+	}
+}
+
+func (wd *WebhookDispatcher) dispatch(ctx context.Context, delivery *models.WebhookDelivery) {
+	err := wd.send(ctx, delivery)
+	if err == nil {
+		if err := wd.webhooksRepo.MarkDelivered(ctx, delivery.DeliveryID, delivery.NextAttemptAt); err != nil {
+			logMarkError(ctx, err, delivery, "delivered")
+		}
+		return
+	}
+	attempts := delivery.Attempts + 1
+	var nextAttemptAt *time.Time
+	if attempts < webhookMaxAttempts {
+		backoff := webhookBaseBackoff << (attempts - 1)
+		if backoff > webhookMaxBackoff {
+			backoff = webhookMaxBackoff
+		}
+		next := time.Now().Add(backoff)
+		nextAttemptAt = &next
+		log.Ctx(ctx).Warn().Err(err).Msgf("webhook delivery %v failed (attempt %v), retrying at %v", delivery.DeliveryID, attempts, next)
+	} else {
+		log.Ctx(ctx).Error().Err(err).Msgf("webhook delivery %v failed after %v attempts, moving to dead letter", delivery.DeliveryID, attempts)
+	}
+	if err := wd.webhooksRepo.MarkFailed(ctx, delivery.DeliveryID, delivery.NextAttemptAt, err.Error(), nextAttemptAt); err != nil {
+		logMarkError(ctx, err, delivery, "failed")
+	}
+}
+
+// logMarkError only warns when the lease ran out mid-delivery: the dispatcher
+// that claimed the delivery next owns its outcome.
+func logMarkError(ctx context.Context, err error, delivery *models.WebhookDelivery, outcome string) {
+	if errors.Is(err, repositories.ErrConflict) {
+		log.Ctx(ctx).Warn().Err(err).Msgf("webhook delivery %v was %v after its lease ran out; leaving it to the current holder", delivery.DeliveryID, outcome)
+		return
+	}
+	log.Ctx(ctx).Error().Err(err).Msgf("error marking webhook delivery %v as %v", delivery.DeliveryID, outcome)
+}
+
+func (wd *WebhookDispatcher) send(ctx context.Context, delivery *models.WebhookDelivery) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("X-QME-Event", delivery.EventType)
+	req.Header.Add("X-QME-Delivery", strconv.FormatInt(delivery.DeliveryID, 10))
+	req.Header.Add("X-QME-Timestamp", timestamp)
+	req.Header.Add("X-QME-Signature", SignWebhookPayload(delivery.Secret, timestamp, delivery.Payload))
+	resp, err := wd.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook endpoint responded with status %v", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package utilities
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWebhookClientRefusesPrivateTargets(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("the webhook client reached a loopback address")
+	}))
+	defer server.Close()
+
+	_, err := newWebhookHTTPClient().Post(server.URL, "application/json", nil)
+	if !errors.Is(err, ErrWebhookTargetNotAllowed) {
+		t.Fatalf("expected the dial to be refused, got %v", err)
+	}
+	if err := ValidateWebhookURL("https://erp.example.com/hooks"); err != nil {
+		t.Fatalf("expected a public https URL to be accepted, got %v", err)
+	}
+}